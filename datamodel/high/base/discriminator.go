@@ -22,6 +22,7 @@ import (
 type Discriminator struct {
 	PropertyName string                          `json:"propertyName,omitempty" yaml:"propertyName,omitempty"`
 	Mapping      *orderedmap.Map[string, string] `json:"mapping,omitempty" yaml:"mapping,omitempty"`
+	Extensions   *orderedmap.Map[string, *yaml.Node]
 	low          *low.Discriminator
 }
 
@@ -35,9 +36,26 @@ func NewDiscriminator(disc *low.Discriminator) *Discriminator {
 		mapping.Set(pair.Key().Value, pair.Value().Value)
 	}
 	d.Mapping = mapping
+	d.Extensions = low2.ExtractExtensions(disc.Extensions)
 	return d
 }
 
+// MappingKeysInOrder returns the keys of the mapping, in the order the author declared them in the document. When
+// there is no low-level model (the discriminator was created by hand), the order of Mapping is used.
+func (d *Discriminator) MappingKeysInOrder() []string {
+	var keys []string
+	if d.low != nil && d.low.Mapping.Value != nil {
+		for pair := orderedmap.First(d.low.Mapping.Value); pair != nil; pair = pair.Next() {
+			keys = append(keys, pair.Key().Value)
+		}
+		return keys
+	}
+	for pair := orderedmap.First(d.Mapping); pair != nil; pair = pair.Next() {
+		keys = append(keys, pair.Key())
+	}
+	return keys
+}
+
 // GoLow returns the low-level Discriminator used to build the high-level one.
 func (d *Discriminator) GoLow() *low.Discriminator {
 	return d.low
@@ -39,6 +39,32 @@ mapping:
 	assert.Equal(t, strings.TrimSpace(string(rendered)), yml)
 }
 
+func TestDiscriminator_MappingKeysInOrder(t *testing.T) {
+	var cNode yaml.Node
+
+	yml := `propertyName: pet
+mapping:
+    zebra: '#/components/schemas/Zebra'
+    aardvark: '#/components/schemas/Aardvark'
+    mongoose: '#/components/schemas/Mongoose'`
+
+	_ = yaml.Unmarshal([]byte(yml), &cNode)
+
+	var lowDiscriminator lowbase.Discriminator
+	_ = lowmodel.BuildModel(cNode.Content[0], &lowDiscriminator)
+	highDiscriminator := NewDiscriminator(&lowDiscriminator)
+
+	assert.Equal(t, []string{"zebra", "aardvark", "mongoose"}, highDiscriminator.MappingKeysInOrder())
+
+	// mapping entries are rendered in the same order.
+	rendered, _ := highDiscriminator.Render()
+	assert.Equal(t, yml, strings.TrimSpace(string(rendered)))
+
+	// a discriminator made by hand uses the order of the mapping.
+	highDiscriminator = &Discriminator{Mapping: highDiscriminator.Mapping}
+	assert.Equal(t, []string{"zebra", "aardvark", "mongoose"}, highDiscriminator.MappingKeysInOrder())
+}
+
 func ExampleNewDiscriminator() {
 	// create a yaml representation of a discriminator (can be JSON, doesn't matter)
 	yml := `propertyName: coffee
@@ -60,3 +86,38 @@ mapping:
 	fmt.Print(highDiscriminator.Mapping.GetOrZero("coffee"))
 	// Output: in the morning
 }
+
+func TestNewDiscriminator_Extensions(t *testing.T) {
+	yml := `propertyName: petType
+x-case-insensitive: true
+x-router: pets
+mapping:
+    cat: '#/components/schemas/Cat'`
+
+	var node yaml.Node
+	_ = yaml.Unmarshal([]byte(yml), &node)
+
+	var lowDiscriminator lowbase.Discriminator
+	_ = lowmodel.BuildModel(node.Content[0], &lowDiscriminator)
+	_ = lowDiscriminator.Build(node.Content[0], nil)
+
+	highDiscriminator := NewDiscriminator(&lowDiscriminator)
+	assert.Equal(t, 2, highDiscriminator.Extensions.Len())
+
+	var caseInsensitive bool
+	_ = highDiscriminator.Extensions.GetOrZero("x-case-insensitive").Decode(&caseInsensitive)
+	assert.True(t, caseInsensitive)
+	assert.Equal(t, "pets", highDiscriminator.Extensions.GetOrZero("x-router").Value)
+	assert.Same(t, &lowDiscriminator, highDiscriminator.GoLow())
+
+	rendered, _ := highDiscriminator.Render()
+	assert.Equal(t, yml, strings.TrimSpace(string(rendered)))
+
+	// extensions built as part of a schema survive too.
+	sch := getHighSchema(t, `oneOf:
+  - type: string
+discriminator:
+  propertyName: petType
+  x-case-insensitive: true`)
+	assert.Equal(t, "true", sch.Discriminator.Extensions.GetOrZero("x-case-insensitive").Value)
+}
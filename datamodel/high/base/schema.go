@@ -4,10 +4,21 @@
 package base
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
 	"github.com/pb33f/libopenapi/datamodel/high"
 	lowmodel "github.com/pb33f/libopenapi/datamodel/low"
 	"github.com/pb33f/libopenapi/datamodel/low/base"
+	"github.com/pb33f/libopenapi/index"
+	"github.com/pb33f/libopenapi/json"
 	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/libopenapi/utils"
+	"golang.org/x/exp/slices"
 	"gopkg.in/yaml.v3"
 )
 
@@ -58,6 +69,7 @@ type Schema struct {
 	Else              *SchemaProxy                          `json:"else,omitempty" yaml:"else,omitempty"`
 	Then              *SchemaProxy                          `json:"then,omitempty" yaml:"then,omitempty"`
 	DependentSchemas  *orderedmap.Map[string, *SchemaProxy] `json:"dependentSchemas,omitempty" yaml:"dependentSchemas,omitempty"`
+	DependentRequired *orderedmap.Map[string, []string]     `json:"dependentRequired,omitempty" yaml:"dependentRequired,omitempty"`
 	PatternProperties *orderedmap.Map[string, *SchemaProxy] `json:"patternProperties,omitempty" yaml:"patternProperties,omitempty"`
 	PropertyNames     *SchemaProxy                          `json:"propertyNames,omitempty" yaml:"propertyNames,omitempty"`
 	UnevaluatedItems  *SchemaProxy                          `json:"unevaluatedItems,omitempty" yaml:"unevaluatedItems,omitempty"`
@@ -106,23 +118,86 @@ type Schema struct {
 
 	// Parent Proxy refers back to the low level SchemaProxy that is proxying this schema.
 	ParentProxy *SchemaProxy `json:"-" yaml:"-"`
+
+	warnings []SchemaWarning
+	opts     *SchemaBuildOptions
 }
 
-// NewSchema will create a new high-level schema from a low-level one.
+// SchemaWarning represents a non-fatal problem found when building a Schema. Things like unknown keywords or
+// malformed values are not errors, but they are lossy, so they are recorded as warnings instead.
+//
+// Path is a JSON Pointer relative to the schema the warning was found in. Line and Column are the location of the
+// problem in the source document (if known).
+type SchemaWarning struct {
+	Path    string
+	Message string
+	Line    int
+	Column  int
+}
+
+// NewSchema will create a new high-level schema from a low-level one. Compositions (allOf, anyOf, oneOf and
+// prefixItems) are built concurrently, unless the schema is small (see DefaultSyncBuildThreshold), then starting
+// goroutines costs more than the work, so everything is built on the calling goroutine.
 func NewSchema(schema *base.Schema) *Schema {
+	return newSchema(context.Background(), schema, nil)
+}
+
+// NewSchemaFromNode will build the low-level and high-level models of a schema directly from a parsed YAML node,
+// useful when the node has already been decoded by another tool, and there is no need to go back through bytes.
+// A document node is unwrapped. References inside the node (like '#/$defs/Address') are resolved against the
+// node itself.
+//
+// The node is not copied, the schema built is backed by it, so it should not be changed afterward.
+func NewSchemaFromNode(node *yaml.Node) (*Schema, error) {
+	if node != nil && node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	node = utils.NodeAlias(node)
+	if node == nil {
+		return nil, fmt.Errorf("cannot build schema: node is empty")
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("cannot build schema: expected a mapping at line %d, column %d", node.Line, node.Column)
+	}
+	idx := index.NewSpecIndexWithConfig(node, index.CreateClosedAPIIndexConfig())
+	var lowSchema base.Schema
+	if err := lowmodel.BuildModel(node, &lowSchema); err != nil {
+		return nil, err
+	}
+	if err := lowSchema.Build(context.Background(), node, idx); err != nil {
+		return nil, err
+	}
+	return NewSchema(&lowSchema), nil
+}
+
+// newSchema builds the schema using the options (which may be nil), any goroutines used are recorded against the
+// metrics in the options. If the context holds a SchemaTracer, the build is traced.
+func newSchema(ctx context.Context, schema *base.Schema, opts *SchemaBuildOptions) *Schema {
+	metrics := opts.buildMetrics()
+	ctx, span := startSchemaSpan(ctx, "schema.build")
+	defer span.End()
 	s := new(Schema)
 	s.low = schema
+	extensions, warnings := schema.ExtensionsWithPrefixes(opts.extensionPrefixes()...)
+	for _, w := range warnings {
+		sw := SchemaWarning{Path: w.Path, Message: w.Message}
+		if w.Node != nil {
+			sw.Line, sw.Column = w.Node.Line, w.Node.Column
+		}
+		s.warnings = append(s.warnings, sw)
+	}
 	s.Title = schema.Title.Value
 	if !schema.SchemaTypeRef.IsEmpty() {
 		s.SchemaTypeRef = schema.SchemaTypeRef.Value
 	}
-	if !schema.MultipleOf.IsEmpty() {
+	// non-finite values (.inf and .nan) are valid YAML, but they break validators, so they are dropped.
+	if !schema.MultipleOf.IsEmpty() && s.isFinite("multipleOf", schema.MultipleOf.Value, schema.MultipleOf.ValueNode) {
 		s.MultipleOf = &schema.MultipleOf.Value
 	}
-	if !schema.Maximum.IsEmpty() {
+	if !schema.Maximum.IsEmpty() && s.isFinite("maximum", schema.Maximum.Value, schema.Maximum.ValueNode) {
 		s.Maximum = &schema.Maximum.Value
 	}
-	if !schema.Minimum.IsEmpty() {
+	if !schema.Minimum.IsEmpty() && s.isFinite("minimum", schema.Minimum.Value, schema.Minimum.ValueNode) {
 		s.Minimum = &schema.Minimum.Value
 	}
 	// if we're dealing with a 3.0 spec using a bool
@@ -132,7 +207,8 @@ func NewSchema(schema *base.Schema) *Schema {
 		}
 	}
 	// if we're dealing with a 3.1 spec using an int
-	if !schema.ExclusiveMaximum.IsEmpty() && schema.ExclusiveMaximum.Value.IsB() {
+	if !schema.ExclusiveMaximum.IsEmpty() && schema.ExclusiveMaximum.Value.IsB() &&
+		s.isFinite(base.ExclusiveMaximumLabel, schema.ExclusiveMaximum.Value.B, schema.ExclusiveMaximum.ValueNode) {
 		s.ExclusiveMaximum = &DynamicValue[bool, float64]{
 			N: 1,
 			B: schema.ExclusiveMaximum.Value.B,
@@ -145,7 +221,8 @@ func NewSchema(schema *base.Schema) *Schema {
 		}
 	}
 	// if we're dealing with a 3.1 spec, using an int
-	if !schema.ExclusiveMinimum.IsEmpty() && schema.ExclusiveMinimum.Value.IsB() {
+	if !schema.ExclusiveMinimum.IsEmpty() && schema.ExclusiveMinimum.Value.IsB() &&
+		s.isFinite(base.ExclusiveMinimumLabel, schema.ExclusiveMinimum.Value.B, schema.ExclusiveMinimum.ValueNode) {
 		s.ExclusiveMinimum = &DynamicValue[bool, float64]{
 			N: 1,
 			B: schema.ExclusiveMinimum.Value.B,
@@ -285,7 +362,7 @@ func NewSchema(schema *base.Schema) *Schema {
 		}
 		s.Examples = examples
 	}
-	s.Extensions = high.ExtractExtensions(schema.Extensions)
+	s.Extensions = high.ExtractExtensions(extensions)
 	if !schema.Discriminator.IsEmpty() {
 		s.Discriminator = NewDiscriminator(schema.Discriminator.Value)
 	}
@@ -301,6 +378,14 @@ func NewSchema(schema *base.Schema) *Schema {
 	}
 	s.Required = req
 
+	if !schema.DependentRequired.IsEmpty() {
+		dependents := orderedmap.New[string, []string]()
+		for pair := orderedmap.First(schema.DependentRequired.Value); pair != nil; pair = pair.Next() {
+			dependents.Set(pair.Key().Value, pair.Value().Value)
+		}
+		s.DependentRequired = dependents
+	}
+
 	if !schema.Anchor.IsEmpty() {
 		s.Anchor = schema.Anchor.Value
 	}
@@ -316,30 +401,56 @@ func NewSchema(schema *base.Schema) *Schema {
 	// any properties each need to be processed in their own thread.
 	// we go as fast as we can.
 	polyCompletedChan := make(chan bool)
-	errChan := make(chan error)
+	// a panic in any of the goroutines is sent back here and raised again on this goroutine, once every goroutine
+	// is done, so it can be recovered by the caller (like NewSchemaAsync) rather than crashing the process.
+	panicChan := make(chan any)
 
 	type buildResult struct {
-		idx int
-		s   *SchemaProxy
+		idx   int
+		s     *SchemaProxy
+		panic any
 	}
 
-	// for every item, build schema async
-	buildSchema := func(sch lowmodel.ValueReference[*base.SchemaProxy], idx int, bChan chan buildResult) {
+	listProxy := func(sch lowmodel.ValueReference[*base.SchemaProxy]) *SchemaProxy {
 		n := &lowmodel.NodeReference[*base.SchemaProxy]{
 			ValueNode: sch.ValueNode,
 			Value:     sch.Value,
 		}
 		n.SetReference(sch.GetReference(), sch.GetReferenceNode())
+		return NewSchemaProxy(n)
+	}
 
-		p := NewSchemaProxy(n)
-
-		bChan <- buildResult{idx: idx, s: p}
+	// for every item, build schema async
+	buildSchema := func(sch lowmodel.ValueReference[*base.SchemaProxy], idx int, bChan chan buildResult) {
+		metrics.enter()
+		defer metrics.leave()
+		r := buildResult{idx: idx}
+		defer func() {
+			r.panic = recover()
+			bChan <- r
+		}()
+		r.s = listProxy(sch)
 	}
 
 	// schema async
-	buildOutSchemas := func(schemas []lowmodel.ValueReference[*base.SchemaProxy], items *[]*SchemaProxy,
-		doneChan chan bool, e chan error,
+	buildOutSchemas := func(label string, schemas []lowmodel.ValueReference[*base.SchemaProxy], items *[]*SchemaProxy,
+		doneChan chan bool, p chan any,
 	) {
+		metrics.enter()
+		defer metrics.leave()
+		var failure any
+		defer func() {
+			if r := recover(); r != nil {
+				failure = r
+			}
+			if failure != nil {
+				p <- failure
+				return
+			}
+			doneChan <- true
+		}()
+		_, span := startSchemaSpan(ctx, "schema.build."+label)
+		defer span.End()
 		bChan := make(chan buildResult)
 		totalSchemas := len(schemas)
 		for i := range schemas {
@@ -350,10 +461,13 @@ func NewSchema(schema *base.Schema) *Schema {
 			select {
 			case r := <-bChan:
 				j++
+				if r.panic != nil {
+					failure = r.panic
+					continue
+				}
 				(*items)[r.idx] = r.s
 			}
 		}
-		doneChan <- true
 	}
 
 	// props async
@@ -376,6 +490,10 @@ func NewSchema(schema *base.Schema) *Schema {
 		}
 	}
 
+	var propSpan SchemaSpan = noopSpan{}
+	if !schema.Properties.IsEmpty() || !schema.DependentSchemas.IsEmpty() || !schema.PatternProperties.IsEmpty() {
+		_, propSpan = startSchemaSpan(ctx, "schema.build.properties")
+	}
 	props := orderedmap.New[string, *SchemaProxy]()
 	for pair := orderedmap.First(schema.Properties.Value); pair != nil; pair = pair.Next() {
 		buildProps(pair.Key(), pair.Value(), props, 0)
@@ -389,6 +507,7 @@ func NewSchema(schema *base.Schema) *Schema {
 	for pair := orderedmap.First(schema.PatternProperties.Value); pair != nil; pair = pair.Next() {
 		buildProps(pair.Key(), pair.Value(), patternProps, 2)
 	}
+	propSpan.End()
 
 	var allOf []*SchemaProxy
 	var oneOf []*SchemaProxy
@@ -397,21 +516,32 @@ func NewSchema(schema *base.Schema) *Schema {
 	var items *DynamicValue[*SchemaProxy, bool]
 	var prefixItems []*SchemaProxy
 
+	// small schemas are built on this goroutine, the goroutines and channels would cost more than the work.
+	async := opts.buildAsync(orderedmap.Len(schema.Properties.Value) + orderedmap.Len(schema.DependentSchemas.Value) +
+		orderedmap.Len(schema.PatternProperties.Value) + len(schema.AllOf.Value) + len(schema.AnyOf.Value) +
+		len(schema.OneOf.Value) + len(schema.PrefixItems.Value))
 	children := 0
-	if !schema.AllOf.IsEmpty() {
+	buildList := func(label string, schemas []lowmodel.ValueReference[*base.SchemaProxy], items *[]*SchemaProxy) {
+		*items = make([]*SchemaProxy, len(schemas))
+		if !async {
+			_, span := startSchemaSpan(ctx, "schema.build."+label)
+			for i := range schemas {
+				(*items)[i] = listProxy(schemas[i])
+			}
+			span.End()
+			return
+		}
 		children++
-		allOf = make([]*SchemaProxy, len(schema.AllOf.Value))
-		go buildOutSchemas(schema.AllOf.Value, &allOf, polyCompletedChan, errChan)
+		go buildOutSchemas(label, schemas, items, polyCompletedChan, panicChan)
+	}
+	if !schema.AllOf.IsEmpty() {
+		buildList("allOf", schema.AllOf.Value, &allOf)
 	}
 	if !schema.AnyOf.IsEmpty() {
-		children++
-		anyOf = make([]*SchemaProxy, len(schema.AnyOf.Value))
-		go buildOutSchemas(schema.AnyOf.Value, &anyOf, polyCompletedChan, errChan)
+		buildList("anyOf", schema.AnyOf.Value, &anyOf)
 	}
 	if !schema.OneOf.IsEmpty() {
-		children++
-		oneOf = make([]*SchemaProxy, len(schema.OneOf.Value))
-		go buildOutSchemas(schema.OneOf.Value, &oneOf, polyCompletedChan, errChan)
+		buildList("oneOf", schema.OneOf.Value, &oneOf)
 	}
 	if !schema.Not.IsEmpty() {
 		not = NewSchemaProxy(&schema.Not)
@@ -431,24 +561,29 @@ func NewSchema(schema *base.Schema) *Schema {
 		}
 	}
 	if !schema.PrefixItems.IsEmpty() {
-		children++
-		prefixItems = make([]*SchemaProxy, len(schema.PrefixItems.Value))
-		go buildOutSchemas(schema.PrefixItems.Value, &prefixItems, polyCompletedChan, errChan)
+		buildList("prefixItems", schema.PrefixItems.Value, &prefixItems)
 	}
 
 	completeChildren := 0
+	var failure any
 	if children > 0 {
 	allDone:
 		for {
 			select {
 			case <-polyCompletedChan:
 				completeChildren++
-				if children == completeChildren {
-					break allDone
-				}
+			case p := <-panicChan:
+				completeChildren++
+				failure = p
+			}
+			if children == completeChildren {
+				break allDone
 			}
 		}
 	}
+	if failure != nil {
+		panic(failure)
+	}
 	s.OneOf = oneOf
 	s.AnyOf = anyOf
 	s.AllOf = allOf
@@ -458,6 +593,390 @@ func NewSchema(schema *base.Schema) *Schema {
 	return s
 }
 
+// Warnings returns any non-fatal problems that were found when the schema was built, an unknown keyword for
+// example. Warnings belong to this schema only, child schemas hold their own.
+func (s *Schema) Warnings() []SchemaWarning {
+	return s.warnings
+}
+
+// isFinite returns true if a numeric constraint is a finite number. If not, a warning is recorded against the keyword.
+func (s *Schema) isFinite(keyword string, value float64, node *yaml.Node) bool {
+	if !math.IsInf(value, 0) && !math.IsNaN(value) {
+		return true
+	}
+	sw := SchemaWarning{
+		Path:    "/" + keyword,
+		Message: fmt.Sprintf("'%s' is not a finite number (%v), the constraint has been dropped", keyword, value),
+	}
+	if node != nil {
+		sw.Line, sw.Column = node.Line, node.Column
+	}
+	s.warnings = append(s.warnings, sw)
+	return false
+}
+
+// HasFiniteBounds returns true if every numeric constraint of the schema (multipleOf, maximum, minimum,
+// exclusiveMaximum and exclusiveMinimum) is a finite number. Non-finite values are dropped when a schema is built
+// from a document, so this is only false for schemas that have been created or modified by hand.
+func (s *Schema) HasFiniteBounds() bool {
+	for _, f := range []*float64{s.MultipleOf, s.Maximum, s.Minimum} {
+		if f != nil && (math.IsInf(*f, 0) || math.IsNaN(*f)) {
+			return false
+		}
+	}
+	for _, dv := range []*DynamicValue[bool, float64]{s.ExclusiveMaximum, s.ExclusiveMinimum} {
+		if dv != nil && dv.IsB() && (math.IsInf(dv.B, 0) || math.IsNaN(dv.B)) {
+			return false
+		}
+	}
+	return true
+}
+
+// XMLName returns the qualified name that should be used when this schema is serialized as XML, when it's the value
+// of the named property. The name from the 'xml' object (with its prefix) is used if defined, otherwise the property
+// name is used. The second value is true if the schema should be written as an attribute rather than an element.
+func (s *Schema) XMLName(propertyName string) (string, bool) {
+	if s.XML == nil {
+		return propertyName, false
+	}
+	return s.XML.QualifiedName(propertyName), s.XML.Attribute
+}
+
+// IsNullable returns true if the schema accepts null. In 3.0 that is the value of the 'nullable' keyword, in 3.1
+// there is no 'nullable' keyword, so 'null' being one of the types is checked instead. Either is understood,
+// regardless of the version of the document.
+func (s *Schema) IsNullable() bool {
+	if s.Nullable != nil && *s.Nullable {
+		return true
+	}
+	return slices.Contains(s.Type, "null")
+}
+
+// AllowsNull returns true if the schema explicitly accepts null. That is a 'null' type (including an unquoted
+// 'type: null'), a 3.0 'nullable', a null const or enum value, or a oneOf or anyOf member that allows null.
+// A schema with no type at all accepts null along with everything else, but says nothing about null, so false
+// is returned for it.
+func (s *Schema) AllowsNull() bool {
+	return s.allowsNull(make(map[any]bool))
+}
+
+func (s *Schema) allowsNull(seen map[any]bool) bool {
+	if s == nil || seen[schemaKey(s)] {
+		return false
+	}
+	seen[schemaKey(s)] = true
+	if s.IsNullable() || isNullNode(s.Const) {
+		return true
+	}
+	for _, e := range s.Enum {
+		if isNullNode(e) {
+			return true
+		}
+	}
+	for _, sp := range append(slices.Clone(s.OneOf), s.AnyOf...) {
+		if sp != nil && sp.Schema().allowsNull(seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// NullablePaths returns a JSON Pointer to every schema in the tree that allows null (see AllowsNull), in the order
+// they are declared, parents before their children. The root is an empty string. This is where a client generator
+// needs an optional or a pointer.
+//
+// References are followed, a schema that is referenced from more than one place is reported at each of them.
+// A reference back to one of its own ancestors is not followed again, so circular schemas are fine.
+func (s *Schema) NullablePaths() []string {
+	if s == nil {
+		return nil
+	}
+	var paths []string
+	s.nullablePaths("", []any{schemaKey(s)}, &paths)
+	return paths
+}
+
+func (s *Schema) nullablePaths(path string, active []any, paths *[]string) {
+	if s.AllowsNull() {
+		*paths = append(*paths, path)
+	}
+	for _, child := range s.namedChildProxies() {
+		if _, isBool := child.proxy.IsBooleanSchema(); isBool {
+			continue
+		}
+		sch := child.proxy.Schema()
+		if sch == nil || slices.Contains(active, schemaKey(sch)) {
+			continue
+		}
+		sch.nullablePaths(path+child.path, append(active, schemaKey(sch)), paths)
+	}
+}
+
+// isNullNode returns true if the node is a YAML null value.
+func isNullNode(n *yaml.Node) bool {
+	n = utils.NodeAlias(n)
+	return n != nil && n.Kind == yaml.ScalarNode && n.Tag == "!!null"
+}
+
+// IsAnyType returns true if the schema imposes no constraints at all, and accepts any value; effectively '{}'.
+// Annotations (title, description, default, examples, deprecated, readOnly, writeOnly, xml, externalDocs and
+// extensions) do not constrain a value, so they are ignored. A 'nullable' without a type is also ignored.
+// Useful for code generators, that map these schemas to an 'any' (or 'interface{}') type.
+func (s *Schema) IsAnyType() bool {
+	if s == nil {
+		return false
+	}
+	return len(s.Type) == 0 && s.Format == "" && s.Pattern == "" &&
+		len(s.AllOf) == 0 && len(s.OneOf) == 0 && len(s.AnyOf) == 0 && s.Not == nil &&
+		s.If == nil && s.Then == nil && s.Else == nil && s.Discriminator == nil &&
+		orderedmap.Len(s.Properties) == 0 && orderedmap.Len(s.PatternProperties) == 0 &&
+		orderedmap.Len(s.DependentSchemas) == 0 && s.AdditionalProperties == nil && s.PropertyNames == nil &&
+		s.UnevaluatedProperties == nil && len(s.Required) == 0 && s.MaxProperties == nil && s.MinProperties == nil &&
+		s.Items == nil && len(s.PrefixItems) == 0 && s.Contains == nil && s.MinContains == nil &&
+		s.MaxContains == nil && s.UnevaluatedItems == nil && s.MaxItems == nil && s.MinItems == nil &&
+		s.UniqueItems == nil && s.MaxLength == nil && s.MinLength == nil && s.MultipleOf == nil &&
+		s.Maximum == nil && s.Minimum == nil && s.ExclusiveMaximum == nil && s.ExclusiveMinimum == nil &&
+		len(s.Enum) == 0 && s.Const == nil
+}
+
+// IsFreeFormObject returns true if the schema is an object with no declared properties (or pattern properties),
+// where any additional property is allowed; additionalProperties is missing, true, or a schema that accepts anything.
+// Code generators map these to a free-form map, like 'map[string]any'.
+func (s *Schema) IsFreeFormObject() bool {
+	if s == nil || s.JSONType() != "object" {
+		return false
+	}
+	if orderedmap.Len(s.Properties) > 0 || orderedmap.Len(s.PatternProperties) > 0 {
+		return false
+	}
+	ap := s.AdditionalProperties
+	if ap == nil || ap.IsB() {
+		return ap == nil || ap.B
+	}
+	return ap.A == nil || ap.A.Schema().IsAnyType()
+}
+
+// JSONType returns the canonical JSON type of the schema, one of 'object', 'array', 'string', 'number', 'integer',
+// 'boolean' or 'null'. A 'null' in a list of types is ignored when there is another type, so [string, null] is a
+// 'string'. When there is no type, the type is inferred from the keywords used (properties means 'object', items
+// means 'array' etc.), or from the const and enum values. An empty string is returned if the schema allows more than
+// one type, or the type cannot be worked out.
+func (s *Schema) JSONType() string {
+	if s == nil {
+		return ""
+	}
+	types := slices.DeleteFunc(slices.Clone(s.Type), func(t string) bool { return t == "null" })
+	switch {
+	case len(types) == 1:
+		return types[0]
+	case len(types) > 1:
+		return ""
+	case len(s.Type) > 0:
+		return "null"
+	}
+
+	// no type, infer it from the keywords.
+	switch {
+	case orderedmap.Len(s.Properties) > 0 || orderedmap.Len(s.PatternProperties) > 0 ||
+		s.AdditionalProperties != nil || len(s.Required) > 0 || s.MinProperties != nil || s.MaxProperties != nil:
+		return "object"
+	case s.Items != nil || len(s.PrefixItems) > 0 || s.Contains != nil || s.MinItems != nil || s.MaxItems != nil ||
+		s.UniqueItems != nil:
+		return "array"
+	case s.MinLength != nil || s.MaxLength != nil || s.Pattern != "":
+		return "string"
+	case s.Maximum != nil || s.Minimum != nil || s.MultipleOf != nil || s.ExclusiveMaximum != nil ||
+		s.ExclusiveMinimum != nil:
+		return "number"
+	}
+
+	// or from the values, every value must be the same type.
+	values := s.Enum
+	if s.Const != nil {
+		values = []*yaml.Node{s.Const}
+	}
+	inferred := ""
+	for _, v := range values {
+		t := instanceType(s.nodeValue(v))
+		if inferred != "" && t != inferred {
+			return ""
+		}
+		inferred = t
+	}
+	if inferred == "unknown" {
+		return ""
+	}
+	return inferred
+}
+
+// RawMaximum returns the maximum exactly as it was written in the original document, so '1e3' stays '1e3' and '1.0'
+// stays '1.0'. If there is no low-level model backing this schema, the number is formatted instead. An empty string
+// is returned if there is no maximum.
+func (s *Schema) RawMaximum() string {
+	var n *yaml.Node
+	if s.low != nil {
+		n = s.low.Maximum.ValueNode
+	}
+	return rawNumber(n, s.Maximum)
+}
+
+// RawMinimum returns the minimum exactly as it was written in the original document, in the same way as RawMaximum.
+func (s *Schema) RawMinimum() string {
+	var n *yaml.Node
+	if s.low != nil {
+		n = s.low.Minimum.ValueNode
+	}
+	return rawNumber(n, s.Minimum)
+}
+
+// rawNumber returns the original text of a number node, if it still holds the value, otherwise the value is
+// formatted.
+func rawNumber(n *yaml.Node, value *float64) string {
+	if value == nil {
+		return ""
+	}
+	if n != nil && utils.ParseNodeFloat(n) == *value {
+		return n.Value
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
+
+// AdditionalPropertiesSchema will build and return the schema of additionalProperties, the value schema of a map.
+// Returns nil if there is no additionalProperties, or if it's a boolean (true or false) rather than a schema.
+func (s *Schema) AdditionalPropertiesSchema() *Schema {
+	if s.AdditionalProperties == nil || !s.AdditionalProperties.IsA() || s.AdditionalProperties.A == nil {
+		return nil
+	}
+	return s.AdditionalProperties.A.Schema()
+}
+
+// Property will build and return the schema of a single named property, the schemas of the other properties are
+// not built. Property proxies are cheap, it's building their schemas that costs, so on a large object where only
+// a few properties are needed, this is much faster than building the whole tree. Subsequent calls return the
+// schema already built. Returns false if there is no property with that name, or its schema cannot be built.
+func (s *Schema) Property(name string) (*Schema, bool) {
+	if s == nil {
+		return nil, false
+	}
+	sp := s.Properties.GetOrZero(name)
+	if sp == nil {
+		return nil, false
+	}
+	sch, err := sp.BuildSchema()
+	if err != nil || sch == nil {
+		return nil, false
+	}
+	return sch, true
+}
+
+// PropertyKeyNode will return the *yaml.Node for the key of a named property, as it was found in the original
+// document. Useful when mapping a property back to its exact position in the source (for renames etc.).
+// Returns nil if the property does not exist, or if there is no low-level model backing this schema.
+func (s *Schema) PropertyKeyNode(name string) *yaml.Node {
+	if s.low == nil {
+		return nil
+	}
+	for pair := orderedmap.First(s.low.Properties.Value); pair != nil; pair = pair.Next() {
+		if pair.Key().Value == name {
+			return pair.Key().KeyNode
+		}
+	}
+	return nil
+}
+
+// EnumNodes will return the original *yaml.Node of each enum value, as it was found in the document, in the same
+// order as Enum. The nodes carry the original style, comments and position of each value. If there is no low-level
+// model backing this schema, the Enum values are returned.
+func (s *Schema) EnumNodes() []*yaml.Node {
+	if s.low == nil {
+		return s.Enum
+	}
+	var enumNodes []*yaml.Node
+	for i := range s.low.Enum.Value {
+		enumNodes = append(enumNodes, s.low.Enum.Value[i].ValueNode)
+	}
+	return enumNodes
+}
+
+// ExtensionKeys will return the keys of every extension, in the order they appear in the original document. If
+// there is no low-level model backing this schema, the keys of Extensions are returned in the order they were set.
+func (s *Schema) ExtensionKeys() []string {
+	var keys []string
+	if s.low != nil && s.low.Extensions != nil {
+		for pair := orderedmap.First(s.low.Extensions); pair != nil; pair = pair.Next() {
+			keys = append(keys, pair.Key().Value)
+		}
+		return keys
+	}
+	for pair := orderedmap.First(s.Extensions); pair != nil; pair = pair.Next() {
+		keys = append(keys, pair.Key())
+	}
+	return keys
+}
+
+// GoTypeOverride will return the Go type a code generator should use for this schema, in place of the one it would
+// generate, read from the 'x-go-type' extension. The import path comes from 'x-go-type-import', which can be a
+// plain string, or a mapping with a 'path' key (as used by oapi-codegen). ok is false if 'x-go-type' is not set.
+func (s *Schema) GoTypeOverride() (typeName string, importPath string, ok bool) {
+	if s.Extensions == nil {
+		return "", "", false
+	}
+	typeNode := utils.NodeAlias(s.Extensions.GetOrZero("x-go-type"))
+	if typeNode == nil || typeNode.Kind != yaml.ScalarNode || typeNode.Value == "" {
+		return "", "", false
+	}
+	if importNode := utils.NodeAlias(s.Extensions.GetOrZero("x-go-type-import")); importNode != nil {
+		switch importNode.Kind {
+		case yaml.ScalarNode:
+			importPath = importNode.Value
+		case yaml.MappingNode:
+			if _, pathNode := utils.FindKeyNodeTop("path", importNode.Content); pathNode != nil {
+				importPath = pathNode.Value
+			}
+		}
+	}
+	return typeNode.Value, importPath, true
+}
+
+// DecimalPrecision will return the precision (total number of digits) and scale (digits after the decimal point)
+// of a decimal number, read from the 'x-precision' and 'x-scale' extensions. If there is no 'x-scale', a
+// fractional multipleOf is used as a hint, a multipleOf of 0.01 is a scale of 2. A value that is not known is
+// returned as zero, ok is false if neither the precision nor the scale are known.
+func (s *Schema) DecimalPrecision() (precision, scale int, ok bool) {
+	extInt := func(key string) (int, bool) {
+		if s.Extensions == nil {
+			return 0, false
+		}
+		n := utils.NodeAlias(s.Extensions.GetOrZero(key))
+		if n == nil || n.Kind != yaml.ScalarNode {
+			return 0, false
+		}
+		v, err := strconv.Atoi(n.Value)
+		if err != nil || v < 0 {
+			return 0, false
+		}
+		return v, true
+	}
+	precision, hasPrecision := extInt("x-precision")
+	scale, hasScale := extInt("x-scale")
+	if !hasScale && s.MultipleOf != nil && *s.MultipleOf > 0 && !math.IsInf(*s.MultipleOf, 0) {
+		if _, fraction, found := strings.Cut(strconv.FormatFloat(*s.MultipleOf, 'f', -1, 64), "."); found {
+			scale, hasScale = len(fraction), true
+		}
+	}
+	return precision, scale, hasPrecision || hasScale
+}
+
+// SourcePath will return the path (or URL) of the document the schema was built from. In a specification that is
+// spread across files, this is the file holding the schema, so references in it can be resolved relative to it,
+// and errors can name the right file. Empty for schemas built in memory, or by hand.
+func (s *Schema) SourcePath() string {
+	if s.low == nil {
+		return ""
+	}
+	return s.low.SourcePath()
+}
+
 // GoLow will return the low-level instance of Schema that was used to create the high level one.
 func (s *Schema) GoLow() *base.Schema {
 	return s.low
@@ -473,6 +992,47 @@ func (s *Schema) Render() ([]byte, error) {
 	return yaml.Marshal(s)
 }
 
+// RenderJSON will return a JSON representation of the Schema object as a byte slice. Properties and extensions are
+// rendered in the order they were declared, so the output is stable and can be compared.
+func (s *Schema) RenderJSON(indention string) ([]byte, error) {
+	n, err := s.MarshalYAML()
+	if err != nil {
+		return nil, err
+	}
+	return json.YAMLNodeToJSON(n.(*yaml.Node), indention)
+}
+
+// RenderJSONMinified will return a compact JSON representation of the Schema object, with no whitespace between
+// tokens. Properties and extensions are rendered in the order they were declared, the same as RenderJSON, so the
+// output is stable; useful for embedding schemas in generated code.
+func (s *Schema) RenderJSONMinified() ([]byte, error) {
+	n, err := s.MarshalYAML()
+	if err != nil {
+		return nil, err
+	}
+	return json.YAMLNodeToCompactJSON(n.(*yaml.Node))
+}
+
+// RenderYAMLTo will write a YAML representation of the Schema object to the writer, instead of returning it as a
+// byte slice. The output is identical to Render.
+func (s *Schema) RenderYAMLTo(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	if err := enc.Encode(s); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// RenderJSONTo will write a JSON representation of the Schema object to the writer, indented with two spaces,
+// instead of returning it as a byte slice. The output is identical to RenderJSON("  ").
+func (s *Schema) RenderJSONTo(w io.Writer) error {
+	n, err := s.MarshalYAML()
+	if err != nil {
+		return err
+	}
+	return json.YAMLNodeToJSONWriter(w, n.(*yaml.Node), "  ")
+}
+
 // RenderInline will return a YAML representation of the Schema object as a byte slice.
 // All the $ref values will be inlined, as in resolved in place.
 //
@@ -482,18 +1042,58 @@ func (s *Schema) RenderInline() ([]byte, error) {
 	return yaml.Marshal(d)
 }
 
+// Snapshot returns a deterministic text dump of the schema, for golden-file tests that compare a built schema to
+// a stored copy. The dump is YAML, properties and extensions are in the order they were declared (and nothing is
+// read from a Go map), so building the same document always produces the same snapshot. Comments and the
+// styles used by the document (flow mappings, quoted strings) are dropped, and the dump is indented with two
+// spaces, so reformatting the source does not change the snapshot. References are not followed.
+func (s *Schema) Snapshot() string {
+	n, err := s.MarshalYAML()
+	if err != nil {
+		return "error: " + err.Error() + "\n"
+	}
+	var b strings.Builder
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err = enc.Encode(snapshotNode(n.(*yaml.Node))); err == nil {
+		err = enc.Close()
+	}
+	if err != nil {
+		return "error: " + err.Error() + "\n"
+	}
+	return b.String()
+}
+
+// snapshotNode returns a copy of a node tree with every comment and style removed. The rendered tree shares nodes
+// with the document, so they are never changed in place.
+func snapshotNode(n *yaml.Node) *yaml.Node {
+	if n == nil {
+		return nil
+	}
+	// the tag is kept, so the encoder quotes strings like '123' that would otherwise change type.
+	c := *n
+	c.Style = 0
+	c.HeadComment, c.LineComment, c.FootComment = "", "", ""
+	if len(n.Content) > 0 {
+		c.Content = make([]*yaml.Node, len(n.Content))
+		for i := range n.Content {
+			c.Content[i] = snapshotNode(n.Content[i])
+		}
+	}
+	return &c
+}
+
 // MarshalYAML will create a ready to render YAML representation of the ExternalDoc object.
 func (s *Schema) MarshalYAML() (interface{}, error) {
 	nb := high.NewNodeBuilder(s, s.low)
 
-	// determine index version
-	idx := s.GoLow().Index
-	if idx != nil {
-		if idx.GetConfig().SpecInfo != nil {
-			nb.Version = idx.GetConfig().SpecInfo.VersionNumeric
+	// determine index version, schemas created by hand have no low-level model.
+	if s.low != nil && s.low.Index != nil {
+		if s.low.Index.GetConfig().SpecInfo != nil {
+			nb.Version = s.low.Index.GetConfig().SpecInfo.VersionNumeric
 		}
 	}
-	return nb.Render(), nil
+	return keepStyle(schemaSourceNode(s), nb.Render()), nil
 }
 
 func (s *Schema) MarshalYAMLInline() (interface{}, error) {
@@ -506,5 +1106,64 @@ func (s *Schema) MarshalYAMLInline() (interface{}, error) {
 			nb.Version = idx.GetConfig().SpecInfo.VersionNumeric
 		}
 	}
-	return nb.Render(), nil
+	return keepStyle(schemaSourceNode(s), nb.Render()), nil
+}
+
+// SourceStyle returns the YAML style of the node the schema was read from, for example yaml.FlowStyle for a schema
+// written as '{type: object}'. Schemas that were not read from a document (built by hand) return 0, the default
+// block style.
+//
+// Rendering keeps the flow style of a YAML schema, and of the values of its keywords (like 'required: [id, name]'),
+// so a round trip does not change how the schema was written. Schemas read from JSON are flow style too, they
+// render as block style YAML, the same as always.
+func (s *Schema) SourceStyle() yaml.Style {
+	if n := schemaSourceNode(s); n != nil {
+		return n.Style
+	}
+	return 0
+}
+
+// isJSONNode returns true if a mapping node was read from JSON, where every key is double-quoted. YAML flow
+// mappings (like '{type: object}') use plain keys.
+func isJSONNode(n *yaml.Node) bool {
+	if n.Kind != yaml.MappingNode || n.Style&yaml.FlowStyle == 0 || len(n.Content) == 0 {
+		return false
+	}
+	for i := 0; i < len(n.Content); i += 2 {
+		if n.Content[i].Style&yaml.DoubleQuotedStyle == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// schemaSourceNode returns the node the schema was read from, or nil if it was built by hand.
+func schemaSourceNode(s *Schema) *yaml.Node {
+	if s != nil && s.low != nil && s.low.ParentProxy != nil {
+		return utils.NodeAlias(s.low.ParentProxy.GetValueNode())
+	}
+	return nil
+}
+
+// keepStyle copies the flow style of the source node to the rendered node, and of each keyword value that is also a
+// mapping or sequence in both. Nested schemas are rendered by their own proxy, which keeps their style. Sources read
+// from JSON are left alone.
+func keepStyle(source, rendered *yaml.Node) *yaml.Node {
+	if source == nil || rendered == nil || source.Kind != rendered.Kind || isJSONNode(source) {
+		return rendered
+	}
+	rendered.Style |= source.Style & yaml.FlowStyle
+	if rendered.Kind != yaml.MappingNode {
+		return rendered
+	}
+	for i := 0; i+1 < len(rendered.Content); i += 2 {
+		value := rendered.Content[i+1]
+		if value.Kind != yaml.MappingNode && value.Kind != yaml.SequenceNode {
+			continue
+		}
+		if src := utils.NodeAlias(mappingValue(source, rendered.Content[i].Value)); src != nil && src.Kind == value.Kind {
+			value.Style |= src.Style & yaml.FlowStyle
+		}
+	}
+	return rendered
 }
@@ -0,0 +1,47 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import "fmt"
+
+// BuildAll will build every schema in the tree below this one, right away, rather than when each is first used.
+// Properties, items, compositions, additionalProperties and every other child are built, as are the children of those
+// children, following references. Each schema is only built once, so circular references are fine. Once done,
+// walking the tree does no more building. '$defs' are not part of the model, definitions are built when something
+// references them.
+//
+// Useful for tools that are going to visit the whole tree anyway. The first error is returned, along with the path
+// to the schema that failed, the rest of the tree is still built.
+func (s *Schema) BuildAll() error {
+	if s == nil {
+		return nil
+	}
+	var first error
+	seen := map[any]bool{schemaKey(s): true}
+	var build func(sch *Schema, path string)
+	build = func(sch *Schema, path string) {
+		for _, child := range sch.namedChildProxies() {
+			if _, ok := child.proxy.IsBooleanSchema(); ok {
+				continue
+			}
+			built, err := child.proxy.BuildSchema()
+			if built == nil {
+				if err == nil {
+					err = fmt.Errorf("schema is empty")
+				}
+				if first == nil {
+					first = fmt.Errorf("schema at '%s' cannot be built: %w", path+child.path, err)
+				}
+				continue
+			}
+			if seen[schemaKey(built)] {
+				continue
+			}
+			seen[schemaKey(built)] = true
+			build(built, path+child.path)
+		}
+	}
+	build(s, "")
+	return first
+}
@@ -0,0 +1,73 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema_BuildAll(t *testing.T) {
+	yml := `components:
+  schemas:
+    Order:
+      type: object
+      properties:
+        items:
+          type: array
+          items:
+            $ref: '#/components/schemas/Item'
+        customer:
+          allOf:
+            - $ref: '#/components/schemas/Customer'
+            - type: object
+              additionalProperties:
+                type: string
+        notes: true
+    Item:
+      type: object
+      properties:
+        sku:
+          type: string
+        order:
+          $ref: '#/components/schemas/Order'
+    Customer:
+      oneOf:
+        - type: string
+        - type: object
+          properties:
+            name:
+              type: string`
+
+	sch := buildComponentSchema(t, yml, "Order")
+	_, built := sch.Properties.GetOrZero("items").Resolved()
+	assert.False(t, built)
+
+	assert.NoError(t, sch.BuildAll())
+
+	// every proxy in the tree has been built, walking it builds nothing more.
+	seen := make(map[any]bool)
+	var check func(s *Schema)
+	count := 0
+	check = func(s *Schema) {
+		if seen[schemaKey(s)] {
+			return
+		}
+		seen[schemaKey(s)] = true
+		for _, child := range s.namedChildProxies() {
+			if _, ok := child.proxy.IsBooleanSchema(); ok {
+				continue
+			}
+			resolved, ok := child.proxy.Resolved()
+			assert.True(t, ok, child.path)
+			count++
+			check(resolved)
+		}
+	}
+	check(sch)
+	assert.Equal(t, 11, count)
+
+	assert.NoError(t, (*Schema)(nil).BuildAll())
+}
@@ -0,0 +1,85 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/pb33f/libopenapi/datamodel/low/base"
+	"golang.org/x/exp/slices"
+)
+
+// componentSchemaPrefix is the reference prefix used to link component schemas built by NewSchemas.
+const componentSchemaPrefix = "#/components/schemas/"
+
+// NewSchemas will build a high-level Schema for every low-level schema in the map (usually the components/schemas
+// of a document), concurrently, with no more builds at a time than there are CPUs. The returned map uses the same
+// keys.
+//
+// Once built, references between the schemas are resolved to the schemas just built; when a child schema
+// of one is a '#/components/schemas/<name>' reference to another schema in the map, building that child returns
+// the already built schema, rather than building it again.
+//
+// Any nil low-level schemas are skipped, and reported in the returned error.
+func NewSchemas(schemas map[string]*base.Schema) (map[string]*Schema, error) {
+	opts := &SchemaBuildOptions{components: make(map[string]*Schema, len(schemas))}
+
+	type job struct {
+		name   string
+		schema *base.Schema
+	}
+	jobs := make(chan job)
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+
+	// a fixed pool of workers, one per CPU, pull schemas to build.
+	for w := 0; w < runtime.GOMAXPROCS(0); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				sch := NewSchemaWithOptions(j.schema, opts)
+				lock.Lock()
+				opts.components[j.name] = sch
+				lock.Unlock()
+			}
+		}()
+	}
+
+	// names are sorted, so any errors are reported in a stable order.
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var errs []error
+	for _, name := range names {
+		lowSchema := schemas[name]
+		if lowSchema == nil {
+			errs = append(errs, fmt.Errorf("schema '%s' cannot be built: low-level schema is nil", name))
+			continue
+		}
+		jobs <- job{name: name, schema: lowSchema}
+	}
+	close(jobs)
+	wg.Wait()
+	return opts.components, errors.Join(errs...)
+}
+
+// component returns the schema built by NewSchemas that the proxy references, or nil if there isn't one.
+func (o *SchemaBuildOptions) component(sp *SchemaProxy) *Schema {
+	if o == nil || o.components == nil || !sp.IsReference() {
+		return nil
+	}
+	ref := sp.GetReference()
+	if !strings.HasPrefix(ref, componentSchemaPrefix) {
+		return nil
+	}
+	return o.components[ref[len(componentSchemaPrefix):]]
+}
@@ -0,0 +1,125 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pb33f/libopenapi/datamodel/low"
+	lowbase "github.com/pb33f/libopenapi/datamodel/low/base"
+	"github.com/pb33f/libopenapi/index"
+	"github.com/pb33f/libopenapi/utils"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+// buildLowComponents builds the low-level schemas of every component schema in the document.
+func buildLowComponents(tb testing.TB, yml string) map[string]*lowbase.Schema {
+	var node yaml.Node
+	_ = yaml.Unmarshal([]byte(yml), &node)
+	idx := index.NewSpecIndexWithConfig(&node, index.CreateOpenAPIIndexConfig())
+
+	_, components := utils.FindKeyNodeTop("components", node.Content[0].Content)
+	_, schemas := utils.FindKeyNodeTop("schemas", components.Content)
+
+	lowSchemas := make(map[string]*lowbase.Schema)
+	for i := 0; i < len(schemas.Content); i += 2 {
+		var ls lowbase.Schema
+		_ = low.BuildModel(schemas.Content[i+1], &ls)
+		if err := ls.Build(context.Background(), schemas.Content[i+1], idx); err != nil {
+			tb.Fatal(err)
+		}
+		lowSchemas[schemas.Content[i].Value] = &ls
+	}
+	return lowSchemas
+}
+
+// generateComponents creates a document with n component schemas, each referencing the next.
+func generateComponents(n int) string {
+	var b strings.Builder
+	b.WriteString("components:\n  schemas:\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, `    Schema%d:
+      type: object
+      description: schema number %d
+      required: [id]
+      properties:
+        id:
+          type: string
+          format: uuid
+        count:
+          type: integer
+          minimum: 0
+        next:
+          $ref: '#/components/schemas/Schema%d'
+`, i, i, (i+1)%n)
+	}
+	return b.String()
+}
+
+func TestNewSchemas(t *testing.T) {
+	yml := `components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        owner:
+          $ref: '#/components/schemas/Owner'
+    Owner:
+      type: object
+      properties:
+        name:
+          type: string`
+
+	lowSchemas := buildLowComponents(t, yml)
+	lowSchemas["Broken"] = nil
+
+	schemas, err := NewSchemas(lowSchemas)
+	assert.Error(t, err)
+	assert.Equal(t, "schema 'Broken' cannot be built: low-level schema is nil", err.Error())
+	assert.Len(t, schemas, 2)
+
+	// the reference resolves to the schema already built.
+	owner := schemas["Pet"].Properties.GetOrZero("owner").Schema()
+	assert.Same(t, schemas["Owner"], owner)
+	assert.Equal(t, []string{"string"}, owner.Properties.GetOrZero("name").Schema().Type)
+
+	// references still render as references.
+	rend, _ := schemas["Pet"].Render()
+	assert.Contains(t, string(rend), "$ref: '#/components/schemas/Owner'")
+}
+
+func TestNewSchemas_Many(t *testing.T) {
+	schemas, err := NewSchemas(buildLowComponents(t, generateComponents(50)))
+	assert.NoError(t, err)
+	assert.Len(t, schemas, 50)
+	for i := 0; i < 50; i++ {
+		next := schemas[fmt.Sprintf("Schema%d", i)].Properties.GetOrZero("next").Schema()
+		assert.Same(t, schemas[fmt.Sprintf("Schema%d", (i+1)%50)], next)
+	}
+}
+
+func BenchmarkNewSchemas(b *testing.B) {
+	lowSchemas := buildLowComponents(b, generateComponents(500))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		_, _ = NewSchemas(lowSchemas)
+	}
+}
+
+func BenchmarkNewSchemas_Sequential(b *testing.B) {
+	lowSchemas := buildLowComponents(b, generateComponents(500))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		built := make(map[string]*Schema, len(lowSchemas))
+		for name, ls := range lowSchemas {
+			built[name] = NewSchema(ls)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pb33f/libopenapi/json"
+	"github.com/pb33f/libopenapi/utils"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// BundleDialect is the '$schema' of the document created by BundleSchemas.
+const BundleDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// BundleSchemas will return a single JSON Schema document (as JSON) holding every schema in the map under '$defs',
+// keyed by name, in name order. This is the standard way to ship a set of schemas, like the components of an
+// OpenAPI document, as one self-contained JSON Schema.
+//
+// Every reference is rewritten to point into the bundle, so '#/components/schemas/Pet' becomes '#/$defs/Pet'.
+// A reference is matched to a schema in the bundle by the last segment of its JSON Pointer, a reference to a schema
+// that is not in the bundle is returned as an error, rather than left dangling.
+//
+// References must point straight at a named schema, one held by 'schemas', 'definitions' or '$defs', or at the top
+// of a file (like 'pet.yaml#/Pet'). A reference deeper into a schema (like '#/$defs/Pet/properties/name') is an
+// error, as is the same name reached from two places (like 'a.yaml#/Pet' and 'b.yaml#/definitions/Pet'), there is
+// only one schema with that name in the bundle.
+func BundleSchemas(schemas map[string]*Schema) ([]byte, error) {
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	// refs holds the first reference to each name, to catch two places using the same name.
+	refs := make(map[string]string)
+	defs := utils.CreateEmptyMapNode()
+	for _, name := range names {
+		sch := schemas[name]
+		if sch == nil {
+			return nil, fmt.Errorf("cannot bundle schema '%s', it is empty", name)
+		}
+		rendered, err := sch.MarshalYAML()
+		if err != nil {
+			return nil, fmt.Errorf("cannot bundle schema '%s': %w", name, err)
+		}
+		n, err := bundleReferences(rendered.(*yaml.Node), schemas, refs)
+		if err != nil {
+			return nil, fmt.Errorf("cannot bundle schema '%s': %w", name, err)
+		}
+		defs.Content = append(defs.Content, utils.CreateStringNode(name), n)
+	}
+
+	doc := utils.CreateEmptyMapNode()
+	doc.Content = append(doc.Content,
+		utils.CreateStringNode("$schema"), utils.CreateStringNode(BundleDialect),
+		utils.CreateStringNode("$defs"), defs)
+	return json.YAMLNodeToJSON(doc, "  ")
+}
+
+// bundleReferences returns a copy of a rendered schema, with every reference to a schema (at any depth) rewritten to
+// '#/$defs/Name'. A '$ref' inside a value, like an example, is not a reference and is kept as it is.
+func bundleReferences(n *yaml.Node, schemas map[string]*Schema, refs map[string]string) (*yaml.Node, error) {
+	return rewriteSchemas(n, func(sch *yaml.Node) (*yaml.Node, bool, error) {
+		ref, isRef := schemaReference(sch)
+		if !isRef {
+			return sch, false, nil
+		}
+		name, source, ok := bundleName(ref)
+		if !ok {
+			return nil, false, fmt.Errorf("reference '%s' does not point at a named schema", ref)
+		}
+		if _, ok = schemas[name]; !ok {
+			return nil, false, fmt.Errorf("reference '%s' is not part of the bundle", ref)
+		}
+		if first, seen := refs[name]; !seen {
+			refs[name] = ref
+		} else if _, firstSource, _ := bundleName(first); firstSource != source {
+			return nil, false, fmt.Errorf("references '%s' and '%s' both bundle as '%s'", first, ref, name)
+		}
+		// anything next to the reference may hold references too, so the copy is rewritten as well.
+		c := *sch
+		c.Content = slices.Clone(sch.Content)
+		for i := 0; i < len(c.Content)-1; i += 2 {
+			if c.Content[i].Value == "$ref" {
+				c.Content[i+1] = utils.CreateStringNode("#/$defs/" + escapePointer(name))
+			}
+		}
+		return &c, false, nil
+	})
+}
+
+// bundleName returns the name a reference points to, the last segment of its JSON Pointer, unescaped, along with
+// the place holding it (the file and the pointer to its parent). The third value is false if the reference does not
+// point straight at a named schema.
+func bundleName(ref string) (string, string, bool) {
+	file, pointer, _ := strings.Cut(ref, "#")
+	i := strings.LastIndex(pointer, "/")
+	if i < 0 {
+		return "", "", false
+	}
+	parent := pointer[:i]
+	switch parent[strings.LastIndex(parent, "/")+1:] {
+	case "", "schemas", "definitions", "$defs":
+	default:
+		return "", "", false
+	}
+	name := strings.ReplaceAll(strings.ReplaceAll(pointer[i+1:], "~1", "/"), "~0", "~")
+	return name, file + "#" + parent, name != ""
+}
@@ -0,0 +1,127 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBundleSchemas(t *testing.T) {
+	doc := `components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        owner:
+          $ref: '#/components/schemas/Person'
+    Person:
+      type: object
+      properties:
+        name:
+          type: string`
+
+	pet := buildComponentSchema(t, doc, "Pet")
+	out, err := BundleSchemas(map[string]*Schema{
+		"Pet":    pet,
+		"Person": buildComponentSchema(t, doc, "Person"),
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$defs": {
+    "Person": {"type": "object", "properties": {"name": {"type": "string"}}},
+    "Pet": {"type": "object", "properties": {"owner": {"$ref": "#/$defs/Person"}}}
+  }
+}`, string(out))
+
+	// the source schema is not changed.
+	rendered, _ := pet.Render()
+	assert.Contains(t, string(rendered), "$ref: '#/components/schemas/Person'")
+
+	_, err = BundleSchemas(map[string]*Schema{"Pet": pet})
+	assert.EqualError(t, err,
+		"cannot bundle schema 'Pet': reference '#/components/schemas/Person' is not part of the bundle")
+
+	_, err = BundleSchemas(map[string]*Schema{"Pet": nil})
+	assert.EqualError(t, err, "cannot bundle schema 'Pet', it is empty")
+}
+
+func TestBundleSchemas_Values(t *testing.T) {
+	// a '$ref' key inside a value is data, it's neither rewritten nor checked.
+	doc := `components:
+  schemas:
+    Pointer:
+      type: object
+      properties:
+        target:
+          $ref: '#/components/schemas/Target'
+      example:
+        $ref: '#/components/schemas/Elsewhere'
+      default:
+        $ref: '#/components/schemas/Elsewhere'
+    Target:
+      type: string`
+
+	out, err := BundleSchemas(map[string]*Schema{
+		"Pointer": buildComponentSchema(t, doc, "Pointer"),
+		"Target":  buildComponentSchema(t, doc, "Target"),
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$defs": {
+    "Pointer": {
+      "type": "object",
+      "properties": {"target": {"$ref": "#/$defs/Target"}},
+      "example": {"$ref": "#/components/schemas/Elsewhere"},
+      "default": {"$ref": "#/components/schemas/Elsewhere"}
+    },
+    "Target": {"type": "string"}
+  }
+}`, string(out))
+}
+
+func TestBundleSchemas_Names(t *testing.T) {
+	// an object with a property for every reference given.
+	refs := func(refs ...string) *Schema {
+		props := orderedmap.New[string, *SchemaProxy]()
+		for i, ref := range refs {
+			props.Set("p"+strconv.Itoa(i), CreateSchemaProxyRef(ref))
+		}
+		return &Schema{Type: []string{"object"}, Properties: props}
+	}
+	pet := &Schema{Type: []string{"object"}}
+
+	// the same schema can be referenced as often as needed, from a file or a container.
+	out, err := BundleSchemas(map[string]*Schema{
+		"Owner": refs("pets.yaml#/Pet", "pets.yaml#/Pet"),
+		"Pet":   pet,
+		"Shop":  refs("#/definitions/Owner", "pets.yaml#/Pet"),
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, string(out), `"$ref": "#/$defs/Pet"`)
+
+	// two places holding a schema of the same name cannot both be bundled.
+	_, err = BundleSchemas(map[string]*Schema{
+		"Owner": refs("a.yaml#/Pet"),
+		"Pet":   pet,
+		"Shop":  refs("b.yaml#/definitions/Pet"),
+	})
+	assert.EqualError(t, err,
+		"cannot bundle schema 'Shop': references 'a.yaml#/Pet' and 'b.yaml#/definitions/Pet' both bundle as 'Pet'")
+
+	// references into a schema are not named schemas, whatever they end with.
+	_, err = BundleSchemas(map[string]*Schema{
+		"Owner": refs("#/components/schemas/Pet/properties/name"),
+		"name":  pet,
+	})
+	assert.EqualError(t, err, "cannot bundle schema 'Owner': reference "+
+		"'#/components/schemas/Pet/properties/name' does not point at a named schema")
+	_, err = BundleSchemas(map[string]*Schema{"Owner": refs("pets.yaml"), "Pet": pet})
+	assert.Error(t, err)
+}
@@ -0,0 +1,64 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+// CircularReferences walks the schema and every schema below it (following references), and returns each reference
+// cycle found. A cycle is the ordered list of references followed to get from a schema back to itself, so for
+// A -> B -> A, walked from A, the cycle is ['#/components/schemas/B', '#/components/schemas/A'].
+//
+// Circular references are perfectly valid, but code generators need to know about them so they can use a pointer
+// (or some other indirection) to break the cycle. Every cycle is reported once, from the first schema in it that
+// the walk reaches. Returns nil when there are no cycles.
+func (s *Schema) CircularReferences() [][]string {
+	if s == nil {
+		return nil
+	}
+	w := &circularWalker{active: make(map[any]int), done: make(map[any]bool)}
+	w.walk(s, "")
+	return w.cycles
+}
+
+// circularWalker is a depth first walk. active holds the position on the stack of every schema being walked, done
+// holds every schema whose children have all been walked.
+type circularWalker struct {
+	stack  []string
+	active map[any]int
+	done   map[any]bool
+	cycles [][]string
+}
+
+func (w *circularWalker) walk(s *Schema, ref string) {
+	key := schemaKey(s)
+	if pos, ok := w.active[key]; ok {
+		var cycle []string
+		for _, r := range append(w.stack[pos+1:], ref) {
+			if r != "" {
+				cycle = append(cycle, r)
+			}
+		}
+		if len(cycle) > 0 {
+			w.cycles = append(w.cycles, cycle)
+		}
+		return
+	}
+	if w.done[key] {
+		return
+	}
+	w.active[key] = len(w.stack)
+	w.stack = append(w.stack, ref)
+	for _, sp := range s.childProxies() {
+		child := sp.Schema()
+		if child == nil {
+			continue
+		}
+		ref := ""
+		if sp.IsReference() {
+			ref = sp.GetReference()
+		}
+		w.walk(child, ref)
+	}
+	w.stack = w.stack[:len(w.stack)-1]
+	delete(w.active, key)
+	w.done[key] = true
+}
@@ -0,0 +1,52 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema_CircularReferences(t *testing.T) {
+	yml := `components:
+  schemas:
+    A:
+      type: object
+      properties:
+        b:
+          $ref: '#/components/schemas/B'
+        name:
+          $ref: '#/components/schemas/Name'
+    B:
+      type: object
+      properties:
+        a:
+          $ref: '#/components/schemas/A'
+        names:
+          type: array
+          items:
+            $ref: '#/components/schemas/Name'
+    Name:
+      type: string
+    Tree:
+      type: object
+      properties:
+        children:
+          type: array
+          items:
+            $ref: '#/components/schemas/Tree'`
+
+	a := buildComponentSchema(t, yml, "A")
+	assert.Equal(t, [][]string{{"#/components/schemas/B", "#/components/schemas/A"}}, a.CircularReferences())
+
+	tree := buildComponentSchema(t, yml, "Tree")
+	assert.Equal(t, [][]string{{"#/components/schemas/Tree"}}, tree.CircularReferences())
+
+	assert.Nil(t, buildComponentSchema(t, yml, "Name").CircularReferences())
+	assert.Nil(t, getHighSchema(t, `type: object
+properties:
+  name:
+    type: string`).CircularReferences())
+}
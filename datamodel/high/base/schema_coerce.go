@@ -0,0 +1,107 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"strconv"
+
+	"github.com/pb33f/libopenapi/orderedmap"
+	"golang.org/x/exp/slices"
+)
+
+// ValidateCoerced will convert strings in the instance into the types the schema declares, and then check the
+// converted instance against the schema, as if Coerce(true) was given to Validate. The converted instance is
+// returned, along with every ValidationError found, so a caller reading a config file gets typed values back.
+//
+// A string is only converted when the schema declares a type, and that type does not include 'string'. Each
+// declared type is tried in order; 'integer' accepts "5", 'number' accepts "5" or "1.5", 'boolean' accepts "true"
+// or "false" and 'null' accepts "null". A string that cannot be converted is left alone, and fails the type check.
+// Properties, additionalProperties (when there are no patternProperties), items, prefixItems and allOf members are
+// followed, so nested values are converted too. The instance given is not modified.
+func (s *Schema) ValidateCoerced(instance any, opts ...ValidateOption) (any, []*ValidationError) {
+	options := new(ValidateOptions)
+	for _, opt := range opts {
+		opt(options)
+	}
+	options.Coerce = false
+	coerced := s.coerce(normalizeInstance(instance))
+	errs, _ := s.validate(coerced, options)
+	return coerced, errs
+}
+
+// coerce returns a copy of a normalized instance, with strings converted into the types declared by the schema.
+func (s *Schema) coerce(instance any) any {
+	if s == nil {
+		return instance
+	}
+	switch value := instance.(type) {
+	case string:
+		instance = coerceString(s.Type, value)
+	case map[string]any:
+		coerced := make(map[string]any, len(value))
+		for k, v := range value {
+			coerced[k] = v
+			if prop := s.Properties.GetOrZero(k); prop != nil {
+				coerced[k] = coerceProxy(prop, v)
+			} else if ap := s.AdditionalProperties; ap != nil && ap.IsA() && orderedmap.Len(s.PatternProperties) == 0 {
+				coerced[k] = coerceProxy(ap.A, v)
+			}
+		}
+		instance = coerced
+	case []any:
+		coerced := make([]any, len(value))
+		for i, v := range value {
+			coerced[i] = v
+			switch {
+			case i < len(s.PrefixItems):
+				coerced[i] = coerceProxy(s.PrefixItems[i], v)
+			case s.Items != nil && s.Items.IsA():
+				coerced[i] = coerceProxy(s.Items.A, v)
+			}
+		}
+		instance = coerced
+	}
+	for _, sp := range s.AllOf {
+		instance = coerceProxy(sp, instance)
+	}
+	return instance
+}
+
+// coerceProxy coerces an instance using the schema of a proxy, boolean schemas have no types to coerce to.
+func coerceProxy(sp *SchemaProxy, instance any) any {
+	if sp == nil {
+		return instance
+	}
+	if _, isBool := sp.IsBooleanSchema(); isBool {
+		return instance
+	}
+	return sp.Schema().coerce(instance)
+}
+
+// coerceString converts a string into the first of the types it can be, or returns it as it is.
+func coerceString(types []string, value string) any {
+	if len(types) == 0 || slices.Contains(types, "string") {
+		return value
+	}
+	for _, t := range types {
+		switch t {
+		case "integer", "number":
+			if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+				return i
+			}
+			if f, err := strconv.ParseFloat(value, 64); err == nil && t == "number" {
+				return f
+			}
+		case "boolean":
+			if value == "true" || value == "false" {
+				return value == "true"
+			}
+		case "null":
+			if value == "null" {
+				return nil
+			}
+		}
+	}
+	return value
+}
@@ -0,0 +1,76 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema_Validate_Coerce(t *testing.T) {
+	sch := getHighSchema(t, `type: integer`)
+
+	assert.Len(t, sch.Validate("5"), 1)
+	assert.Empty(t, sch.Validate("5", Coerce(true)))
+	assert.Len(t, sch.Validate("five", Coerce(true)), 1)
+
+	c, err := sch.Compile(Coerce(true))
+	assert.NoError(t, err)
+	assert.Empty(t, c.Validate("5"))
+}
+
+func TestSchema_ValidateCoerced(t *testing.T) {
+	sch := getHighSchema(t, `type: object
+properties:
+  port:
+    type: integer
+    maximum: 9000
+  debug:
+    type: boolean
+  ratio:
+    type: [number, "null"]
+  name:
+    type: string
+  hosts:
+    type: array
+    items:
+      type: [boolean, integer]
+additionalProperties:
+  type: number
+allOf:
+  - properties:
+      retries:
+        type: integer`)
+
+	config := map[string]any{
+		"port":    "8080",
+		"debug":   "true",
+		"ratio":   "0.5",
+		"name":    "42",
+		"hosts":   []any{"1", "false", "x"},
+		"timeout": "2.5",
+		"retries": "3",
+	}
+	coerced, errs := sch.ValidateCoerced(config)
+	assert.Equal(t, map[string]any{
+		"port":    int64(8080),
+		"debug":   true,
+		"ratio":   0.5,
+		"name":    "42",
+		"hosts":   []any{int64(1), false, "x"},
+		"timeout": 2.5,
+		"retries": int64(3),
+	}, coerced)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "/hosts/2", errs[0].Path)
+
+	// the constraints are checked against the coerced values.
+	_, errs = sch.ValidateCoerced(map[string]any{"port": "9001", "ratio": "null"})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "maximum", errs[0].Keyword)
+
+	// the original is untouched.
+	assert.Equal(t, "8080", config["port"])
+}
@@ -0,0 +1,204 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"github.com/pb33f/libopenapi/orderedmap"
+	"golang.org/x/exp/slices"
+)
+
+// Compatibility is the verdict of CompareSchemas, how a new version of a schema relates to the old one.
+type Compatibility int
+
+const (
+	// Identical means nothing changed at all.
+	Identical Compatibility = iota
+
+	// BackwardCompatible means the schema changed, but everything valid against the old schema is still valid
+	// against the new one (by the rules CompareSchemas understands), and nothing was taken away.
+	BackwardCompatible
+
+	// Breaking means the change can break existing clients.
+	Breaking
+)
+
+// String returns the name of the verdict.
+func (c Compatibility) String() string {
+	switch c {
+	case Identical:
+		return "identical"
+	case BackwardCompatible:
+		return "backward compatible"
+	default:
+		return "breaking"
+	}
+}
+
+// CompareSchemas will compare an old and a new version of a schema, and return a single verdict on the change,
+// useful as a gate in CI. Schemas with no differences at all (see DiffSchemaNodes), including in the schemas they
+// reference, are Identical.
+//
+// A change is Breaking when a property is removed, a property becomes required, a type, enum or const no longer
+// allows a value it did before, a numeric bound, length or size is narrowed, a pattern or format is added or
+// changed, uniqueItems is turned on, nullable is turned off, or additionalProperties becomes stricter. Properties,
+// additionalProperties and items are compared recursively, following references. Anything else (an added optional
+// property, a widened enum, a loosened bound, a changed description) is BackwardCompatible.
+//
+// Compositions (allOf, oneOf, anyOf, not, if/then/else etc.) cannot be compared reliably, if either side uses one
+// and they are not the same, the change is Breaking, to be safe.
+func CompareSchemas(old, new *Schema) Compatibility {
+	if old == nil && new == nil {
+		return Identical
+	}
+	if old == nil || new == nil {
+		return Breaking
+	}
+	if breakingChange(old, new, make(map[[2]any]bool)) {
+		return Breaking
+	}
+	if identical(old, new, true, make(map[[2]any]bool)) {
+		return Identical
+	}
+	return BackwardCompatible
+}
+
+// identical returns true if there are no differences between old and new, including in the schemas they reference.
+// When diff is false, the nodes of the schemas have already been compared as part of their parents, only the
+// references below them are left to check. The seen map guards against circular references.
+func identical(old, new *Schema, diff bool, seen map[[2]any]bool) bool {
+	if old == nil || new == nil {
+		return old == new
+	}
+	pair := [2]any{schemaKey(old), schemaKey(new)}
+	if seen[pair] {
+		return true
+	}
+	seen[pair] = true
+	if diff && len(DiffSchemaNodes(old, new)) > 0 {
+		return false
+	}
+	oldChildren, newChildren := old.namedChildProxies(), new.namedChildProxies()
+	if len(oldChildren) != len(newChildren) {
+		return false
+	}
+	for i := range oldChildren {
+		if oldChildren[i].path != newChildren[i].path {
+			return false
+		}
+		// the nodes of a reference are only the '$ref', the schema it points at has not been compared yet.
+		ref := oldChildren[i].proxy.IsReference() || newChildren[i].proxy.IsReference()
+		if !identical(oldChildren[i].proxy.Schema(), newChildren[i].proxy.Schema(), ref, seen) {
+			return false
+		}
+	}
+	return true
+}
+
+// breakingChange returns true if a change from old to new breaks anything. The seen map guards against circular
+// schemas, a pair already being compared is assumed fine, the rest of the tree decides.
+func breakingChange(old, new *Schema, seen map[[2]any]bool) bool {
+	if old == nil || new == nil {
+		return old != new
+	}
+	pair := [2]any{schemaKey(old), schemaKey(new)}
+	if old == new || seen[pair] {
+		return false
+	}
+	seen[pair] = true
+
+	if old.hasUnknownConstraints() || new.hasUnknownConstraints() {
+		return old.Signature() != new.Signature()
+	}
+
+	// the new schema must allow every type and value the old one did.
+	if len(new.Type) > 0 {
+		if len(old.Type) == 0 {
+			return true
+		}
+		for _, t := range old.Type {
+			if !slices.Contains(new.Type, t) && !(t == "integer" && slices.Contains(new.Type, "number")) {
+				return true
+			}
+		}
+	}
+	if old.Nullable != nil && *old.Nullable && (new.Nullable == nil || !*new.Nullable) {
+		return true
+	}
+	if len(new.Enum) > 0 || new.Const != nil {
+		if !old.valuesSubsetOf(new) {
+			return true
+		}
+	}
+
+	// bounds, lengths and sizes must not be narrowed.
+	if nv, nex, ok := new.upperBound(); ok {
+		ov, oex, ook := old.upperBound()
+		if !ook || ov > nv || (ov == nv && nex && !oex) {
+			return true
+		}
+	}
+	if nv, nex, ok := new.lowerBound(); ok {
+		ov, oex, ook := old.lowerBound()
+		if !ook || ov < nv || (ov == nv && nex && !oex) {
+			return true
+		}
+	}
+	if new.MultipleOf != nil && *new.MultipleOf != 0 {
+		if old.MultipleOf == nil || !isMultipleOf(*old.MultipleOf, *new.MultipleOf) {
+			return true
+		}
+	}
+	if !maxWithin(old.MaxLength, new.MaxLength) || !minWithin(old.MinLength, new.MinLength) ||
+		!maxWithin(old.MaxItems, new.MaxItems) || !minWithin(old.MinItems, new.MinItems) ||
+		!maxWithin(old.MaxProperties, new.MaxProperties) || !minWithin(old.MinProperties, new.MinProperties) {
+		return true
+	}
+	if new.UniqueItems != nil && *new.UniqueItems && (old.UniqueItems == nil || !*old.UniqueItems) {
+		return true
+	}
+	if (new.Pattern != "" && new.Pattern != old.Pattern) || (new.Format != "" && new.Format != old.Format) {
+		return true
+	}
+
+	// properties can be added, but not removed or made required.
+	for _, r := range new.Required {
+		if !slices.Contains(old.Required, r) {
+			return true
+		}
+	}
+	for pair := orderedmap.First(old.Properties); pair != nil; pair = pair.Next() {
+		np := new.Properties.GetOrZero(pair.Key())
+		if np == nil || breakingChange(pair.Value().Schema(), np.Schema(), seen) {
+			return true
+		}
+	}
+	if breakingDynamic(old.AdditionalProperties, new.AdditionalProperties, seen) ||
+		breakingDynamic(old.Items, new.Items, seen) {
+		return true
+	}
+	return false
+}
+
+// breakingDynamic compares additionalProperties or items, which can be a schema or a boolean. Missing is the same
+// as true, anything is allowed.
+func breakingDynamic(old, new *DynamicValue[*SchemaProxy, bool], seen map[[2]any]bool) bool {
+	if new == nil || (new.IsB() && new.B) {
+		return false
+	}
+	if old == nil || (old.IsB() && old.B) {
+		return true
+	}
+	if new.IsB() {
+		// new is false, old must be false too.
+		return old.IsA()
+	}
+	if old.IsB() {
+		// old is false, nothing was allowed, so any new schema is looser.
+		return false
+	}
+	if old.A == nil || new.A == nil {
+		return old.A != new.A
+	}
+	return breakingChange(old.A.Schema(), new.A.Schema(), seen)
+}
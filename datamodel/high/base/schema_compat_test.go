@@ -0,0 +1,109 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareSchemas(t *testing.T) {
+	base := `type: object
+required: [name]
+properties:
+  name:
+    type: string
+    maxLength: 50
+  size:
+    type: string
+    enum: [small, large]
+  count:
+    type: integer
+    minimum: 1
+    maximum: 10`
+
+	tests := []struct {
+		name     string
+		new      string
+		expected Compatibility
+	}{
+		{"same", base, Identical},
+		{"description added", base + "\ndescription: an order", BackwardCompatible},
+		{"optional property added", base + "\n  notes:\n    type: string", BackwardCompatible},
+		{"enum widened", replace(base, "[small, large]", "[small, medium, large]"), BackwardCompatible},
+		{"bound loosened", replace(base, "maximum: 10", "maximum: 20"), BackwardCompatible},
+		{"property removed", replace(base, "  name:\n    type: string\n    maxLength: 50\n", ""), Breaking},
+		{"property required", replace(base, "[name]", "[name, size]"), Breaking},
+		{"enum narrowed", replace(base, "[small, large]", "[small]"), Breaking},
+		{"bound narrowed", replace(base, "maximum: 10", "maximum: 5"), Breaking},
+		{"length narrowed", replace(base, "maxLength: 50", "maxLength: 20"), Breaking},
+		{"type changed", replace(base, "type: integer", "type: string"), Breaking},
+		{"pattern added", replace(base, "maxLength: 50", "maxLength: 50\n    pattern: '^[a-z]+$'"), Breaking},
+	}
+	old := getHighSchema(t, base)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, CompareSchemas(old, getHighSchema(t, tc.new)))
+		})
+	}
+}
+
+func TestCompareSchemas_Nested(t *testing.T) {
+	old := getHighSchema(t, `type: array
+items:
+  type: integer
+  minimum: 0`)
+	assert.Equal(t, BackwardCompatible, CompareSchemas(old, getHighSchema(t, `type: array
+items:
+  type: number`)))
+	assert.Equal(t, Breaking, CompareSchemas(old, getHighSchema(t, `type: array
+items:
+  type: integer
+  minimum: 1`)))
+
+	// compositions are only compatible when they do not change.
+	oneOf := getHighSchema(t, `oneOf: [{type: string}, {type: integer}]`)
+	assert.Equal(t, BackwardCompatible, CompareSchemas(oneOf, getHighSchema(t, `oneOf: [{type: string}, {type: integer}]
+description: either`)))
+	assert.Equal(t, Breaking, CompareSchemas(oneOf, getHighSchema(t, `oneOf: [{type: string}]`)))
+
+	// additionalProperties turned off.
+	assert.Equal(t, Breaking, CompareSchemas(getHighSchema(t, `type: object`), getHighSchema(t, `type: object
+additionalProperties: false`)))
+
+	assert.Equal(t, Identical, CompareSchemas(nil, nil))
+	assert.Equal(t, Breaking, CompareSchemas(old, nil))
+	assert.Equal(t, "backward compatible", BackwardCompatible.String())
+}
+
+func TestCompareSchemas_References(t *testing.T) {
+	spec := `components:
+  schemas:
+    Order:
+      type: object
+      properties:
+        address:
+          $ref: '#/components/schemas/Address'
+    Address:
+      type: object
+      properties:
+        street:
+          type: string`
+	old := buildComponentSchema(t, spec, "Order")
+	assert.Equal(t, Identical, CompareSchemas(old, buildComponentSchema(t, spec, "Order")))
+
+	// only the referenced schema changed.
+	described := replace(spec, "    Address:\n", "    Address:\n      description: where to deliver\n")
+	assert.Equal(t, BackwardCompatible, CompareSchemas(old, buildComponentSchema(t, described, "Order")))
+
+	required := replace(spec, "    Address:\n", "    Address:\n      required: [street]\n")
+	assert.Equal(t, Breaking, CompareSchemas(old, buildComponentSchema(t, required, "Order")))
+}
+
+// replace replaces the first instance of old in s.
+func replace(s, old, new string) string {
+	return strings.Replace(s, old, new, 1)
+}
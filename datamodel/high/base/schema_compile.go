@@ -0,0 +1,125 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/pb33f/libopenapi/orderedmap"
+	"gopkg.in/yaml.v3"
+)
+
+// CompiledValidator checks instances against a schema that has been prepared once by Schema.Compile. Every schema
+// in the tree is already built, every pattern is already compiled and every enum and const value is already
+// decoded, so each call to Validate only walks the instance. A CompiledValidator is safe to use from many goroutines
+// at the same time, which makes it a good fit for a server validating many payloads against the same schema.
+type CompiledValidator struct {
+	schema   *Schema
+	opts     ValidateOptions
+	disabled map[string]bool
+	patterns map[string]*regexp.Regexp
+	values   map[*yaml.Node]any
+}
+
+// Compile will prepare the schema for validating many instances. The whole tree is built (see BuildAll), every
+// 'pattern' and 'patternProperties' regular expression is compiled, and every enum and const value is decoded.
+// An error is returned if any schema in the tree cannot be built, or any pattern is not a valid regular expression.
+//
+// Options are fixed when compiling, and apply to every call to CompiledValidator.Validate.
+func (s *Schema) Compile(opts ...ValidateOption) (*CompiledValidator, error) {
+	if s == nil {
+		return nil, fmt.Errorf("cannot compile a nil schema")
+	}
+	if err := s.BuildAll(); err != nil {
+		return nil, err
+	}
+	c := &CompiledValidator{
+		schema:   s,
+		patterns: make(map[string]*regexp.Regexp),
+		values:   make(map[*yaml.Node]any),
+	}
+	for _, opt := range opts {
+		opt(&c.opts)
+	}
+	if len(c.opts.DisabledKeywords) > 0 {
+		c.disabled = make(map[string]bool, len(c.opts.DisabledKeywords))
+		for _, k := range c.opts.DisabledKeywords {
+			c.disabled[k] = true
+		}
+	}
+
+	seen := map[any]bool{schemaKey(s): true}
+	var compile func(sch *Schema, path string) error
+	compile = func(sch *Schema, path string) error {
+		if err := c.compileSchema(sch, path); err != nil {
+			return err
+		}
+		for _, child := range sch.namedChildProxies() {
+			if _, ok := child.proxy.IsBooleanSchema(); ok {
+				continue
+			}
+			built := child.proxy.Schema()
+			if built == nil || seen[schemaKey(built)] {
+				continue
+			}
+			seen[schemaKey(built)] = true
+			if err := compile(built, path+child.path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := compile(s, ""); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// compileSchema compiles the patterns and decodes the values of a single schema.
+func (c *CompiledValidator) compileSchema(s *Schema, path string) error {
+	exprs := make([]string, 0, 1+orderedmap.Len(s.PatternProperties))
+	if s.Pattern != "" {
+		exprs = append(exprs, s.Pattern)
+	}
+	for pair := orderedmap.First(s.PatternProperties); pair != nil; pair = pair.Next() {
+		exprs = append(exprs, pair.Key())
+	}
+	for _, expr := range exprs {
+		if _, ok := c.patterns[expr]; ok {
+			continue
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return fmt.Errorf("pattern '%s' of schema at '%s' is not a valid regular expression: %w", expr, path, err)
+		}
+		c.patterns[expr] = re
+	}
+	for _, e := range s.Enum {
+		c.values[e] = s.nodeValue(e)
+	}
+	if s.Const != nil {
+		c.values[s.Const] = s.nodeValue(s.Const)
+	}
+	return nil
+}
+
+// Validate will check an instance against the compiled schema, in the same way as Schema.Validate, and return every
+// failure found. If the instance is valid, nothing is returned.
+func (c *CompiledValidator) Validate(instance any) []*ValidationError {
+	v := &schemaValidator{opts: &c.opts, disabled: c.disabled, patterns: c.patterns, compiled: c}
+	instance = normalizeInstance(instance)
+	if c.opts.Coerce {
+		instance = c.schema.coerce(instance)
+	}
+	v.validateSchema(c.schema, instance, "")
+	found := v.errors
+	if c.opts.MaxErrors > 0 && len(found) > c.opts.MaxErrors {
+		found = found[:c.opts.MaxErrors]
+	}
+	if len(found) == 0 {
+		return nil
+	}
+	return found
+}
@@ -0,0 +1,123 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+var compileTestSchema = `type: object
+required: [id, kind]
+properties:
+  id:
+    type: string
+    pattern: '^[a-z]{3}-[0-9]+$'
+  kind:
+    enum: [cat, dog]
+  tags:
+    type: array
+    items:
+      type: string
+      maxLength: 5
+patternProperties:
+  '^x-':
+    type: string
+additionalProperties: false`
+
+func TestSchema_Compile(t *testing.T) {
+	sch := getHighSchema(t, compileTestSchema)
+	c, err := sch.Compile()
+	assert.NoError(t, err)
+
+	valid := map[string]any{"id": "abc-12", "kind": "cat", "tags": []any{"a"}, "x-trace": "on"}
+	invalid := map[string]any{"id": "nope", "kind": "fish", "tags": []any{"toolong"}, "extra": true}
+	assert.Empty(t, c.Validate(valid))
+
+	// the compiled path finds exactly the same failures as the schema.
+	errs := c.Validate(invalid)
+	expected := sch.Validate(invalid)
+	assert.Len(t, errs, 4)
+	assert.Len(t, expected, len(errs))
+	for i := range errs {
+		assert.Equal(t, expected[i], errs[i])
+	}
+	assert.Equal(t, "/id", errs[0].Path)
+	assert.Equal(t, "pattern", errs[0].Keyword)
+}
+
+func TestSchema_Compile_Options(t *testing.T) {
+	c, err := getHighSchema(t, compileTestSchema).Compile(MaxErrors(1), DisableKeywords("enum"))
+	assert.NoError(t, err)
+	errs := c.Validate(map[string]any{"id": "nope", "kind": "fish", "extra": true})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "pattern", errs[0].Keyword)
+}
+
+func TestSchema_Compile_InvalidPattern(t *testing.T) {
+	sch := getHighSchema(t, `type: object
+properties:
+  name:
+    type: string
+    pattern: '[a-'`)
+	c, err := sch.Compile()
+	assert.Nil(t, c)
+	assert.EqualError(t, err, "pattern '[a-' of schema at '/properties/name' is not a valid regular expression: "+
+		"error parsing regexp: missing closing ]: `[a-`")
+}
+
+func TestCompiledValidator_Concurrent(t *testing.T) {
+	c, err := getHighSchema(t, compileTestSchema).Compile()
+	assert.NoError(t, err)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Empty(t, c.Validate(map[string]any{"id": "abc-1", "kind": "dog"}))
+			assert.Len(t, c.Validate(map[string]any{"id": "abc", "kind": "dog"}), 1)
+		}()
+	}
+	wg.Wait()
+}
+
+func benchmarkCompileSchema(b *testing.B) *Schema {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(compileTestSchema), &node); err != nil {
+		b.Fatal(err)
+	}
+	sch, err := NewSchemaFromNode(node.Content[0])
+	if err != nil {
+		b.Fatal(err)
+	}
+	return sch
+}
+
+var benchmarkCompileInstance = map[string]any{
+	"id": "abc-12", "kind": "dog", "tags": []any{"a", "b", "c"}, "x-trace": "on",
+}
+
+func BenchmarkSchema_Validate(b *testing.B) {
+	sch := benchmarkCompileSchema(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		_ = sch.Validate(benchmarkCompileInstance)
+	}
+}
+
+func BenchmarkCompiledValidator_Validate(b *testing.B) {
+	c, err := benchmarkCompileSchema(b).Compile()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		_ = c.Validate(benchmarkCompileInstance)
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"fmt"
+
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/libopenapi/utils"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// schemaKey returns a value that identifies a schema, schemas built from the same node in the document share the
+// same key, which allows circular references (and recursive YAML aliases) to be detected even though every
+// reference builds a new Schema. Schemas without a low-level model are identified by their pointer.
+func schemaKey(s *Schema) any {
+	if s.low != nil && s.low.ParentProxy != nil && s.low.ParentProxy.GetValueNode() != nil {
+		return utils.NodeAlias(s.low.ParentProxy.GetValueNode())
+	}
+	return s
+}
+
+// walkAllOf will call fn with the schema, and then every allOf member (following references) depth first, in the
+// order they are declared. Circular references are only visited once. If fn returns false, the walk stops.
+func (s *Schema) walkAllOf(fn func(sch *Schema) bool) {
+	s.walkAllOfSeen(fn, make(map[any]bool))
+}
+
+func (s *Schema) walkAllOfSeen(fn func(sch *Schema) bool, seen map[any]bool) bool {
+	if s == nil || seen[schemaKey(s)] {
+		return true
+	}
+	seen[schemaKey(s)] = true
+	if !fn(s) {
+		return false
+	}
+	for _, sp := range s.AllOf {
+		if sp == nil {
+			continue
+		}
+		if !sp.Schema().walkAllOfSeen(fn, seen) {
+			return false
+		}
+	}
+	return true
+}
+
+// EffectiveProperty will look up a property by name, searching the properties of the schema first and then the
+// properties of each allOf member, recursively (following references), in the order they are declared. The first
+// match is returned. If no schema in the chain defines the property, false is returned.
+func (s *Schema) EffectiveProperty(name string) (*Schema, bool) {
+	var found *SchemaProxy
+	s.walkAllOf(func(sch *Schema) bool {
+		found = sch.Properties.GetOrZero(name)
+		return found == nil
+	})
+	if found == nil {
+		return nil, false
+	}
+	return found.Schema(), true
+}
+
+// EffectiveDiscriminator will return the discriminator of the schema, or if it does not have one, the discriminator
+// of the closest allOf ancestor (following references). This is how inheritance is modeled, a base schema declares
+// the discriminator and each subtype references the base through allOf. Returns nil if nothing in the chain has a
+// discriminator.
+func (s *Schema) EffectiveDiscriminator() *Discriminator {
+	var found *Discriminator
+	s.walkAllOf(func(sch *Schema) bool {
+		found = sch.Discriminator
+		return found == nil
+	})
+	return found
+}
+
+// EffectiveType returns the declared type of the schema, or if it does not declare one, the type declared by the
+// closest allOf member (following references). This covers the common pattern of a base schema declaring
+// 'type: object' and subtypes only adding properties through allOf. The type is normalized the same way as JSONType,
+// so [string, null] is 'string'. An empty string is returned if nothing in the chain declares a type, or the declared
+// type allows more than one type.
+func (s *Schema) EffectiveType() string {
+	var found string
+	s.walkAllOf(func(sch *Schema) bool {
+		if len(sch.Type) == 0 {
+			return true
+		}
+		found = sch.JSONType()
+		return false
+	})
+	return found
+}
+
+// DiscriminatorValues returns every value the discriminator property can hold, collected from the oneOf members
+// of the schema. Each member (following references and allOf) contributes the const, or single enum value, of its
+// discriminator property. This is the complete set of tags for a union like:
+//
+//	oneOf:
+//	  - properties:
+//	      kind:
+//	        const: cat
+//
+// Values are returned in the order the members are declared, without duplicates. Members whose discriminator
+// property has no single value are skipped, as is everything if the schema has no discriminator.
+func (s *Schema) DiscriminatorValues() []string {
+	d := s.EffectiveDiscriminator()
+	if d == nil || d.PropertyName == "" {
+		return nil
+	}
+	var values []string
+	for _, sp := range s.OneOf {
+		member := sp.Schema()
+		if member == nil {
+			continue
+		}
+		prop, ok := member.EffectiveProperty(d.PropertyName)
+		if !ok || prop == nil {
+			continue
+		}
+		value := prop.Const
+		if value == nil && len(prop.Enum) == 1 {
+			value = prop.Enum[0]
+		}
+		value = utils.NodeAlias(value)
+		if value == nil || value.Kind != yaml.ScalarNode || slices.Contains(values, value.Value) {
+			continue
+		}
+		values = append(values, value.Value)
+	}
+	return values
+}
+
+// UnionMembers will build and return every oneOf and anyOf member of the schema (following references), oneOf
+// members first, each in the order they are declared, along with the discriminator that tells the members apart
+// (see EffectiveDiscriminator), its property name and mapping. This is everything needed to generate a tagged union
+// type. The discriminator is nil if the union has none.
+//
+// If any member cannot be built, the members built so far are returned along with the error.
+func (s *Schema) UnionMembers() ([]*Schema, *Discriminator, error) {
+	discriminator := s.EffectiveDiscriminator()
+	var members []*Schema
+	for _, group := range [][]*SchemaProxy{s.OneOf, s.AnyOf} {
+		for i, sp := range group {
+			if sp == nil {
+				continue
+			}
+			sch, err := sp.BuildSchema()
+			if err != nil || sch == nil {
+				if err == nil {
+					err = fmt.Errorf("schema is empty")
+				}
+				return members, discriminator, fmt.Errorf("union member %d cannot be built: %s", i, err.Error())
+			}
+			members = append(members, sch)
+		}
+	}
+	return members, discriminator, nil
+}
+
+// effectiveProperties returns the properties of the schema and all allOf members (following references), in the
+// order they are declared. When a property is defined more than once, the first definition wins.
+func (s *Schema) effectiveProperties() *orderedmap.Map[string, *SchemaProxy] {
+	props := orderedmap.New[string, *SchemaProxy]()
+	s.walkAllOf(func(sch *Schema) bool {
+		for pair := orderedmap.First(sch.Properties); pair != nil; pair = pair.Next() {
+			if props.GetOrZero(pair.Key()) == nil {
+				props.Set(pair.Key(), pair.Value())
+			}
+		}
+		return true
+	})
+	return props
+}
+
+// EffectiveRequired returns the required properties of the schema and all of its allOf members, recursively
+// (following references), in the order they are declared, without duplicates. This is the set of required properties
+// an inheritance model actually has, a subtype inherits what its base requires. Circular allOf chains are fine.
+func (s *Schema) EffectiveRequired() []string {
+	var required []string
+	s.walkAllOf(func(sch *Schema) bool {
+		for _, r := range sch.Required {
+			if !slices.Contains(required, r) {
+				required = append(required, r)
+			}
+		}
+		return true
+	})
+	return required
+}
+
+// PartitionProperties splits the properties of the schema into those that are required, and those that are optional.
+// Properties and required properties contributed by allOf members (following references) are included, so a
+// property declared by a base schema and required by a subtype is in the required map. A required property that is
+// never declared is not in either map.
+func (s *Schema) PartitionProperties() (required map[string]*SchemaProxy, optional map[string]*SchemaProxy) {
+	required, optional = make(map[string]*SchemaProxy), make(map[string]*SchemaProxy)
+	req := s.EffectiveRequired()
+	for pair := orderedmap.First(s.effectiveProperties()); pair != nil; pair = pair.Next() {
+		if slices.Contains(req, pair.Key()) {
+			required[pair.Key()] = pair.Value()
+		} else {
+			optional[pair.Key()] = pair.Value()
+		}
+	}
+	return required, optional
+}
+
+// PropertiesAtDepth returns the properties that become visible when the schema is expanded to the given depth,
+// useful for tooling that loads large schema trees one level at a time. Depth 0 returns the top level properties,
+// depth 1 returns the properties of those properties, and so on. Properties contributed by allOf members are
+// included.
+//
+// Each key is the path to the property from this schema, made of property names separated by '/' (escaped as a
+// JSON Pointer), for example 'address/street'. Only the schemas on the way down to the requested depth are built.
+// A negative depth returns nothing.
+func (s *Schema) PropertiesAtDepth(d int) map[string]*Schema {
+	found := make(map[string]*Schema)
+	if d < 0 {
+		return found
+	}
+	var expand func(sch *Schema, prefix string, depth int)
+	expand = func(sch *Schema, prefix string, depth int) {
+		for pair := orderedmap.First(sch.effectiveProperties()); pair != nil; pair = pair.Next() {
+			if _, isBool := pair.Value().IsBooleanSchema(); isBool {
+				continue
+			}
+			child := pair.Value().Schema()
+			if child == nil {
+				continue
+			}
+			path := prefix + escapePointer(pair.Key())
+			if depth == d {
+				found[path] = child
+				continue
+			}
+			expand(child, path+"/", depth+1)
+		}
+	}
+	expand(s, "", 0)
+	return found
+}
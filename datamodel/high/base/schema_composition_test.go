@@ -0,0 +1,309 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema_EffectiveProperty(t *testing.T) {
+	sch := getHighSchema(t, `type: object
+properties:
+  name:
+    type: string
+allOf:
+  - type: object
+    properties:
+      age:
+        type: integer
+  - allOf:
+      - properties:
+          email:
+            type: string
+            format: email
+          age:
+            type: string`)
+
+	name, ok := sch.EffectiveProperty("name")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"string"}, name.Type)
+
+	email, ok := sch.EffectiveProperty("email")
+	assert.True(t, ok)
+	assert.Equal(t, "email", email.Format)
+
+	// the first match wins.
+	age, ok := sch.EffectiveProperty("age")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"integer"}, age.Type)
+
+	missing, ok := sch.EffectiveProperty("pizza")
+	assert.False(t, ok)
+	assert.Nil(t, missing)
+}
+
+func TestSchema_EffectiveDiscriminator(t *testing.T) {
+	yml := `openapi: 3.1.0
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [petType]
+      properties:
+        petType:
+          type: string
+      discriminator:
+        propertyName: petType
+    Dog:
+      allOf:
+        - $ref: '#/components/schemas/Pet'
+        - type: object
+          properties:
+            bark:
+              type: boolean
+    Puppy:
+      allOf:
+        - $ref: '#/components/schemas/Dog'
+    Rock:
+      type: object`
+
+	dog := buildComponentSchema(t, yml, "Dog")
+	assert.Nil(t, dog.Discriminator)
+	d := dog.EffectiveDiscriminator()
+	assert.NotNil(t, d)
+	assert.Equal(t, "petType", d.PropertyName)
+
+	assert.Equal(t, "petType", buildComponentSchema(t, yml, "Puppy").EffectiveDiscriminator().PropertyName)
+	assert.Equal(t, "petType", buildComponentSchema(t, yml, "Pet").EffectiveDiscriminator().PropertyName)
+	assert.Nil(t, buildComponentSchema(t, yml, "Rock").EffectiveDiscriminator())
+}
+
+func TestSchema_EffectiveType(t *testing.T) {
+	yml := `openapi: 3.1.0
+components:
+  schemas:
+    Base:
+      type: object
+      properties:
+        id:
+          type: string
+    Sub:
+      allOf:
+        - $ref: '#/components/schemas/Base'
+        - properties:
+            name:
+              type: string
+    Nullable:
+      type: [string, null]
+    Untyped:
+      allOf:
+        - properties:
+            name:
+              type: string`
+
+	sub := buildComponentSchema(t, yml, "Sub")
+	assert.Empty(t, sub.Type)
+	assert.Equal(t, "object", sub.EffectiveType())
+	assert.Equal(t, "object", buildComponentSchema(t, yml, "Base").EffectiveType())
+	assert.Equal(t, "string", buildComponentSchema(t, yml, "Nullable").EffectiveType())
+	assert.Equal(t, "", buildComponentSchema(t, yml, "Untyped").EffectiveType())
+}
+
+func TestSchema_UnionMembers(t *testing.T) {
+	yml := `components:
+  schemas:
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Cat'
+        - $ref: '#/components/schemas/Dog'
+        - $ref: '#/components/schemas/Fish'
+      discriminator:
+        propertyName: petType
+        mapping:
+          cat: '#/components/schemas/Cat'
+          dog: '#/components/schemas/Dog'
+          fish: '#/components/schemas/Fish'
+    Cat:
+      type: object
+      title: Cat
+    Dog:
+      type: object
+      title: Dog
+    Fish:
+      type: object
+      title: Fish`
+
+	sch := buildComponentSchema(t, yml, "Pet")
+	members, discriminator, err := sch.UnionMembers()
+	assert.NoError(t, err)
+	assert.Len(t, members, 3)
+	assert.Equal(t, "Cat", members[0].Title)
+	assert.Equal(t, "Dog", members[1].Title)
+	assert.Equal(t, "Fish", members[2].Title)
+	assert.Equal(t, "petType", discriminator.PropertyName)
+	assert.Equal(t, 3, discriminator.Mapping.Len())
+	assert.Equal(t, "#/components/schemas/Dog", discriminator.Mapping.GetOrZero("dog"))
+
+	none, discriminator, err := getHighSchema(t, `type: string`).UnionMembers()
+	assert.NoError(t, err)
+	assert.Empty(t, none)
+	assert.Nil(t, discriminator)
+}
+
+func TestSchema_DiscriminatorValues(t *testing.T) {
+	yml := `components:
+  schemas:
+    Shape:
+      discriminator:
+        propertyName: kind
+      oneOf:
+        - $ref: '#/components/schemas/Circle'
+        - type: object
+          properties:
+            kind:
+              enum: [square]
+        - allOf:
+            - $ref: '#/components/schemas/Base'
+            - properties:
+                kind:
+                  const: triangle
+        - properties:
+            kind:
+              enum: [any, other]
+    Base:
+      type: object
+      properties:
+        sides:
+          type: integer
+    Circle:
+      type: object
+      properties:
+        kind:
+          const: circle`
+
+	sch := buildComponentSchema(t, yml, "Shape")
+	assert.Equal(t, []string{"circle", "square", "triangle"}, sch.DiscriminatorValues())
+
+	assert.Nil(t, getHighSchema(t, `oneOf:
+  - properties:
+      kind:
+        const: circle`).DiscriminatorValues())
+}
+
+func TestSchema_PartitionProperties(t *testing.T) {
+	yml := `components:
+  schemas:
+    Base:
+      type: object
+      required: [id]
+      properties:
+        id:
+          type: string
+        created:
+          type: string
+    Pet:
+      allOf:
+        - $ref: '#/components/schemas/Base'
+        - type: object
+          required: [name, created]
+          properties:
+            name:
+              type: string
+            nickname:
+              type: string
+      properties:
+        age:
+          type: integer
+      required: [age, ghost]`
+
+	sch := buildComponentSchema(t, yml, "Pet")
+	required, optional := sch.PartitionProperties()
+	assert.Len(t, required, 4)
+	for _, name := range []string{"age", "id", "name", "created"} {
+		assert.NotNil(t, required[name], name)
+	}
+	assert.Len(t, optional, 1)
+	assert.Equal(t, []string{"string"}, optional["nickname"].Schema().Type)
+
+	required, optional = getHighSchema(t, `type: string`).PartitionProperties()
+	assert.Empty(t, required)
+	assert.Empty(t, optional)
+}
+
+func TestSchema_PropertiesAtDepth(t *testing.T) {
+	sch := getHighSchema(t, `type: object
+properties:
+  name:
+    type: string
+  address:
+    type: object
+    properties:
+      street:
+        type: string
+      geo:
+        type: object
+        properties:
+          lat:
+            type: number
+allOf:
+  - properties:
+      owner:
+        type: object
+        properties:
+          email:
+            type: string`)
+
+	top := sch.PropertiesAtDepth(0)
+	assert.Len(t, top, 3)
+	assert.Equal(t, []string{"string"}, top["name"].Type)
+	assert.Equal(t, []string{"object"}, top["address"].Type)
+	assert.NotNil(t, top["owner"])
+
+	// nothing below the requested depth has been built.
+	assert.Nil(t, top["address"].Properties.GetOrZero("geo").rendered)
+
+	one := sch.PropertiesAtDepth(1)
+	assert.Len(t, one, 3)
+	assert.Equal(t, []string{"string"}, one["address/street"].Type)
+	assert.Equal(t, []string{"object"}, one["address/geo"].Type)
+	assert.Equal(t, []string{"string"}, one["owner/email"].Type)
+
+	assert.Len(t, sch.PropertiesAtDepth(2), 1)
+	assert.Empty(t, sch.PropertiesAtDepth(3))
+	assert.Empty(t, sch.PropertiesAtDepth(-1))
+}
+
+func TestSchema_EffectiveRequired(t *testing.T) {
+	yml := `components:
+  schemas:
+    Animal:
+      type: object
+      required: [id, kind]
+      properties:
+        id:
+          type: string
+        kind:
+          type: string
+    Pet:
+      allOf:
+        - $ref: '#/components/schemas/Animal'
+      required: [name, id]
+    Dog:
+      required: [breed]
+      allOf:
+        - $ref: '#/components/schemas/Pet'
+        - required: [barks]
+    Loop:
+      required: [a]
+      allOf:
+        - $ref: '#/components/schemas/Loop'`
+
+	dog := buildComponentSchema(t, yml, "Dog")
+	assert.Equal(t, []string{"breed", "name", "id", "kind", "barks"}, dog.EffectiveRequired())
+	assert.Equal(t, []string{"id", "kind"}, buildComponentSchema(t, yml, "Animal").EffectiveRequired())
+	assert.Equal(t, []string{"a"}, buildComponentSchema(t, yml, "Loop").EffectiveRequired())
+	assert.Empty(t, getHighSchema(t, `type: string`).EffectiveRequired())
+}
@@ -0,0 +1,149 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pb33f/libopenapi/orderedmap"
+	"golang.org/x/exp/slices"
+)
+
+// CoverageItem is a single part of a schema that sample data can exercise, a oneOf or anyOf member, an enum value or
+// an optional property.
+type CoverageItem struct {
+	// Kind is the type of item, one of 'oneOf', 'anyOf', 'enum' or 'property'.
+	Kind string
+
+	// Location is a JSON Pointer to the item in the schema, for example '/properties/pet/oneOf/1'.
+	// Enum values all share the location of the enum.
+	Location string
+
+	// Value is the enum value, as it's written in the schema. Empty for other kinds.
+	Value string
+
+	// Covered is true if at least one sample exercised the item.
+	Covered bool
+}
+
+// CoverageReport describes which parts of a schema were exercised by sample data, it's returned by Schema.Coverage.
+type CoverageReport struct {
+	// Items holds every item found, in the order they were reached.
+	Items []*CoverageItem
+}
+
+// Uncovered returns the items that were not exercised by any sample.
+func (r CoverageReport) Uncovered() []*CoverageItem {
+	var uncovered []*CoverageItem
+	for _, item := range r.Items {
+		if !item.Covered {
+			uncovered = append(uncovered, item)
+		}
+	}
+	return uncovered
+}
+
+// Coverage will run every sample through the schema and report which oneOf and anyOf members, enum values and
+// optional (not required) properties were exercised. This highlights branches of a schema that are not tested.
+//
+// A oneOf or anyOf member is covered when a sample is valid against it. An enum value is covered when a sample has
+// that value, and an optional property is covered when a sample contains it. Only the parts of the schema that are
+// reached by at least one sample can be reported; the properties of a oneOf member that no sample matched are not
+// visited for example, the member itself is reported as uncovered.
+func (s *Schema) Coverage(samples []any) CoverageReport {
+	c := &coverageWalker{items: make(map[string]*CoverageItem)}
+	for _, sample := range samples {
+		c.cover(s, normalizeInstance(sample), "", nil)
+	}
+	return CoverageReport{Items: c.order}
+}
+
+// coverageWalker holds the state of a single Coverage run.
+type coverageWalker struct {
+	items map[string]*CoverageItem
+	order []*CoverageItem
+}
+
+// item returns the CoverageItem for a kind, location and value, creating it the first time it's reached.
+func (c *coverageWalker) item(kind, location, value string) *CoverageItem {
+	key := fmt.Sprintf("%s|%s|%s", kind, location, value)
+	if ci, ok := c.items[key]; ok {
+		return ci
+	}
+	ci := &CoverageItem{Kind: kind, Location: location, Value: value}
+	c.items[key] = ci
+	c.order = append(c.order, ci)
+	return ci
+}
+
+// cover walks the instance through the schema. The active slice holds the schemas already being walked with this
+// same instance (through allOf, oneOf or anyOf), so circular compositions are only walked once.
+func (c *coverageWalker) cover(s *Schema, instance any, location string, active []any) {
+	if s == nil || slices.Contains(active, schemaKey(s)) {
+		return
+	}
+	active = append(active, schemaKey(s))
+
+	for _, e := range s.Enum {
+		ci := c.item("enum", location+"/enum", e.Value)
+		if valuesEqual(instance, s.nodeValue(e)) {
+			ci.Covered = true
+		}
+	}
+
+	switch value := instance.(type) {
+	case map[string]any:
+		for pair := orderedmap.First(s.Properties); pair != nil; pair = pair.Next() {
+			propLocation := location + "/properties/" + escapePointer(pair.Key())
+			propValue, present := value[pair.Key()]
+			if !slices.Contains(s.Required, pair.Key()) {
+				ci := c.item("property", propLocation, "")
+				ci.Covered = ci.Covered || present
+			}
+			if present {
+				c.coverProxy(pair.Value(), propValue, propLocation, nil)
+			}
+		}
+	case []any:
+		for i, item := range value {
+			if i < len(s.PrefixItems) {
+				c.coverProxy(s.PrefixItems[i], item, location+"/prefixItems/"+strconv.Itoa(i), nil)
+				continue
+			}
+			if s.Items != nil && s.Items.IsA() {
+				c.coverProxy(s.Items.A, item, location+"/items", nil)
+			}
+		}
+	}
+
+	for i, sp := range s.AllOf {
+		c.coverProxy(sp, instance, location+"/allOf/"+strconv.Itoa(i), active)
+	}
+	c.coverMembers("oneOf", s.OneOf, instance, location, active)
+	c.coverMembers("anyOf", s.AnyOf, instance, location, active)
+}
+
+// coverMembers marks every oneOf or anyOf member the instance is valid against as covered, and walks into it.
+func (c *coverageWalker) coverMembers(kind string, members []*SchemaProxy, instance any, location string, active []any) {
+	v := &schemaValidator{opts: new(ValidateOptions)}
+	for i, sp := range members {
+		memberLocation := location + "/" + kind + "/" + strconv.Itoa(i)
+		ci := c.item(kind, memberLocation, "")
+		if len(v.validateBranch(sp, instance, "")) == 0 {
+			ci.Covered = true
+			c.coverProxy(sp, instance, memberLocation, active)
+		}
+	}
+}
+
+func (c *coverageWalker) coverProxy(sp *SchemaProxy, instance any, location string, active []any) {
+	if sp == nil {
+		return
+	}
+	if _, isBool := sp.IsBooleanSchema(); isBool {
+		return
+	}
+	c.cover(sp.Schema(), instance, location, active)
+}
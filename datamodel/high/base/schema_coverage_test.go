@@ -0,0 +1,62 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema_Coverage(t *testing.T) {
+	sch := getHighSchema(t, `oneOf:
+  - type: object
+    required: [name]
+    properties:
+      name:
+        type: string
+      size:
+        type: string
+        enum: [small, large]
+  - type: integer
+  - type: boolean`)
+
+	report := sch.Coverage([]any{map[string]any{"name": "pizza", "size": "large"}})
+
+	var uncovered []string
+	for _, item := range report.Uncovered() {
+		uncovered = append(uncovered, item.Kind+" "+item.Location+" "+item.Value)
+	}
+	assert.Equal(t, []string{
+		"enum /oneOf/0/properties/size/enum small",
+		"oneOf /oneOf/1 ",
+		"oneOf /oneOf/2 ",
+	}, uncovered)
+
+	// the required name property is not reported, the optional size property is.
+	assert.Len(t, report.Items, 6)
+
+	report = sch.Coverage([]any{map[string]any{"name": "pizza", "size": "small"}, 12, true,
+		map[string]any{"name": "pizza", "size": "large"}})
+	assert.Empty(t, report.Uncovered())
+}
+
+func TestSchema_Coverage_OptionalProperty(t *testing.T) {
+	sch := getHighSchema(t, `type: object
+properties:
+  name:
+    type: string
+  tags:
+    type: array
+    items:
+      anyOf:
+        - type: string
+        - type: integer`)
+
+	report := sch.Coverage([]any{map[string]any{"tags": []any{"hot"}}})
+	uncovered := report.Uncovered()
+	assert.Len(t, uncovered, 2)
+	assert.Equal(t, "/properties/name", uncovered[0].Location)
+	assert.Equal(t, "/properties/tags/items/anyOf/1", uncovered[1].Location)
+}
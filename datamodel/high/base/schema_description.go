@@ -0,0 +1,52 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	mdImage      = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	mdLink       = regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`)
+	mdRefLink    = regexp.MustCompile(`\[([^\]]+)\]\[[^\]]*\]`)
+	mdAutoLink   = regexp.MustCompile(`<((?:https?|mailto):[^>]+)>`)
+	mdCode       = regexp.MustCompile("`+([^`]+)`+")
+	mdBold       = regexp.MustCompile(`(\*\*|__)(.+?)(\*\*|__)`)
+	mdItalicStar = regexp.MustCompile(`\*([^*\s][^*]*)\*`)
+	mdItalicLine = regexp.MustCompile(`(^|[^\w])_([^_\s][^_]*)_([^\w]|$)`)
+	mdStrike     = regexp.MustCompile(`~~(.+?)~~`)
+	mdHeading    = regexp.MustCompile(`^\s{0,3}#{1,6}\s+`)
+	mdQuote      = regexp.MustCompile(`^\s*>\s?`)
+	mdBullet     = regexp.MustCompile(`^(\s*)[*+-]\s+`)
+)
+
+// DescriptionText returns the description of the schema with Markdown stripped, leaving plain text that is suitable
+// for places where Markdown cannot be rendered, like CLI help. Links and images are replaced by their text,
+// emphasis, strikethrough and code span markers are removed, as are heading and block quote markers.
+//
+// The Description field is not changed, it always holds the raw Markdown.
+func (s *Schema) DescriptionText() string {
+	if s.Description == "" {
+		return ""
+	}
+	lines := strings.Split(strings.ReplaceAll(s.Description, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		line = mdHeading.ReplaceAllString(line, "")
+		line = mdQuote.ReplaceAllString(line, "")
+		line = mdBullet.ReplaceAllString(line, "$1- ")
+		line = mdImage.ReplaceAllString(line, "$1")
+		line = mdLink.ReplaceAllString(line, "$1")
+		line = mdRefLink.ReplaceAllString(line, "$1")
+		line = mdAutoLink.ReplaceAllString(line, "$1")
+		line = mdCode.ReplaceAllString(line, "$1")
+		line = mdBold.ReplaceAllString(line, "$2")
+		line = mdStrike.ReplaceAllString(line, "$1")
+		line = mdItalicStar.ReplaceAllString(line, "$1")
+		line = mdItalicLine.ReplaceAllString(line, "$1$2$3")
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,24 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema_DescriptionText(t *testing.T) {
+	sch := getHighSchema(t, `description: |
+  # Pizza
+  A **delicious** pizza, see [the menu](https://example.com/menu) for _all_ toppings.
+  Use the `+"`topping`"+` field, or ~~ask~~ *call* us at <https://example.com>.
+  > snake_case_names are left alone`)
+
+	assert.Equal(t, "Pizza\nA delicious pizza, see the menu for all toppings.\n"+
+		"Use the topping field, or ask call us at https://example.com.\nsnake_case_names are left alone",
+		sch.DescriptionText())
+	assert.Contains(t, sch.Description, "**delicious**")
+	assert.Empty(t, getHighSchema(t, `type: string`).DescriptionText())
+}
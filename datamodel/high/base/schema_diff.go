@@ -0,0 +1,140 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"strconv"
+
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// NodeDiff is a single difference between two schemas, found by DiffSchemaNodes.
+type NodeDiff struct {
+	// Path is a JSON Pointer to the changed value, for example '/properties/age/maximum'.
+	Path string
+
+	// Old and New are the original nodes of the value, Old is nil when the value was added, New is nil when the
+	// value was removed.
+	Old *yaml.Node
+	New *yaml.Node
+
+	// OldLine and NewLine are the lines of the values in their source documents, or 0 if the value does not exist
+	// on that side (or the schema was not read from a document).
+	OldLine int
+	NewLine int
+}
+
+// DiffSchemaNodes compares two schemas at the node level, and returns a NodeDiff for every scalar value that was
+// changed, added or removed. Diffs are in the order of the old schema, keys that only exist in the new schema come
+// after the other keys of the same mapping. Unlike the 'what-changed' module, this is a raw diff of the YAML
+// that makes up each schema, with the line numbers from the low-level model of each side, which is handy for placing
+// comments inline in a review.
+//
+// Mapping key order, comments and styles are ignored. References are not followed, a changed reference is reported
+// as a changed '$ref' value. The nodes each schema was built from are compared, unless either schema has been
+// changed since it was built (or was built by hand), then both schemas are rendered and the rendered nodes are
+// compared instead. Rendered nodes are not part of a document, so those diffs have no line numbers.
+func DiffSchemaNodes(old, new *Schema) []NodeDiff {
+	var diffs []NodeDiff
+	oldSource, newSource := sourceNode(old), sourceNode(new)
+	if (old == nil || oldSource != nil) && (new == nil || newSource != nil) && !old.modified() && !new.modified() {
+		diffNodes(oldSource, newSource, "", &diffs)
+		return diffs
+	}
+	diffNodes(renderedNode(old), renderedNode(new), "", &diffs)
+	for i := range diffs {
+		diffs[i].OldLine, diffs[i].NewLine = 0, 0
+	}
+	return diffs
+}
+
+// sourceNode returns the node a schema was built from, or nil if it did not come from a document.
+func sourceNode(s *Schema) *yaml.Node {
+	if s == nil || s.low == nil || s.low.ParentProxy == nil || s.low.ParentProxy.GetValueNode() == nil {
+		return nil
+	}
+	return utils.NodeAlias(s.low.ParentProxy.GetValueNode())
+}
+
+// renderedNode returns the schema rendered into a node, or nil if it cannot be rendered.
+func renderedNode(s *Schema) *yaml.Node {
+	if s == nil {
+		return nil
+	}
+	n, err := s.MarshalYAML()
+	if err != nil {
+		return nil
+	}
+	rendered, _ := n.(*yaml.Node)
+	return rendered
+}
+
+// modified returns true if the schema (or any schema built from it) has been changed since it was built from the
+// low-level model, found by rendering it next to a fresh copy built from the same model.
+func (s *Schema) modified() bool {
+	if s == nil || s.low == nil {
+		return false
+	}
+	var changes []NodeDiff
+	diffNodes(renderedNode(NewSchema(s.low)), renderedNode(s), "", &changes)
+	return len(changes) > 0
+}
+
+func diffNodes(a, b *yaml.Node, path string, diffs *[]NodeDiff) {
+	a, b = utils.NodeAlias(a), utils.NodeAlias(b)
+	if a == nil && b == nil {
+		return
+	}
+	if a != nil && b != nil && a.Kind == b.Kind {
+		switch a.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(a.Content); i += 2 {
+				key := a.Content[i].Value
+				diffNodes(a.Content[i+1], mappingValue(b, key), path+"/"+escapePointer(key), diffs)
+			}
+			for i := 0; i+1 < len(b.Content); i += 2 {
+				key := b.Content[i].Value
+				if mappingValue(a, key) == nil {
+					diffNodes(nil, b.Content[i+1], path+"/"+escapePointer(key), diffs)
+				}
+			}
+			return
+		case yaml.SequenceNode:
+			for i := 0; i < len(a.Content) || i < len(b.Content); i++ {
+				var av, bv *yaml.Node
+				if i < len(a.Content) {
+					av = a.Content[i]
+				}
+				if i < len(b.Content) {
+					bv = b.Content[i]
+				}
+				diffNodes(av, bv, path+"/"+strconv.Itoa(i), diffs)
+			}
+			return
+		case yaml.ScalarNode:
+			if a.ShortTag() == b.ShortTag() && a.Value == b.Value {
+				return
+			}
+		}
+	}
+	d := NodeDiff{Path: path, Old: a, New: b}
+	if a != nil {
+		d.OldLine = a.Line
+	}
+	if b != nil {
+		d.NewLine = b.Line
+	}
+	*diffs = append(*diffs, d)
+}
+
+// mappingValue returns the value of a key in a mapping node, or nil if the key does not exist.
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffSchemaNodes(t *testing.T) {
+	old := buildComponentSchema(t, `openapi: 3.1.0
+components:
+  schemas:
+    Pizza:
+      type: object
+      properties:
+        slices:
+          type: integer
+          maximum: 8
+        name:
+          type: string`, "Pizza")
+	new := buildComponentSchema(t, `openapi: 3.1.0
+components:
+  schemas:
+    Pizza:
+      description: a pizza
+      type: object
+      properties:
+        name:
+          type: string
+        slices:
+          type: integer
+          minimum: 1
+          maximum: 12`, "Pizza")
+
+	diffs := DiffSchemaNodes(old, new)
+	assert.Len(t, diffs, 3)
+
+	assert.Equal(t, "/properties/slices/maximum", diffs[0].Path)
+	assert.Equal(t, "8", diffs[0].Old.Value)
+	assert.Equal(t, "12", diffs[0].New.Value)
+	assert.Equal(t, 9, diffs[0].OldLine)
+	assert.Equal(t, 13, diffs[0].NewLine)
+
+	assert.Equal(t, "/properties/slices/minimum", diffs[1].Path)
+	assert.Nil(t, diffs[1].Old)
+	assert.Equal(t, 0, diffs[1].OldLine)
+	assert.Equal(t, 12, diffs[1].NewLine)
+
+	assert.Equal(t, "/description", diffs[2].Path)
+	assert.Equal(t, 5, diffs[2].NewLine)
+
+	assert.Empty(t, DiffSchemaNodes(old, old))
+
+	// schemas built by hand are rendered, so they have no lines.
+	diffs = DiffSchemaNodes(getHighSchema(t, `type: string`), &Schema{Type: []string{"integer"}})
+	assert.Len(t, diffs, 1)
+	assert.Equal(t, "/type", diffs[0].Path)
+	assert.Equal(t, "integer", diffs[0].New.Value)
+}
+
+func TestDiffSchemaNodes_Modified(t *testing.T) {
+	yml := `openapi: 3.1.0
+components:
+  schemas:
+    Pizza:
+      type: object
+      properties:
+        slices:
+          type: integer
+          maximum: 8`
+	old := buildComponentSchema(t, yml, "Pizza")
+	new := buildComponentSchema(t, yml, "Pizza")
+	assert.Empty(t, DiffSchemaNodes(old, new))
+
+	// changed by hand, the source nodes are the same but the schema is not.
+	maximum := 12.0
+	new.Properties.GetOrZero("slices").Schema().Maximum = &maximum
+	new.Description = "a pizza"
+
+	diffs := DiffSchemaNodes(old, new)
+	assert.Len(t, diffs, 2)
+	assert.Equal(t, "/properties/slices/maximum", diffs[0].Path)
+	assert.Equal(t, "8", diffs[0].Old.Value)
+	assert.Equal(t, "12", diffs[0].New.Value)
+	assert.Equal(t, "/description", diffs[1].Path)
+	assert.Nil(t, diffs[1].Old)
+	assert.Equal(t, "a pizza", diffs[1].New.Value)
+
+	// rendered nodes are not in a document, so there are no lines.
+	for _, d := range diffs {
+		assert.Zero(t, d.OldLine)
+		assert.Zero(t, d.NewLine)
+	}
+	assert.Empty(t, DiffSchemaNodes(new, new))
+}
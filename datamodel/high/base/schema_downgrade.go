@@ -0,0 +1,133 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"fmt"
+	"strconv"
+
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// Downgrade30 returns a best-effort OpenAPI 3.0 copy of an OpenAPI 3.1 schema, the original schema is not modified.
+// The following conversions are made, to this schema and every inline child schema (references are left alone):
+//
+//   - 'type: [X, null]' becomes 'type: X' with 'nullable: true'.
+//   - the first value of 'examples' becomes 'example'.
+//   - 'const' becomes an 'enum' with a single value.
+//   - a numeric 'exclusiveMaximum' / 'exclusiveMinimum' becomes 'maximum' / 'minimum' with the boolean form.
+//
+// Keywords that 3.0 does not support (prefixItems, contains, if/then/else, dependentSchemas, dependentRequired,
+// patternProperties, unevaluatedProperties etc.) are dropped. Every lossy change is returned as a warning, prefixed
+// with a JSON Pointer to the child schema (if it's not this schema).
+func (s *Schema) Downgrade30() (*Schema, []string) {
+	var warnings []string
+	d := s.transform(func(sch *Schema, path string) {
+		sch.downgrade30(path, &warnings)
+	})
+	return d, warnings
+}
+
+// downgrade30 converts a copy of a single schema to 3.0, the children are handled by transform.
+func (s *Schema) downgrade30(path string, warnings *[]string) {
+	warn := func(message string, args ...any) {
+		message = fmt.Sprintf(message, args...)
+		if path != "" {
+			message = path + ": " + message
+		}
+		*warnings = append(*warnings, message)
+	}
+	drop := func(keyword string) {
+		warn("'%s' is not supported by OpenAPI 3.0 and has been dropped", keyword)
+	}
+
+	// types
+	if slices.Contains(s.Type, "null") {
+		s.Type = slices.DeleteFunc(slices.Clone(s.Type), func(t string) bool { return t == "null" })
+		nullable := true
+		s.Nullable = &nullable
+	}
+	if len(s.Type) > 1 {
+		warn("multiple types %v are not supported by OpenAPI 3.0, 'type' has been dropped", s.Type)
+		s.Type = nil
+	}
+
+	// values
+	if len(s.Examples) > 0 {
+		if len(s.Examples) > 1 || s.Example != nil {
+			warn("'examples' is not supported by OpenAPI 3.0, only one example has been kept")
+		}
+		if s.Example == nil {
+			s.Example = s.Examples[0]
+		}
+		s.Examples = nil
+	}
+	if s.Const != nil {
+		s.Enum = []*yaml.Node{s.Const}
+		s.Const = nil
+	}
+
+	// numeric exclusive bounds
+	if s.ExclusiveMaximum != nil && s.ExclusiveMaximum.IsB() {
+		v, exclusive, _ := s.upperBound()
+		s.Maximum = &v
+		s.ExclusiveMaximum = &DynamicValue[bool, float64]{A: exclusive}
+	}
+	if s.ExclusiveMinimum != nil && s.ExclusiveMinimum.IsB() {
+		v, exclusive, _ := s.lowerBound()
+		s.Minimum = &v
+		s.ExclusiveMinimum = &DynamicValue[bool, float64]{A: exclusive}
+	}
+
+	// unsupported keywords
+	if s.SchemaTypeRef != "" {
+		drop("$schema")
+		s.SchemaTypeRef = ""
+	}
+	if s.Anchor != "" {
+		drop("$anchor")
+		s.Anchor = ""
+	}
+	if len(s.PrefixItems) > 0 {
+		drop("prefixItems")
+		s.PrefixItems = nil
+	}
+	for _, k := range []struct {
+		keyword string
+		proxy   **SchemaProxy
+	}{
+		{"contains", &s.Contains}, {"if", &s.If}, {"then", &s.Then}, {"else", &s.Else},
+		{"propertyNames", &s.PropertyNames}, {"unevaluatedItems", &s.UnevaluatedItems},
+	} {
+		if *k.proxy != nil {
+			drop(k.keyword)
+			*k.proxy = nil
+		}
+	}
+	if s.MinContains != nil || s.MaxContains != nil {
+		drop("minContains/maxContains")
+		s.MinContains, s.MaxContains = nil, nil
+	}
+	if s.DependentSchemas != nil {
+		drop("dependentSchemas")
+		s.DependentSchemas = nil
+	}
+	if s.DependentRequired != nil {
+		drop("dependentRequired")
+		s.DependentRequired = nil
+	}
+	if s.PatternProperties != nil {
+		drop("patternProperties")
+		s.PatternProperties = nil
+	}
+	if s.UnevaluatedProperties != nil {
+		drop("unevaluatedProperties")
+		s.UnevaluatedProperties = nil
+	}
+	if s.Items != nil && s.Items.IsB() {
+		drop("items: " + strconv.FormatBool(s.Items.B))
+		s.Items = nil
+	}
+}
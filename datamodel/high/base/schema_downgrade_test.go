@@ -0,0 +1,113 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema_Downgrade30_NullableType(t *testing.T) {
+	sch := getHighSchema(t, `type: [string, "null"]`)
+	d, warnings := sch.Downgrade30()
+	assert.Empty(t, warnings)
+	assert.Equal(t, []string{"string"}, d.Type)
+	assert.True(t, *d.Nullable)
+
+	// the original is untouched.
+	assert.Equal(t, []string{"string", "null"}, sch.Type)
+	assert.Nil(t, sch.Nullable)
+
+	d, warnings = getHighSchema(t, `type: [string, integer]`).Downgrade30()
+	assert.Nil(t, d.Type)
+	assert.Equal(t, []string{"multiple types [string integer] are not supported by OpenAPI 3.0, 'type' has been dropped"},
+		warnings)
+}
+
+func TestSchema_Downgrade30_Examples(t *testing.T) {
+	d, warnings := getHighSchema(t, `type: string
+examples: [pizza]`).Downgrade30()
+	assert.Empty(t, warnings)
+	assert.Nil(t, d.Examples)
+	assert.Equal(t, "pizza", d.Example.Value)
+
+	d, warnings = getHighSchema(t, `type: string
+examples: [pizza, pie]`).Downgrade30()
+	assert.Equal(t, "pizza", d.Example.Value)
+	assert.Len(t, warnings, 1)
+}
+
+func TestSchema_Downgrade30_Const(t *testing.T) {
+	d, warnings := getHighSchema(t, `type: string
+const: pizza`).Downgrade30()
+	assert.Empty(t, warnings)
+	assert.Nil(t, d.Const)
+	assert.Len(t, d.Enum, 1)
+	assert.Equal(t, "pizza", d.Enum[0].Value)
+	assert.Empty(t, d.Validate("pizza"))
+	assert.Len(t, d.Validate("pie"), 1)
+}
+
+func TestSchema_Downgrade30_ExclusiveBounds(t *testing.T) {
+	d, warnings := getHighSchema(t, `type: number
+exclusiveMaximum: 10
+exclusiveMinimum: 1`).Downgrade30()
+	assert.Empty(t, warnings)
+	assert.Equal(t, 10.0, *d.Maximum)
+	assert.True(t, d.ExclusiveMaximum.IsA())
+	assert.True(t, d.ExclusiveMaximum.A)
+	assert.Equal(t, 1.0, *d.Minimum)
+	assert.True(t, d.ExclusiveMinimum.A)
+
+	rend, err := d.Render()
+	assert.NoError(t, err)
+	assert.Contains(t, string(rend), "exclusiveMaximum: true")
+	assert.Contains(t, string(rend), "maximum: !!float 10")
+}
+
+func TestSchema_Downgrade30_DroppedKeywords(t *testing.T) {
+	sch := getHighSchema(t, `$schema: https://json-schema.org/draft/2020-12/schema
+type: object
+properties:
+  tags:
+    type: array
+    prefixItems:
+      - type: string
+    contains:
+      const: hot
+  name:
+    type: [string, "null"]
+    const: pizza
+patternProperties:
+  "^x-":
+    type: string
+unevaluatedProperties: false
+if:
+  required: [name]
+then:
+  required: [tags]`)
+
+	d, warnings := sch.Downgrade30()
+	assert.Equal(t, []string{
+		"'$schema' is not supported by OpenAPI 3.0 and has been dropped",
+		"'if' is not supported by OpenAPI 3.0 and has been dropped",
+		"'then' is not supported by OpenAPI 3.0 and has been dropped",
+		"'patternProperties' is not supported by OpenAPI 3.0 and has been dropped",
+		"'unevaluatedProperties' is not supported by OpenAPI 3.0 and has been dropped",
+		"/properties/tags: 'prefixItems' is not supported by OpenAPI 3.0 and has been dropped",
+		"/properties/tags: 'contains' is not supported by OpenAPI 3.0 and has been dropped",
+	}, warnings)
+
+	name := d.Properties.GetOrZero("name").Schema()
+	assert.Equal(t, []string{"string"}, name.Type)
+	assert.True(t, *name.Nullable)
+	assert.Equal(t, "pizza", name.Enum[0].Value)
+
+	rend, err := d.Render()
+	assert.NoError(t, err)
+	assert.NotContains(t, string(rend), "prefixItems")
+	assert.NotContains(t, string(rend), "$schema")
+	assert.Contains(t, string(rend), "nullable: true")
+}
@@ -0,0 +1,385 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	lowbase "github.com/pb33f/libopenapi/datamodel/low/base"
+	"github.com/pb33f/libopenapi/index"
+	"github.com/pb33f/libopenapi/utils"
+	"golang.org/x/sync/syncmap"
+	"gopkg.in/yaml.v3"
+)
+
+// ResolveExternal will resolve a reference to a schema held in another file, using the loader to read that file.
+// The file is parsed, the JSON pointer in the reference (if there is one) is followed and the schema found
+// there is returned. References the loaded file makes to other files are loaded the same way, so properties,
+// items or compositions that point into those files resolve as normal, including those that point back into the
+// document holding the first reference.
+//
+// Paths handed to the loader are cleaned, and are relative to the document holding the reference, for example
+// './schemas/../common.yaml#/Address' will load 'common.yaml'. If the schema was built with a BaseURI (see
+// SchemaBuildOptions), the first reference is relative to that document instead, so '../common.yaml' from
+// 'specs/api/openapi.yaml' loads 'specs/common.yaml'. Absolute paths are never relative to anything.
+//
+// Files are only loaded when a schema needs them, and every proxy built with the same SchemaBuildOptions shares the
+// files loaded, so each file is loaded once (using the loader given first). The resolved schema is kept by the
+// proxy, so calling ResolveExternal (or Schema) again does not load anything. A broken reference in a loaded file
+// is only reported when the schema holding it is built.
+//
+// Schemas that refer to each other across files (through properties for example) resolve fine, children are only
+// built when used. A reference that only points at other references, and eventually back to itself, has no schema
+// to resolve to and is returned as an error.
+//
+// If the proxy is not a reference, or refers to something in the same document (or to an http or https URL), it
+// is built as normal.
+func (sp *SchemaProxy) ResolveExternal(loader func(path string) ([]byte, error)) (*Schema, error) {
+	if !sp.IsReference() {
+		return sp.BuildSchema()
+	}
+	ref := sp.GetReference()
+	if file, _ := splitReference(ref); file == "" || isRemoteReference(file) {
+		if sp.schema == nil {
+			return nil, fmt.Errorf("cannot resolve reference '%s', it has no document to be resolved in", ref)
+		}
+		return sp.BuildSchema()
+	}
+	if loader == nil {
+		return nil, fmt.Errorf("cannot resolve external reference '%s', no loader supplied", ref)
+	}
+
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+	if sp.rendered != nil {
+		return sp.rendered, nil
+	}
+	r, err := sp.externalResolver(loader)
+	if err != nil {
+		return nil, err
+	}
+	base, err := externalBase(sp.opts)
+	if err != nil {
+		return nil, err
+	}
+
+	target, file, err := r.resolve(base, ref)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.WithValue(context.Background(), index.CurrentPathKey, file.path)
+	low := new(lowbase.Schema)
+	if err = low.Build(ctx, target, file.idx); err != nil {
+		return nil, fmt.Errorf("cannot build schema '%s': %w", ref, err)
+	}
+	sch := NewSchemaWithOptions(low, sp.opts.forSource(file.path, r))
+	sch.ParentProxy = sp
+	sp.rendered = sch
+	return sch, nil
+}
+
+// externalResolver returns the resolver shared by every proxy built with the same options, it's created (using the
+// loader) for the first. The proxy lock must be held.
+func (sp *SchemaProxy) externalResolver(loader func(path string) ([]byte, error)) (*externalResolver, error) {
+	if sp.opts == nil {
+		sp.opts = new(SchemaBuildOptions)
+	}
+	resolverLock.Lock()
+	defer resolverLock.Unlock()
+	if sp.opts.resolver != nil {
+		return sp.opts.resolver, nil
+	}
+	root, err := externalBase(sp.opts)
+	if err != nil {
+		return nil, err
+	}
+	r := newExternalResolver(loader, root)
+	r.anonymous = sp.opts.BaseURI == ""
+	// references back into the document holding the first reference are found in the document itself.
+	if sp.schema != nil && sp.schema.Value.GetIndex() != nil {
+		r.document = sp.schema.Value.GetIndex().GetRootNode()
+	}
+	sp.opts.resolver = r
+	return r, nil
+}
+
+// resolverLock guards the resolver held by a set of options.
+var resolverLock sync.Mutex
+
+// forSource returns a copy of the options for schemas built from a file loaded by the resolver, with the BaseURI
+// set to the path of that file. The options are returned as they are, if they are already for that file.
+func (o *SchemaBuildOptions) forSource(path string, r *externalResolver) *SchemaBuildOptions {
+	if o != nil && o.loaded == r && o.BaseURI == path {
+		return o
+	}
+	c := new(SchemaBuildOptions)
+	if o != nil {
+		resolverLock.Lock()
+		*c = *o
+		resolverLock.Unlock()
+	}
+	c.BaseURI = path
+	c.resolver = r
+	c.loaded = r
+	return c
+}
+
+// isRemoteReference returns true if the file part of a reference is an http or https URL, those are left to the
+// low-level model.
+func isRemoteReference(file string) bool {
+	u, err := url.Parse(file)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https")
+}
+
+// externalRoot is the path given to the document holding the first reference when there is no BaseURI, all loaded
+// files sit beneath it.
+var externalRoot = filepath.FromSlash("/root.yaml")
+
+// externalBase returns the path of the document holding the first reference, from the BaseURI in the options. Like
+// externalRoot, the path is absolute, so relative references that climb above the base are kept beneath the root.
+// Schemas built from a loaded file already have the path of that file as their BaseURI.
+func externalBase(opts *SchemaBuildOptions) (string, error) {
+	if opts == nil || opts.BaseURI == "" {
+		return externalRoot, nil
+	}
+	if opts.loaded != nil {
+		return opts.BaseURI, nil
+	}
+	base, err := url.Parse(opts.BaseURI)
+	if err != nil {
+		return "", fmt.Errorf("cannot use base URI '%s': %w", opts.BaseURI, err)
+	}
+	p := base.Path
+	if base.Opaque != "" {
+		p = base.Opaque
+	}
+	return filepath.FromSlash(path.Join("/", p)), nil
+}
+
+// externalFile is a file loaded by an externalResolver, along with an index that can look up references in it.
+type externalFile struct {
+	path string
+	root *yaml.Node
+	idx  *index.SpecIndex
+}
+
+// externalResolver loads and indexes files for ResolveExternal. The indexes of every file share a single cache,
+// before a schema from a loaded file is built, the references it holds are located and placed into that cache, so
+// the low-level model finds them, without knowing anything about the loader.
+type externalResolver struct {
+	lock      sync.Mutex
+	loader    func(path string) ([]byte, error)
+	root      string
+	anonymous bool       // the document holding the first reference has no BaseURI.
+	document  *yaml.Node // the document holding the first reference, if it's known.
+	files     map[string]*externalFile
+	owners    map[*yaml.Node]*externalFile
+	cache     *syncmap.Map
+}
+
+func newExternalResolver(loader func(path string) ([]byte, error), root string) *externalResolver {
+	return &externalResolver{
+		loader: loader,
+		root:   root,
+		files:  make(map[string]*externalFile),
+		owners: make(map[*yaml.Node]*externalFile),
+		cache:  new(syncmap.Map),
+	}
+}
+
+// resolve finds the node a reference made from the file at base points to, and prepares the references it holds.
+func (r *externalResolver) resolve(base, ref string) (*yaml.Node, *externalFile, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	node, f, err := r.locate(base, ref, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	r.prepareReferences(f, node)
+	return node, f, nil
+}
+
+// prepare places the references held by a node into the cache, before a schema is built from it. The node is
+// either one a reference was resolved to, or one held by the file at base.
+func (r *externalResolver) prepare(base string, node *yaml.Node) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	f, ok := r.owners[node]
+	if !ok {
+		var err error
+		if f, err = r.load(base); err != nil {
+			return
+		}
+	}
+	r.prepareReferences(f, node)
+}
+
+// prepareReferences locates every reference held by a node in the file given, and caches them under the full
+// definition the low-level model searches for. References that cannot be located are skipped, the low-level model
+// reports them if the schema holding them is built.
+func (r *externalResolver) prepareReferences(f *externalFile, node *yaml.Node) {
+	for _, ref := range collectReferences(node) {
+		file, fragment, found := strings.Cut(ref, "#")
+		if isRemoteReference(file) {
+			continue
+		}
+		full := r.location(f.path, file)
+		if found {
+			full += "#" + fragment
+		}
+		if _, ok := r.cache.Load(full); ok {
+			continue
+		}
+		target, tf, err := r.locate(f.path, ref, nil)
+		if err != nil {
+			continue
+		}
+		r.owners[target] = tf
+		r.cache.Store(full, &index.Reference{
+			FullDefinition: full,
+			Definition:     ref,
+			Node:           target,
+			Index:          tf.idx,
+			RemoteLocation: tf.path,
+		})
+	}
+}
+
+// location returns the path of a file referenced from the file at base. An empty file is base itself.
+func (r *externalResolver) location(base, file string) string {
+	if file == "" {
+		return base
+	}
+	if filepath.IsAbs(filepath.FromSlash(file)) {
+		return filepath.FromSlash(file)
+	}
+	return filepath.Join(filepath.Dir(base), filepath.FromSlash(file))
+}
+
+// locate finds the node a reference points to, made from the file at base. References to references are followed,
+// seen holds the references already followed, so a loop is reported rather than followed forever.
+func (r *externalResolver) locate(base, ref string, seen map[string]bool) (*yaml.Node, *externalFile, error) {
+	file, fragment := splitReference(ref)
+	location := r.location(base, file)
+	key := location + "#" + fragment
+	if seen == nil {
+		seen = make(map[string]bool)
+	}
+	if seen[key] {
+		return nil, nil, fmt.Errorf("circular reference '%s' cannot be resolved, it only refers to itself", ref)
+	}
+	seen[key] = true
+
+	f, err := r.load(location)
+	if err != nil {
+		return nil, nil, err
+	}
+	node, err := resolvePointer(f.root, fragment)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot resolve reference '%s': %w", ref, err)
+	}
+	if isRef, _, next := utils.IsNodeRefValue(node); isRef {
+		return r.locate(f.path, next, seen)
+	}
+	return node, f, nil
+}
+
+// load reads, parses and indexes a file, once. The document holding the first reference is not loaded if it's
+// already known.
+func (r *externalResolver) load(location string) (*externalFile, error) {
+	if f, ok := r.files[location]; ok {
+		return f, nil
+	}
+	root := r.document
+	if location != r.root || root == nil {
+		if location == r.root && r.anonymous {
+			return nil, fmt.Errorf("cannot resolve a reference back into the document holding the first " +
+				"reference, it has no BaseURI")
+		}
+		name := strings.TrimPrefix(filepath.ToSlash(location), "/")
+		bytes, err := r.loader(name)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load '%s': %w", name, err)
+		}
+		root = new(yaml.Node)
+		if err = yaml.Unmarshal(bytes, root); err != nil {
+			return nil, fmt.Errorf("cannot parse '%s': %w", name, err)
+		}
+	}
+
+	cfg := index.CreateClosedAPIIndexConfig()
+	cfg.SpecAbsolutePath = location
+	f := &externalFile{path: location, root: root, idx: index.NewSpecIndexWithConfig(root, cfg)}
+	f.idx.SetCache(r.cache)
+	r.files[location] = f
+	return f, nil
+}
+
+// splitReference splits a reference into the file it points to, and the JSON pointer into that file.
+func splitReference(ref string) (string, string) {
+	file, fragment, _ := strings.Cut(ref, "#")
+	return file, fragment
+}
+
+// collectReferences returns the value of every $ref found in a node tree, in the order they are found.
+func collectReferences(node *yaml.Node) []string {
+	var refs []string
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		if isRef, _, ref := utils.IsNodeRefValue(n); isRef {
+			refs = append(refs, ref)
+			return
+		}
+		for _, c := range n.Content {
+			walk(c)
+		}
+	}
+	walk(node)
+	return refs
+}
+
+// resolvePointer follows a JSON pointer (RFC 6901) from the root of a document. An empty pointer is the document.
+func resolvePointer(root *yaml.Node, pointer string) (*yaml.Node, error) {
+	node := root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	pointer = strings.TrimPrefix(pointer, "/")
+	if pointer == "" {
+		return node, nil
+	}
+	for _, segment := range strings.Split(pointer, "/") {
+		if s, err := url.PathUnescape(segment); err == nil {
+			segment = s
+		}
+		segment = strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+		node = utils.NodeAlias(node)
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i < len(node.Content)-1; i += 2 {
+				if node.Content[i].Value == segment {
+					next = node.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if i, err := strconv.Atoi(segment); err == nil && i >= 0 && i < len(node.Content) {
+				next = node.Content[i]
+			}
+		}
+		if next == nil {
+			return nil, fmt.Errorf("'%s' not found", segment)
+		}
+		node = next
+	}
+	return node, nil
+}
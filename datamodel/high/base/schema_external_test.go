@@ -0,0 +1,315 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/pb33f/libopenapi/datamodel/low"
+	lowbase "github.com/pb33f/libopenapi/datamodel/low/base"
+	"github.com/pb33f/libopenapi/index"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func stubLoader(files map[string]string, calls map[string]int) func(string) ([]byte, error) {
+	return func(path string) ([]byte, error) {
+		calls[path]++
+		if f, ok := files[path]; ok {
+			return []byte(f), nil
+		}
+		return nil, errors.New("no such file")
+	}
+}
+
+// refProxy returns the proxy for a reference held in a document, built by NewSchemaWithOptions (as the 'not' of a
+// schema), so it has the options given. The document is indexed by idx, which may be nil.
+func refProxy(t *testing.T, ref *yaml.Node, idx *index.SpecIndex, opts *SchemaBuildOptions) *SchemaProxy {
+	lowProxy := new(lowbase.SchemaProxy)
+	assert.NoError(t, lowProxy.Build(context.Background(), nil, ref, idx))
+	return NewSchemaWithOptions(&lowbase.Schema{
+		Not: low.NodeReference[*lowbase.SchemaProxy]{Value: lowProxy, ValueNode: ref},
+	}, opts).Not
+}
+
+func refNode(t *testing.T, ref string) *yaml.Node {
+	var node yaml.Node
+	assert.NoError(t, yaml.Unmarshal([]byte("$ref: '"+ref+"'"), &node))
+	return node.Content[0]
+}
+
+func TestSchemaProxy_ResolveExternal(t *testing.T) {
+	files := map[string]string{
+		"schemas/pet.yaml": `Pet:
+  type: object
+  properties:
+    name:
+      type: string
+    owner:
+      $ref: '../people.yaml#/Person'
+    tags:
+      type: array
+      items:
+        $ref: '#/Tag'
+Tag:
+  type: string
+  maxLength: 10`,
+		"people.yaml": `Person:
+  type: object
+  properties:
+    pets:
+      type: array
+      items:
+        $ref: 'schemas/pet.yaml#/Pet'
+    name:
+      $ref: '#/Name'
+Name:
+  $ref: '#/RealName'
+RealName:
+  type: string
+  minLength: 1`,
+	}
+	calls := make(map[string]int)
+
+	sp := CreateSchemaProxyRef("./schemas/pet.yaml#/Pet")
+	sch, err := sp.ResolveExternal(stubLoader(files, calls))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"object"}, sch.Type)
+
+	tags := sch.Properties.GetOrZero("tags").Schema()
+	assert.Equal(t, int64(10), *tags.Items.A.Schema().MaxLength)
+
+	// the files refer to each other, the cycle is only followed as far as it is walked.
+	owner := sch.Properties.GetOrZero("owner").Schema()
+	assert.Equal(t, []string{"object"}, owner.Type)
+	assert.Equal(t, int64(1), *owner.Properties.GetOrZero("name").Schema().MinLength)
+	pet := owner.Properties.GetOrZero("pets").Schema().Items.A.Schema()
+	assert.NotNil(t, pet.Properties.GetOrZero("owner").Schema())
+
+	// every file is loaded once, and the proxy keeps the result.
+	again, err := sp.ResolveExternal(stubLoader(files, calls))
+	assert.NoError(t, err)
+	assert.Same(t, sch, again)
+	assert.Same(t, sch, sp.Schema())
+	assert.Equal(t, map[string]int{"schemas/pet.yaml": 1, "people.yaml": 1}, calls)
+}
+
+func TestSchemaProxy_ResolveExternal_Chain(t *testing.T) {
+	files := map[string]string{
+		"a.yaml": `Thing:
+  $ref: 'b.json#/definitions/Thing'`,
+		"b.json": `{"definitions": {"Thing": {"type": "integer", "minimum": 3}}}`,
+	}
+	sch, err := CreateSchemaProxyRef("a.yaml#/Thing").ResolveExternal(stubLoader(files, map[string]int{}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"integer"}, sch.Type)
+	assert.Equal(t, 3.0, *sch.Minimum)
+
+	// a whole file can be referenced.
+	sch, err = CreateSchemaProxyRef("c.yaml").ResolveExternal(stubLoader(map[string]string{"c.yaml": "type: boolean"}, map[string]int{}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"boolean"}, sch.Type)
+}
+
+func TestSchemaProxy_ResolveExternal_Errors(t *testing.T) {
+	loops := map[string]string{
+		"a.yaml": `A:
+  $ref: 'b.yaml#/B'`,
+		"b.yaml": `B:
+  $ref: 'a.yaml#/A'`,
+	}
+	_, err := CreateSchemaProxyRef("a.yaml#/A").ResolveExternal(stubLoader(loops, map[string]int{}))
+	assert.ErrorContains(t, err, "circular reference")
+
+	_, err = CreateSchemaProxyRef("missing.yaml#/A").ResolveExternal(stubLoader(loops, map[string]int{}))
+	assert.EqualError(t, err, "cannot load 'missing.yaml': no such file")
+
+	_, err = CreateSchemaProxyRef("c.yaml#/Nope").ResolveExternal(stubLoader(map[string]string{"c.yaml": "A: {}"}, map[string]int{}))
+	assert.EqualError(t, err, "cannot resolve reference 'c.yaml#/Nope': 'Nope' not found")
+
+	_, err = CreateSchemaProxyRef("c.yaml#/A").ResolveExternal(nil)
+	assert.Error(t, err)
+
+	// not a reference, or a local one, builds as normal.
+	inline := CreateSchemaProxy(&Schema{Type: []string{"string"}})
+	sch, err := inline.ResolveExternal(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"string"}, sch.Type)
+}
+
+func TestSchemaProxy_ResolveExternal_BaseURI(t *testing.T) {
+	files := map[string]string{
+		"specs/models/pet.yaml": `Pet:
+  type: object
+  properties:
+    tag:
+      $ref: 'tag.yaml#/Tag'
+    label:
+      $ref: '../shared/label.yaml#/Label'`,
+		"specs/models/tag.yaml": `Tag:
+  type: string`,
+		"specs/shared/label.yaml": `Label:
+  type: object
+  properties:
+    text:
+      $ref: 'text.yaml'`,
+		"specs/shared/text.yaml": `type: string
+maxLength: 20`,
+		"shared/owner.yaml": `type: object`,
+	}
+	calls := make(map[string]int)
+	proxy := func(ref, base string) *SchemaProxy {
+		return refProxy(t, refNode(t, ref), nil, &SchemaBuildOptions{BaseURI: base})
+	}
+
+	// relative to the base, and then relative to the file holding the reference.
+	pet, err := proxy("../models/pet.yaml#/Pet", "specs/api/openapi.yaml").ResolveExternal(stubLoader(files, calls))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"object"}, pet.Type)
+	assert.Equal(t, []string{"string"}, pet.Properties.GetOrZero("tag").Schema().Type)
+
+	// proxies inside loaded files resolve against the file holding them, however deep they are.
+	label, err := pet.Properties.GetOrZero("label").ResolveExternal(stubLoader(files, calls))
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.FromSlash("/specs/shared/label.yaml"), label.SourcePath())
+	text, err := label.Properties.GetOrZero("text").ResolveExternal(stubLoader(files, calls))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(20), *text.MaxLength)
+
+	// absolute references bypass the base.
+	owner, err := proxy("/shared/owner.yaml", "specs/api/openapi.yaml").ResolveExternal(stubLoader(files, calls))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"object"}, owner.Type)
+	assert.Equal(t, map[string]int{
+		"specs/models/pet.yaml": 1, "specs/models/tag.yaml": 1, "specs/shared/label.yaml": 1,
+		"specs/shared/text.yaml": 1, "shared/owner.yaml": 1,
+	}, calls)
+
+	// a file URI works the same way.
+	pet, err = proxy("../models/pet.yaml#/Pet", "file:///specs/api/openapi.yaml").ResolveExternal(stubLoader(files, calls))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"object"}, pet.Type)
+
+	_, err = proxy("pet.yaml", "%zz").ResolveExternal(stubLoader(files, calls))
+	assert.Error(t, err)
+}
+
+func TestSchemaProxy_ResolveExternal_NotRemote(t *testing.T) {
+	files := map[string]string{
+		"httpmodels/pet.yaml": `Pet:
+  type: object`,
+		"https_types.yaml": `type: string`,
+	}
+	calls := make(map[string]int)
+
+	// relative files that happen to start with 'http' are not URLs.
+	sch, err := CreateSchemaProxyRef("httpmodels/pet.yaml#/Pet").ResolveExternal(stubLoader(files, calls))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"object"}, sch.Type)
+	sch, err = CreateSchemaProxyRef("https_types.yaml").ResolveExternal(stubLoader(files, calls))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"string"}, sch.Type)
+
+	// URLs, and references into the same document, need a low-level model to be resolved.
+	_, err = CreateSchemaProxyRef("https://example.com/pet.yaml#/Pet").ResolveExternal(stubLoader(files, calls))
+	assert.EqualError(t, err, "cannot resolve reference 'https://example.com/pet.yaml#/Pet', it has no document to be resolved in")
+	_, err = CreateSchemaProxyRef("#/components/schemas/Pet").ResolveExternal(stubLoader(files, calls))
+	assert.Error(t, err)
+	assert.Equal(t, map[string]int{"httpmodels/pet.yaml": 1, "https_types.yaml": 1}, calls)
+}
+
+func TestSchemaProxy_ResolveExternal_BackIntoDocument(t *testing.T) {
+	files := map[string]string{
+		"specs/common.yaml": `Pet:
+  type: object
+  properties:
+    id:
+      $ref: 'openapi.yaml#/components/schemas/Id'`,
+		"specs/openapi.yaml": `components:
+  schemas:
+    Id:
+      type: integer`,
+	}
+	spec := `components:
+  schemas:
+    Id:
+      type: string
+      format: uuid
+    Pet:
+      $ref: 'common.yaml#/Pet'`
+
+	var doc yaml.Node
+	assert.NoError(t, yaml.Unmarshal([]byte(spec), &doc))
+	idx := index.NewSpecIndexWithConfig(&doc, index.CreateClosedAPIIndexConfig())
+	pet := doc.Content[0].Content[1].Content[1].Content[3]
+
+	// the document holding the first reference is used, rather than loaded.
+	calls := make(map[string]int)
+	sch, err := refProxy(t, pet, idx, &SchemaBuildOptions{BaseURI: "specs/openapi.yaml"}).ResolveExternal(stubLoader(files, calls))
+	assert.NoError(t, err)
+	assert.Equal(t, "uuid", sch.Properties.GetOrZero("id").Schema().Format)
+	assert.Equal(t, map[string]int{"specs/common.yaml": 1}, calls)
+
+	// without it, it's loaded like any other file.
+	calls = make(map[string]int)
+	sch, err = refProxy(t, refNode(t, "common.yaml#/Pet"), nil, &SchemaBuildOptions{BaseURI: "specs/openapi.yaml"}).
+		ResolveExternal(stubLoader(files, calls))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"integer"}, sch.Properties.GetOrZero("id").Schema().Type)
+	assert.Equal(t, map[string]int{"specs/common.yaml": 1, "specs/openapi.yaml": 1}, calls)
+
+	// with no BaseURI and no document, there is nothing to resolve into.
+	sch, err = CreateSchemaProxyRef("specs/common.yaml#/Pet").ResolveExternal(stubLoader(map[string]string{
+		"specs/common.yaml": `Pet:
+  $ref: '../root.yaml#/Id'`,
+	}, map[string]int{}))
+	assert.Nil(t, sch)
+	assert.ErrorContains(t, err, "it has no BaseURI")
+}
+
+func TestSchemaProxy_ResolveExternal_Lazy(t *testing.T) {
+	files := map[string]string{
+		"common.yaml": `Address:
+  type: object
+  properties:
+    street:
+      type: string
+    country:
+      $ref: 'countries.yaml#/Country'
+Broken:
+  type: object
+  properties:
+    nope:
+      $ref: 'missing.yaml#/Nope'
+Unrelated:
+  $ref: 'other.yaml#/Other'`,
+		"countries.yaml": `Country:
+  type: string
+  minLength: 2`,
+	}
+	calls := make(map[string]int)
+
+	// a broken reference elsewhere in the file makes no difference, and only the files needed are loaded.
+	opts := &SchemaBuildOptions{}
+	sch, err := refProxy(t, refNode(t, "common.yaml#/Address"), nil, opts).ResolveExternal(stubLoader(files, calls))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"object"}, sch.Type)
+	assert.Equal(t, map[string]int{"common.yaml": 1, "countries.yaml": 1}, calls)
+	assert.Equal(t, int64(2), *sch.Properties.GetOrZero("country").Schema().MinLength)
+
+	// the broken reference is reported when it's used.
+	_, err = refProxy(t, refNode(t, "common.yaml#/Broken"), nil, opts).ResolveExternal(stubLoader(files, calls))
+	assert.ErrorContains(t, err, "missing.yaml")
+
+	// proxies built with the same options share the files loaded.
+	for i := 0; i < 10; i++ {
+		sch, err = refProxy(t, refNode(t, "./common.yaml#/Address"), nil, opts).ResolveExternal(stubLoader(files, calls))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), *sch.Properties.GetOrZero("country").Schema().MinLength)
+	}
+	assert.Equal(t, map[string]int{"common.yaml": 1, "countries.yaml": 1, "missing.yaml": 1}, calls)
+}
@@ -0,0 +1,299 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"context"
+	"fmt"
+
+	lowmodel "github.com/pb33f/libopenapi/datamodel/low"
+	lowbase "github.com/pb33f/libopenapi/datamodel/low/base"
+	"github.com/pb33f/libopenapi/index"
+	"github.com/pb33f/libopenapi/json"
+	"github.com/pb33f/libopenapi/utils"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// RenderFlattened will return a YAML representation of the Schema object with every $ref replaced by the schema
+// it points to, so the output is self-contained and can be handed to consumers that cannot follow references.
+//
+// The resolver is asked for the schema behind each reference (like '#/$defs/Address'), if it is nil, or returns
+// nil, the reference is looked up in the index the schema was built with. References inside a resolved schema are
+// flattened too. A reference that cannot be resolved is returned as an error, rather than left dangling.
+//
+// A circular reference cannot be inlined forever, the second time a reference is reached inside itself, it is
+// replaced by an empty schema (which allows anything), with a description naming the reference.
+func (s *Schema) RenderFlattened(resolver func(string) *Schema) ([]byte, error) {
+	f := &schemaFlattener{root: s, resolver: resolver}
+	n, err := f.render(s, nil)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(n)
+}
+
+// RenderOptions controls how Schema.RenderWithOptions and Schema.RenderJSONWithOptions render a schema.
+type RenderOptions struct {
+	// InlineRefs replaces every $ref with the schema it points to, in the same way as RenderFlattened, so circular
+	// references are replaced with a stub the second time they are reached. A '$ref' inside a value, like an
+	// example, is not a reference and is kept. By default references are rendered as they are, like Render.
+	InlineRefs bool
+
+	// Resolver is asked for the schema behind each reference when InlineRefs is on, see RenderFlattened.
+	Resolver func(ref string) *Schema
+}
+
+// RenderOption is used to change the RenderOptions used by Schema.RenderWithOptions.
+type RenderOption func(opts *RenderOptions)
+
+// InlineRefs will return a RenderOption that turns inlining references on or off, see RenderOptions.InlineRefs.
+func InlineRefs(inline bool) RenderOption {
+	return func(opts *RenderOptions) {
+		opts.InlineRefs = inline
+	}
+}
+
+// RefResolver will return a RenderOption that sets the resolver used to inline references, see
+// RenderOptions.Resolver.
+func RefResolver(resolver func(ref string) *Schema) RenderOption {
+	return func(opts *RenderOptions) {
+		opts.Resolver = resolver
+	}
+}
+
+// RenderWithOptions will return a YAML representation of the Schema object, rendered using the options supplied.
+// With no options the output is identical to Render, with InlineRefs(true) it's identical to RenderFlattened. One
+// method serves both distributing a schema as it was written, and bundling it into a self-contained copy.
+func (s *Schema) RenderWithOptions(opts ...RenderOption) ([]byte, error) {
+	n, err := s.renderNode(opts)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(n)
+}
+
+// RenderJSONWithOptions will return a JSON representation of the Schema object, rendered using the options supplied
+// in the same way as RenderWithOptions. With no options the output is identical to RenderJSON.
+func (s *Schema) RenderJSONWithOptions(indention string, opts ...RenderOption) ([]byte, error) {
+	n, err := s.renderNode(opts)
+	if err != nil {
+		return nil, err
+	}
+	return json.YAMLNodeToJSON(n, indention)
+}
+
+// renderNode renders the schema into a node, inlining references if the options ask for it.
+func (s *Schema) renderNode(opts []RenderOption) (*yaml.Node, error) {
+	options := new(RenderOptions)
+	for _, opt := range opts {
+		opt(options)
+	}
+	if options.InlineRefs {
+		return (&schemaFlattener{root: s, resolver: options.Resolver}).render(s, nil)
+	}
+	n, err := s.MarshalYAML()
+	if err != nil {
+		return nil, err
+	}
+	return n.(*yaml.Node), nil
+}
+
+// EqualsResolved returns true if both schemas have the same structure once every $ref has been replaced by the
+// schema it points to, so a property that refers to '#/components/schemas/Pet' is equal to one with a copy of Pet
+// inlined. That's useful to find duplicate shapes across a document, however they are written.
+//
+// References are resolved the same way as RenderFlattened, using the resolver and then the index each schema was
+// built with. Key order, comments and styles are ignored. If either schema has a reference that cannot be resolved,
+// false is returned.
+func (s *Schema) EqualsResolved(other *Schema, resolver func(string) *Schema) bool {
+	if s == nil || other == nil {
+		return s == other
+	}
+	a, err := (&schemaFlattener{root: s, resolver: resolver}).render(s, nil)
+	if err != nil {
+		return false
+	}
+	b, err := (&schemaFlattener{root: other, resolver: resolver}).render(other, nil)
+	if err != nil {
+		return false
+	}
+	return nodesEqual(a, b, make(map[[2]*yaml.Node]bool))
+}
+
+// schemaFlattener inlines references for RenderFlattened.
+type schemaFlattener struct {
+	root     *Schema
+	resolver func(string) *Schema
+}
+
+// render renders a schema and flattens the result, active holds the references currently being inlined.
+func (f *schemaFlattener) render(sch *Schema, active []string) (*yaml.Node, error) {
+	rendered, err := sch.MarshalYAML()
+	if err != nil {
+		return nil, err
+	}
+	return f.flatten(rendered.(*yaml.Node), active)
+}
+
+// flatten returns a copy of a rendered schema, with any reference to a schema (at any depth) replaced by its schema.
+func (f *schemaFlattener) flatten(n *yaml.Node, active []string) (*yaml.Node, error) {
+	return rewriteSchemas(n, func(sch *yaml.Node) (*yaml.Node, bool, error) {
+		ref, isRef := schemaReference(sch)
+		if !isRef {
+			return sch, false, nil
+		}
+		inlined, err := f.inline(sch, ref, active)
+		return inlined, true, err
+	})
+}
+
+// inline returns the schema behind a reference, flattened, merged with anything next to the reference.
+func (f *schemaFlattener) inline(n *yaml.Node, ref string, active []string) (*yaml.Node, error) {
+	var inlined *yaml.Node
+	for _, a := range active {
+		if a == ref {
+			inlined = utils.CreateEmptyMapNode()
+			inlined.Content = append(inlined.Content, utils.CreateStringNode("description"),
+				utils.CreateStringNode(fmt.Sprintf("circular reference to '%s'", ref)))
+			break
+		}
+	}
+	if inlined == nil {
+		target, err := f.resolve(ref)
+		if err != nil {
+			return nil, err
+		}
+		if inlined, err = f.render(target, append(active, ref)); err != nil {
+			return nil, err
+		}
+	}
+
+	// anything next to the reference (allowed since 3.1) is kept, and wins over the same keyword in the target.
+	siblings := utils.CreateEmptyMapNode()
+	for i := 0; i < len(n.Content)-1; i += 2 {
+		if n.Content[i].Value != "$ref" {
+			siblings.Content = append(siblings.Content, n.Content[i], n.Content[i+1])
+		}
+	}
+	if len(siblings.Content) == 0 {
+		return inlined, nil
+	}
+	siblings, err := f.flatten(siblings, active)
+	if err != nil {
+		return nil, err
+	}
+	merged := utils.CreateEmptyMapNode()
+	for i := 0; i < len(inlined.Content)-1; i += 2 {
+		if _, v := utils.FindKeyNodeTop(inlined.Content[i].Value, siblings.Content); v == nil {
+			merged.Content = append(merged.Content, inlined.Content[i], inlined.Content[i+1])
+		}
+	}
+	merged.Content = append(merged.Content, siblings.Content...)
+	return merged, nil
+}
+
+// subschemaKeywords holds every keyword with a value made of schemas. Keywords set to true hold many schemas, a
+// list (like allOf) or a mapping (like properties), the rest hold a single schema. Every other keyword holds a
+// value (like example, default, enum or const), even if that value looks like a schema.
+var subschemaKeywords = map[string]bool{
+	"allOf": true, "anyOf": true, "oneOf": true, "prefixItems": true, "properties": true, "patternProperties": true,
+	"dependentSchemas": true, "$defs": true, "definitions": true, "items": false, "additionalProperties": false,
+	"unevaluatedProperties": false, "unevaluatedItems": false, "contains": false, "propertyNames": false,
+	"if": false, "then": false, "else": false, "not": false, "contentSchema": false,
+}
+
+// rewriteSchemas returns a copy of a rendered schema, with fn applied to the schema and then to every schema held
+// by its keywords, at any depth. fn returns the node to use in place of the schema, and true if that node is
+// complete, otherwise the schemas held by the node returned are rewritten as well. Values that are not schemas
+// (like an example holding a '$ref' key) are never passed to fn. Rendered nodes can be shared with the low-level
+// model, so nothing is changed in place.
+func rewriteSchemas(n *yaml.Node, fn func(sch *yaml.Node) (*yaml.Node, bool, error)) (*yaml.Node, error) {
+	if n == nil {
+		return nil, nil
+	}
+	n, done, err := fn(n)
+	if err != nil || done || n.Kind != yaml.MappingNode {
+		return n, err
+	}
+	c := *n
+	c.Content = slices.Clone(n.Content)
+	for i := 1; i < len(c.Content); i += 2 {
+		many, ok := subschemaKeywords[c.Content[i-1].Value]
+		switch {
+		case !ok:
+			continue
+		case many || c.Content[i].Kind == yaml.SequenceNode:
+			// items may be a list of schemas in older versions.
+			c.Content[i], err = rewriteEachSchema(c.Content[i], fn)
+		default:
+			c.Content[i], err = rewriteSchemas(c.Content[i], fn)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &c, nil
+}
+
+// rewriteEachSchema returns a copy of a list or mapping of schemas, with every schema in it passed to
+// rewriteSchemas.
+func rewriteEachSchema(n *yaml.Node, fn func(sch *yaml.Node) (*yaml.Node, bool, error)) (*yaml.Node, error) {
+	if n.Kind != yaml.SequenceNode && n.Kind != yaml.MappingNode {
+		return n, nil
+	}
+	c := *n
+	c.Content = slices.Clone(n.Content)
+	for i := range c.Content {
+		// mapping keys are never schemas, so they are kept as they are.
+		if n.Kind == yaml.MappingNode && i%2 == 0 {
+			continue
+		}
+		sch, err := rewriteSchemas(c.Content[i], fn)
+		if err != nil {
+			return nil, err
+		}
+		c.Content[i] = sch
+	}
+	return &c, nil
+}
+
+// resolve finds the schema behind a reference, using the resolver first, then the index of the root schema.
+func (f *schemaFlattener) resolve(ref string) (*Schema, error) {
+	if f.resolver != nil {
+		if sch := f.resolver(ref); sch != nil {
+			return sch, nil
+		}
+	}
+	var idx *index.SpecIndex
+	if f.root.low != nil {
+		idx = f.root.low.Index
+	}
+	if idx != nil {
+		if found := idx.FindComponent(ref); found != nil && found.Node != nil {
+			low := new(lowbase.Schema)
+			if err := lowmodel.BuildModel(found.Node, low); err != nil {
+				return nil, fmt.Errorf("cannot flatten reference '%s': %w", ref, err)
+			}
+			if err := low.Build(context.Background(), found.Node, idx); err != nil {
+				return nil, fmt.Errorf("cannot flatten reference '%s': %w", ref, err)
+			}
+			return NewSchemaWithOptions(low, f.root.opts), nil
+		}
+	}
+	return nil, fmt.Errorf("cannot flatten reference '%s', it cannot be resolved", ref)
+}
+
+// schemaReference returns the reference held by a rendered schema, if it is a mapping with a '$ref' scalar.
+func schemaReference(n *yaml.Node) (string, bool) {
+	if n.Kind != yaml.MappingNode {
+		return "", false
+	}
+	for i := 0; i < len(n.Content)-1; i += 2 {
+		if n.Content[i].Value == "$ref" && n.Content[i+1].Kind == yaml.ScalarNode {
+			return n.Content[i+1].Value, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,268 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSchema_RenderFlattened(t *testing.T) {
+	yml := `type: object
+properties:
+  home:
+    $ref: '#/$defs/Address'
+  work:
+    $ref: '#/$defs/Address'
+    description: where the money is made
+  boss:
+    $ref: '#/$defs/Person'
+$defs:
+  Address:
+    type: object
+    description: an address
+    properties:
+      street:
+        $ref: '#/$defs/Street'
+  Street:
+    type: string
+    maxLength: 100
+  Person:
+    type: object
+    properties:
+      boss:
+        $ref: '#/$defs/Person'`
+
+	var node yaml.Node
+	_ = yaml.Unmarshal([]byte(yml), &node)
+	sch, err := NewSchemaFromNode(&node)
+	assert.NoError(t, err)
+
+	out, err := sch.RenderFlattened(nil)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(out), "$ref")
+
+	expected := `type: object
+properties:
+    home:
+        type: object
+        description: an address
+        properties:
+            street:
+                type: string
+                maxLength: 100
+    work:
+        type: object
+        properties:
+            street:
+                type: string
+                maxLength: 100
+        description: where the money is made
+    boss:
+        type: object
+        properties:
+            boss:
+                description: circular reference to '#/$defs/Person'
+`
+	assert.Equal(t, expected, string(out))
+}
+
+func TestSchema_RenderFlattened_Values(t *testing.T) {
+	// a '$ref' key inside a value (like an example of a JSON Schema document) is data, not a reference.
+	yml := `type: object
+properties:
+  document:
+    type: object
+    example:
+      $ref: '#/$defs/Missing'
+    default:
+      $ref: '#/$defs/Missing'
+    enum:
+      - $ref: '#/$defs/Missing'
+  tag:
+    $ref: '#/$defs/Tag'
+$defs:
+  Tag:
+    type: string
+    const:
+      $ref: '#/$defs/Missing'`
+
+	var node yaml.Node
+	_ = yaml.Unmarshal([]byte(yml), &node)
+	sch, err := NewSchemaFromNode(&node)
+	assert.NoError(t, err)
+
+	out, err := sch.RenderFlattened(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `type: object
+properties:
+    document:
+        type: object
+        example:
+            $ref: '#/$defs/Missing'
+        default:
+            $ref: '#/$defs/Missing'
+        enum:
+            - $ref: '#/$defs/Missing'
+    tag:
+        type: string
+        const:
+            $ref: '#/$defs/Missing'
+`, string(out))
+}
+
+func TestSchema_RenderFlattened_Resolver(t *testing.T) {
+	sch := buildComponentSchema(t, `components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        tag:
+          $ref: '#/components/schemas/Tag'
+    Tag:
+      type: string`, "Pet")
+
+	var asked []string
+	out, err := sch.RenderFlattened(func(ref string) *Schema {
+		asked = append(asked, ref)
+		return &Schema{Type: []string{"integer"}}
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"#/components/schemas/Tag"}, asked)
+	assert.Equal(t, "type: object\nproperties:\n    tag:\n        type: integer\n", string(out))
+
+	missing := getHighSchema(t, `type: object`)
+	missing.Not = CreateSchemaProxyRef("#/$defs/Nope")
+	_, err = missing.RenderFlattened(func(string) *Schema { return nil })
+	assert.EqualError(t, err, "cannot flatten reference '#/$defs/Nope', it cannot be resolved")
+}
+
+func TestSchema_RenderWithOptions(t *testing.T) {
+	sch := buildComponentSchema(t, `components:
+  schemas:
+    Person:
+      type: object
+      properties:
+        tag:
+          $ref: '#/components/schemas/Tag'
+        boss:
+          $ref: '#/components/schemas/Person'
+    Tag:
+      type: string`, "Person")
+
+	// references are kept by default.
+	out, err := sch.RenderWithOptions()
+	assert.NoError(t, err)
+	rendered, _ := sch.Render()
+	assert.Equal(t, string(rendered), string(out))
+	out, err = sch.RenderWithOptions(InlineRefs(false))
+	assert.NoError(t, err)
+	assert.Equal(t, string(rendered), string(out))
+	assert.Contains(t, string(out), "$ref: '#/components/schemas/Tag'")
+
+	// or inlined, with a stub for the cycle.
+	out, err = sch.RenderWithOptions(InlineRefs(true))
+	assert.NoError(t, err)
+	flattened, _ := sch.RenderFlattened(nil)
+	assert.Equal(t, string(flattened), string(out))
+	assert.NotContains(t, string(out), "$ref")
+
+	js, err := sch.RenderJSONWithOptions("  ", InlineRefs(true), RefResolver(func(ref string) *Schema {
+		if ref == "#/components/schemas/Tag" {
+			return &Schema{Type: []string{"integer"}}
+		}
+		return nil
+	}))
+	assert.NoError(t, err)
+	assert.Contains(t, string(js), `"type": "integer"`)
+	assert.NotContains(t, string(js), "$ref")
+
+	js, err = sch.RenderJSONWithOptions("  ")
+	assert.NoError(t, err)
+	rendered, _ = sch.RenderJSON("  ")
+	assert.Equal(t, string(rendered), string(js))
+	assert.Contains(t, string(js), `"$ref": "#/components/schemas/Tag"`)
+}
+
+func TestSchema_RenderWithOptions_Values(t *testing.T) {
+	sch := buildComponentSchema(t, `components:
+  schemas:
+    Link:
+      type: object
+      properties:
+        tag:
+          $ref: '#/components/schemas/Tag'
+      example:
+        $ref: '#/components/schemas/Unknown'
+    Tag:
+      type: string`, "Link")
+
+	out, err := sch.RenderWithOptions(InlineRefs(true))
+	assert.NoError(t, err)
+	assert.Equal(t, `type: object
+properties:
+    tag:
+        type: string
+example:
+    $ref: '#/components/schemas/Unknown'
+`, string(out))
+
+	js, err := sch.RenderJSONWithOptions("", InlineRefs(true))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type": "object", "properties": {"tag": {"type": "string"}},
+  "example": {"$ref": "#/components/schemas/Unknown"}}`, string(js))
+}
+
+func TestSchema_EqualsResolved(t *testing.T) {
+	doc := `components:
+  schemas:
+    ByRef:
+      type: object
+      properties:
+        pet:
+          $ref: '#/components/schemas/Pet'
+    Inlined:
+      type: object
+      properties:
+        pet:
+          properties:
+            name:
+              type: string
+          type: object
+    Different:
+      type: object
+      properties:
+        pet:
+          type: object
+          properties:
+            name:
+              type: integer
+    Pet:
+      type: object
+      properties:
+        name:
+          type: string`
+	byRef := buildComponentSchema(t, doc, "ByRef")
+	inlined := buildComponentSchema(t, doc, "Inlined")
+	different := buildComponentSchema(t, doc, "Different")
+
+	assert.True(t, byRef.EqualsResolved(inlined, nil))
+	assert.True(t, inlined.EqualsResolved(byRef, nil))
+	assert.False(t, byRef.EqualsResolved(different, nil))
+	assert.False(t, byRef.EqualsResolved(nil, nil))
+
+	// without resolving, the reference is not the same as the inlined copy.
+	assert.NotEmpty(t, DiffSchemaNodes(byRef, inlined))
+
+	// the resolver is asked first.
+	assert.True(t, byRef.EqualsResolved(different, func(string) *Schema {
+		return different.Properties.GetOrZero("pet").Schema()
+	}))
+
+	missing := getHighSchema(t, `type: object`)
+	missing.Not = CreateSchemaProxyRef("#/$defs/Nope")
+	assert.False(t, missing.EqualsResolved(missing, nil))
+}
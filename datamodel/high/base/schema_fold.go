@@ -0,0 +1,54 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+// foldStop wraps the accumulator returned by StopFold.
+type foldStop struct {
+	acc any
+}
+
+// StopFold can be returned by the function passed to Fold, to end the traversal early. Fold returns acc.
+func StopFold(acc any) any {
+	return foldStop{acc: acc}
+}
+
+// Fold will visit this schema and every schema below it, depth first, parents before their children, passing an
+// accumulator from one call of fn to the next. The value fn returns is handed to the next call, and the last value
+// is returned, init is used for the first call. Useful to compute something across a whole tree (every format used,
+// the number of properties, etc.) without any state kept outside the traversal.
+//
+// The path is a JSON Pointer to the schema from this one, the root is an empty string. References are followed,
+// and each schema is only visited once, so circular trees are fine. Boolean schemas, and schemas that cannot be
+// built, are skipped. Return the result of StopFold to stop the traversal before the whole tree has been visited.
+func (s *Schema) Fold(init any, fn func(acc any, path string, s *Schema) any) any {
+	if s == nil {
+		return init
+	}
+	acc := init
+	stopped := false
+	seen := make(map[any]bool)
+	var visit func(sch *Schema, path string)
+	visit = func(sch *Schema, path string) {
+		seen[schemaKey(sch)] = true
+		acc = fn(acc, path, sch)
+		if stop, ok := acc.(foldStop); ok {
+			acc, stopped = stop.acc, true
+			return
+		}
+		for _, child := range sch.namedChildProxies() {
+			if _, ok := child.proxy.IsBooleanSchema(); ok {
+				continue
+			}
+			built := child.proxy.Schema()
+			if built == nil || seen[schemaKey(built)] {
+				continue
+			}
+			if visit(built, path+child.path); stopped {
+				return
+			}
+		}
+	}
+	visit(s, "")
+	return acc
+}
@@ -0,0 +1,62 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/exp/slices"
+)
+
+func TestSchema_Fold(t *testing.T) {
+	sch := buildComponentSchema(t, `components:
+  schemas:
+    Order:
+      type: object
+      properties:
+        id:
+          type: string
+          format: uuid
+        count:
+          type: integer
+        customer:
+          $ref: '#/components/schemas/Customer'
+        notes:
+          type: array
+          items:
+            type: string
+    Customer:
+      type: object
+      properties:
+        name:
+          type: string
+        referrer:
+          $ref: '#/components/schemas/Customer'`, "Order")
+
+	paths := sch.Fold([]string{}, func(acc any, path string, s *Schema) any {
+		if slices.Contains(s.Type, "string") {
+			return append(acc.([]string), path)
+		}
+		return acc
+	})
+	assert.Equal(t, []string{"/properties/id", "/properties/customer/properties/name", "/properties/notes/items"},
+		paths)
+
+	count := sch.Fold(0, func(acc any, _ string, _ *Schema) any {
+		return acc.(int) + 1
+	})
+	assert.Equal(t, 7, count)
+
+	first := sch.Fold("", func(acc any, path string, s *Schema) any {
+		if s.Format != "" {
+			return StopFold(path)
+		}
+		return acc
+	})
+	assert.Equal(t, "/properties/id", first)
+
+	var nilSchema *Schema
+	assert.Equal(t, 12, nilSchema.Fold(12, nil))
+}
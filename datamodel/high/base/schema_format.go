@@ -0,0 +1,101 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"encoding/base64"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+var (
+	uuidFormat     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hostnameFormat = regexp.MustCompile(`^(?i)[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?(\.[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?)*$`)
+)
+
+// stringFormats holds a checker for each string format that can be asserted. Formats that are not listed are
+// always treated as annotations.
+var stringFormats = map[string]func(value string) bool{
+	"email": func(value string) bool {
+		addr, err := mail.ParseAddress(value)
+		return err == nil && addr.Address == value
+	},
+	"date": func(value string) bool {
+		_, err := time.Parse(time.DateOnly, value)
+		return err == nil
+	},
+	"date-time": func(value string) bool {
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	},
+	"time": func(value string) bool {
+		_, err := time.Parse("15:04:05Z07:00", value)
+		return err == nil
+	},
+	"uuid": uuidFormat.MatchString,
+	"ipv4": func(value string) bool {
+		ip := net.ParseIP(value)
+		return ip != nil && ip.To4() != nil && !strings.Contains(value, ":")
+	},
+	"ipv6": func(value string) bool {
+		return net.ParseIP(value) != nil && strings.Contains(value, ":")
+	},
+	"hostname": func(value string) bool {
+		return len(value) <= 253 && hostnameFormat.MatchString(value)
+	},
+	"uri": func(value string) bool {
+		u, err := url.Parse(value)
+		return err == nil && u.Scheme != ""
+	},
+	"uri-reference": func(value string) bool {
+		_, err := url.Parse(value)
+		return err == nil
+	},
+	"byte": func(value string) bool {
+		_, err := base64.StdEncoding.DecodeString(value)
+		return err == nil
+	},
+}
+
+// validateFormat checks a string instance against the format of the schema. Unknown formats always pass.
+func (v *schemaValidator) validateFormat(s *Schema, instance string, path string) {
+	if s.Format == "" {
+		return
+	}
+	if check, ok := stringFormats[s.Format]; ok && !check(instance) {
+		v.fail(path, "format", "value '%s' is not a valid '%s'", instance, s.Format)
+	}
+}
+
+// UsedFormats returns every distinct 'format' used by the schema, or anywhere in the tree below it (following
+// references), sorted. Useful to work out which format validators need to be registered before using a schema.
+// Each schema is only visited once, so circular references are fine.
+func (s *Schema) UsedFormats() []string {
+	var formats []string
+	seen := make(map[any]bool)
+	var walk func(sch *Schema)
+	walk = func(sch *Schema) {
+		if sch == nil || seen[schemaKey(sch)] {
+			return
+		}
+		seen[schemaKey(sch)] = true
+		if sch.Format != "" && !slices.Contains(formats, sch.Format) {
+			formats = append(formats, sch.Format)
+		}
+		for _, sp := range sch.childProxies() {
+			if _, isBool := sp.IsBooleanSchema(); !isBool {
+				walk(sp.Schema())
+			}
+		}
+	}
+	walk(s)
+	slices.Sort(formats)
+	return formats
+}
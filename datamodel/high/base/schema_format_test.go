@@ -0,0 +1,45 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema_UsedFormats(t *testing.T) {
+	yml := `openapi: 3.1.0
+components:
+  schemas:
+    Person:
+      type: object
+      properties:
+        id:
+          type: string
+          format: uuid
+        email:
+          type: string
+          format: email
+        friends:
+          type: array
+          items:
+            $ref: '#/components/schemas/Person'
+        history:
+          type: array
+          items:
+            type: object
+            properties:
+              at:
+                type: string
+                format: date-time
+              by:
+                type: string
+                format: uuid
+    Plain:
+      type: string`
+
+	assert.Equal(t, []string{"date-time", "email", "uuid"}, buildComponentSchema(t, yml, "Person").UsedFormats())
+	assert.Empty(t, buildComponentSchema(t, yml, "Plain").UsedFormats())
+}
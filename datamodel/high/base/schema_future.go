@@ -0,0 +1,48 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pb33f/libopenapi/datamodel/low/base"
+)
+
+// SchemaFuture represents a Schema that is being built in the background by NewSchemaAsync. Call Get to
+// wait for the build to complete and collect the result.
+type SchemaFuture struct {
+	done   chan struct{}
+	schema *Schema
+	err    error
+}
+
+// NewSchemaAsync will start building a new high-level Schema from a low-level one in its own goroutine and
+// returns straight away. This allows many schemas to be kicked off at once, and then gathered later on using Get.
+// A panic during the build, including in any goroutine the build starts, is returned by Get as an error.
+func NewSchemaAsync(schema *base.Schema) *SchemaFuture {
+	f := &SchemaFuture{done: make(chan struct{})}
+	go func() {
+		defer close(f.done)
+		defer func() {
+			if r := recover(); r != nil {
+				f.schema = nil
+				f.err = fmt.Errorf("schema build failed: %v", r)
+			}
+		}()
+		if schema == nil {
+			f.err = errors.New("schema build failed: low-level schema is nil")
+			return
+		}
+		f.schema = NewSchema(schema)
+	}()
+	return f
+}
+
+// Get will block until the schema has been built, and then return it along with any error that occurred.
+// Get can be called any number of times, the same result is returned each time.
+func (f *SchemaFuture) Get() (*Schema, error) {
+	<-f.done
+	return f.schema, f.err
+}
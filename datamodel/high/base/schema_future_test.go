@@ -0,0 +1,87 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pb33f/libopenapi/datamodel/low"
+	lowbase "github.com/pb33f/libopenapi/datamodel/low/base"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestNewSchemaAsync(t *testing.T) {
+	var futures []*SchemaFuture
+	for i := 0; i < 20; i++ {
+		yml := fmt.Sprintf(`type: object
+description: schema %d
+properties:
+  id:
+    type: integer`, i)
+
+		var node yaml.Node
+		_ = yaml.Unmarshal([]byte(yml), &node)
+		var lowSchema lowbase.Schema
+		_ = low.BuildModel(node.Content[0], &lowSchema)
+		_ = lowSchema.Build(context.Background(), node.Content[0], nil)
+		futures = append(futures, NewSchemaAsync(&lowSchema))
+	}
+
+	for i, f := range futures {
+		sch, err := f.Get()
+		assert.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("schema %d", i), sch.Description)
+		assert.Equal(t, 1, sch.Properties.Len())
+
+		// a second call returns the same result
+		again, _ := f.Get()
+		assert.Same(t, sch, again)
+	}
+}
+
+func TestNewSchemaAsync_Nil(t *testing.T) {
+	sch, err := NewSchemaAsync(nil).Get()
+	assert.Nil(t, sch)
+	assert.Error(t, err)
+}
+
+// panicTracer panics when a span with the name given is started.
+type panicTracer struct {
+	name string
+}
+
+func (p panicTracer) Start(ctx context.Context, name string) (context.Context, SchemaSpan) {
+	if name == p.name {
+		panic("tracer failed")
+	}
+	return ctx, noopSpan{}
+}
+
+func TestNewSchema_PanicInGoroutine(t *testing.T) {
+	yml := `oneOf:
+  - type: string
+  - type: integer
+  - type: boolean
+  - type: number`
+
+	var node yaml.Node
+	_ = yaml.Unmarshal([]byte(yml), &node)
+	var lowSchema lowbase.Schema
+	_ = low.BuildModel(node.Content[0], &lowSchema)
+	_ = lowSchema.Build(context.Background(), node.Content[0], nil)
+
+	// the oneOf members are built on their own goroutine, the panic is raised again on the caller, where it can
+	// be recovered, rather than crashing the process.
+	ctx := ContextWithSchemaTracer(context.Background(), panicTracer{name: "schema.build.oneOf"})
+	assert.PanicsWithValue(t, "tracer failed", func() {
+		NewSchemaWithContext(ctx, &lowSchema)
+	})
+
+	sch, err := NewSchemaAsync(&lowSchema).Get()
+	assert.NoError(t, err)
+	assert.Len(t, sch.OneOf, 4)
+}
@@ -0,0 +1,155 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// DanglingRequired returns the names of any required properties that are not defined by the schema. Properties
+// contributed by allOf members (including references) are considered defined, so they are not returned.
+//
+// Listing a property as required without defining it is a common authoring mistake.
+func (s *Schema) DanglingRequired() []string {
+	defined := s.effectiveProperties()
+	var dangling []string
+	for _, r := range s.Required {
+		if defined.GetOrZero(r) == nil {
+			dangling = append(dangling, r)
+		}
+	}
+	return dangling
+}
+
+// Contradictions returns a description of every pair of constraints in the schema that cannot both be satisfied,
+// which means no value is valid against the schema. For example 'minimum: 10' with 'maximum: 5'. Each of these
+// is checked:
+//
+//   - minimum / exclusiveMinimum greater than maximum / exclusiveMaximum
+//   - minLength greater than maxLength
+//   - minItems greater than maxItems
+//   - minProperties greater than maxProperties
+//   - minContains greater than maxContains
+//   - an empty enum
+//   - a const value that is not one of the enum values
+//   - an enum value that is not one of the types, like 'type: string' with 'enum: [1, 2]'
+//
+// Only this schema is checked, child schemas are not.
+func (s *Schema) Contradictions() []string {
+	var found []string
+	add := func(message string, args ...any) {
+		found = append(found, fmt.Sprintf(message, args...))
+	}
+
+	if max, maxExclusive, ok := s.upperBound(); ok {
+		if min, minExclusive, ok := s.lowerBound(); ok {
+			if min > max || (min == max && (minExclusive || maxExclusive)) {
+				add("%s (%v) is greater than %s (%v), no number can match",
+					boundKeyword("minimum", minExclusive), min, boundKeyword("maximum", maxExclusive), max)
+			}
+		}
+	}
+
+	sizes := []struct {
+		min, max         *int64
+		minName, maxName string
+	}{
+		{s.MinLength, s.MaxLength, "minLength", "maxLength"},
+		{s.MinItems, s.MaxItems, "minItems", "maxItems"},
+		{s.MinProperties, s.MaxProperties, "minProperties", "maxProperties"},
+		{s.MinContains, s.MaxContains, "minContains", "maxContains"},
+	}
+	for _, size := range sizes {
+		if size.min != nil && size.max != nil && *size.min > *size.max {
+			add("%s (%d) is greater than %s (%d)", size.minName, *size.min, size.maxName, *size.max)
+		}
+	}
+
+	if s.low != nil && !s.low.Enum.IsEmpty() && len(s.low.Enum.Value) == 0 {
+		add("enum is empty, no value can match")
+	}
+	if s.Const != nil && len(s.Enum) > 0 {
+		constValue := s.nodeValue(s.Const)
+		matched := false
+		for _, e := range s.Enum {
+			if valuesEqual(constValue, s.nodeValue(e)) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			add("const value '%s' is not one of the enum values, no value can match", s.describeValue(s.Const))
+		}
+	}
+	if len(s.Type) > 0 {
+		for _, e := range s.Enum {
+			value := s.nodeValue(e)
+			if value == nil && s.IsNullable() {
+				continue
+			}
+			if !slices.ContainsFunc(s.Type, func(t string) bool { return instanceIsType(value, t) }) {
+				add("enum value '%s' is '%s', not type '%s', it can never match", s.describeValue(e),
+					instanceType(value), strings.Join(s.Type, "|"))
+			}
+		}
+	}
+	return found
+}
+
+// ValidateExamples checks the example, every value in examples and the default of the schema against the schema
+// itself, and returns an error for every failure found. Each error names the value that failed, like 'examples[1]'
+// or 'default', and wraps the *ValidationError, so errors.As can be used to get the path and keyword. A value that
+// cannot be decoded is reported too. Nothing is returned if every value conforms.
+//
+// Examples that do not match their own schema are a sign the documentation has drifted from the schema.
+func (s *Schema) ValidateExamples() []error {
+	if s == nil {
+		return nil
+	}
+	var errs []error
+	check := func(name string, n *yaml.Node) {
+		if n == nil {
+			return
+		}
+		value, err := s.decodeValue(n)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s cannot be decoded: %w", name, err))
+			return
+		}
+		for _, ve := range s.Validate(value) {
+			errs = append(errs, fmt.Errorf("%s does not match the schema: %w", name, ve))
+		}
+	}
+	check("example", s.Example)
+	for i, e := range s.Examples {
+		check(fmt.Sprintf("examples[%d]", i), e)
+	}
+	check("default", s.Default)
+	return errs
+}
+
+// describeValue returns a short description of a value for a message, scalars are used as they are, objects and
+// arrays are rendered as compact JSON.
+func (s *Schema) describeValue(n *yaml.Node) string {
+	if n.Kind == yaml.ScalarNode {
+		return n.Value
+	}
+	if b, err := json.Marshal(s.nodeValue(n)); err == nil {
+		return string(b)
+	}
+	return n.Value
+}
+
+// boundKeyword returns the keyword used for a bound, depending on if it's exclusive or not.
+func boundKeyword(keyword string, exclusive bool) string {
+	if exclusive {
+		return "exclusive" + strings.ToUpper(keyword[:1]) + keyword[1:]
+	}
+	return keyword
+}
@@ -0,0 +1,110 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema_DanglingRequired(t *testing.T) {
+	sch := getHighSchema(t, `type: object
+required: [name, email]
+properties:
+  name:
+    type: string
+allOf:
+  - type: object
+    allOf:
+      - properties:
+          email:
+            type: string`)
+
+	assert.Empty(t, sch.DanglingRequired())
+
+	sch = getHighSchema(t, `type: object
+required: [name, email, phone]
+properties:
+  name:
+    type: string
+allOf:
+  - properties:
+      phone:
+        type: string`)
+
+	assert.Equal(t, []string{"email"}, sch.DanglingRequired())
+}
+
+func TestSchema_Contradictions(t *testing.T) {
+	tests := []struct {
+		yml      string
+		expected string
+	}{
+		{"minimum: 10\nmaximum: 5", "minimum (10) is greater than maximum (5), no number can match"},
+		{"exclusiveMinimum: 5\nmaximum: 5", "exclusiveMinimum (5) is greater than maximum (5), no number can match"},
+		{"minLength: 5\nmaxLength: 2", "minLength (5) is greater than maxLength (2)"},
+		{"minItems: 3\nmaxItems: 1", "minItems (3) is greater than maxItems (1)"},
+		{"minProperties: 4\nmaxProperties: 2", "minProperties (4) is greater than maxProperties (2)"},
+		{"minContains: 2\nmaxContains: 1", "minContains (2) is greater than maxContains (1)"},
+		{"enum: []", "enum is empty, no value can match"},
+		{"enum: [a, b]\nconst: c", "const value 'c' is not one of the enum values, no value can match"},
+		{"enum: [{a: 1}]\nconst: {a: 2}", "const value '{\"a\":2}' is not one of the enum values, no value can match"},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, []string{tc.expected}, getHighSchema(t, tc.yml).Contradictions(), tc.yml)
+	}
+
+	assert.Empty(t, getHighSchema(t, `minimum: 5
+maximum: 5
+minLength: 2
+maxLength: 2
+enum: [1, 2]
+const: 2.0`).Contradictions())
+	assert.Len(t, getHighSchema(t, "minimum: 10\nmaximum: 5\nminItems: 3\nmaxItems: 1").Contradictions(), 2)
+}
+
+func TestSchema_Contradictions_EnumType(t *testing.T) {
+	assert.Equal(t, []string{
+		"enum value '1' is 'integer', not type 'string', it can never match",
+		"enum value '2' is 'integer', not type 'string', it can never match",
+	}, getHighSchema(t, "type: string\nenum: [1, '1', 2]").Contradictions())
+
+	assert.Equal(t, []string{"enum value '1.5' is 'number', not type 'integer', it can never match"},
+		getHighSchema(t, "type: integer\nenum: [1, 1.5]").Contradictions())
+
+	assert.Empty(t, getHighSchema(t, "type: number\nenum: [1, 1.5]").Contradictions())
+	assert.Empty(t, getHighSchema(t, "type: [string, integer]\nenum: [a, 1]").Contradictions())
+	assert.Empty(t, getHighSchema(t, "type: string\nnullable: true\nenum: [a, null]").Contradictions())
+	assert.Empty(t, getHighSchema(t, "enum: [a, 1]").Contradictions())
+}
+
+func TestSchema_ValidateExamples(t *testing.T) {
+	sch := getHighSchema(t, `type: integer
+maximum: 10
+example: 12
+examples: [1, 5, 100]
+default: 3`)
+
+	errs := sch.ValidateExamples()
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "example does not match the schema: /: value 12 must be less than or equal to 10", errs[0].Error())
+	assert.Equal(t, "examples[2] does not match the schema: /: value 100 must be less than or equal to 10",
+		errs[1].Error())
+
+	var ve *ValidationError
+	assert.True(t, errors.As(errs[0], &ve))
+	assert.Equal(t, "maximum", ve.Keyword)
+
+	assert.Empty(t, getHighSchema(t, `type: object
+required: [name]
+properties:
+  name:
+    type: string
+example:
+  name: pizza
+default:
+  name: pie`).ValidateExamples())
+}
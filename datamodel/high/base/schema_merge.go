@@ -0,0 +1,561 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"fmt"
+
+	"github.com/pb33f/libopenapi/orderedmap"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// MergeStrategy decides what MergeAllOf does when two allOf members set the same constraint to different values.
+type MergeStrategy int
+
+const (
+	// MergeStrictest keeps the tighter of two constraints; the smaller maximum, the larger minimum, the types and enum
+	// values both members allow etc. Null is only allowed if every member with a type allows it. Constraints that
+	// cannot be tightened (two different patterns, formats or const values) are an error.
+	MergeStrictest MergeStrategy = iota
+
+	// MergeFirst keeps the value of the first member that sets a constraint, in the order the members are declared.
+	MergeFirst
+
+	// MergeError returns an error when two members set a constraint to different values.
+	MergeError
+)
+
+// MergeAllOf returns a new schema, with the schema and every one of its allOf members (recursively, following
+// references) merged into one. This is what most code generators need, allOf is how inheritance is modeled, but
+// a generated type has a single set of properties. The original schema is not modified.
+//
+// Annotations (title, description, examples, externalDocs etc.) are taken from the first member that has them.
+// Required properties are combined, and properties defined by more than one member are merged recursively. Anything
+// that cannot be merged into a single schema (a oneOf in two members for example) is kept in the allOf of the result.
+// Conflicting constraints are resolved using the strategy.
+//
+// The result is built by hand, it's not backed by a low-level model.
+func (s *Schema) MergeAllOf(strategy MergeStrategy) (*Schema, error) {
+	if s == nil {
+		return nil, nil
+	}
+	m := &allOfMerger{strategy: strategy}
+	return m.flatten(s, nil)
+}
+
+// allOfMerger holds the state of a single MergeAllOf run. The active slice of each call holds the pairs of property
+// schemas already being merged, so circular schemas are only merged once.
+type allOfMerger struct {
+	strategy MergeStrategy
+}
+
+// flatten merges a schema, and all of its allOf members into a new schema.
+func (m *allOfMerger) flatten(s *Schema, active [][2]any) (*Schema, error) {
+	var members []*Schema
+	s.walkAllOf(func(sch *Schema) bool {
+		members = append(members, sch)
+		return true
+	})
+	merged := &Schema{opts: s.opts}
+	for _, member := range members {
+		if err := m.merge(merged, member, active); err != nil {
+			return nil, err
+		}
+	}
+	if m.strategy == MergeStrictest && merged.Nullable != nil && *merged.Nullable {
+		// null is only allowed if every member allows it, a member with a type that does not say it's nullable
+		// does not.
+		for _, member := range members {
+			if len(member.Type) > 0 && !slices.Contains(member.Type, "null") &&
+				(member.Nullable == nil || !*member.Nullable) {
+				nullable := false
+				merged.Nullable = &nullable
+				break
+			}
+		}
+	}
+	return merged, nil
+}
+
+func (m *allOfMerger) conflict(keyword string, a, b any) error {
+	return fmt.Errorf("allOf members have conflicting '%s' values (%v and %v)", keyword, a, b)
+}
+
+// merge merges a single member into the destination schema.
+func (m *allOfMerger) merge(dst, src *Schema, active [][2]any) error {
+	// annotations, the first wins.
+	firstString(&dst.SchemaTypeRef, src.SchemaTypeRef)
+	firstString(&dst.Anchor, src.Anchor)
+	firstString(&dst.Title, src.Title)
+	firstString(&dst.Description, src.Description)
+	firstPointer(&dst.Default, src.Default)
+	firstPointer(&dst.Example, src.Example)
+	firstPointer(&dst.XML, src.XML)
+	firstPointer(&dst.ExternalDocs, src.ExternalDocs)
+	firstPointer(&dst.Discriminator, src.Discriminator)
+	if len(dst.Examples) == 0 {
+		dst.Examples = slices.Clone(src.Examples)
+	}
+	for pair := orderedmap.First(src.Extensions); pair != nil; pair = pair.Next() {
+		if dst.Extensions == nil {
+			dst.Extensions = orderedmap.New[string, *yaml.Node]()
+		}
+		if _, ok := dst.Extensions.Get(pair.Key()); !ok {
+			dst.Extensions.Set(pair.Key(), pair.Value())
+		}
+	}
+	anyTrue(&dst.UniqueItems, src.UniqueItems)
+	anyTrue(&dst.ReadOnly, src.ReadOnly)
+	anyTrue(&dst.WriteOnly, src.WriteOnly)
+	anyTrue(&dst.Deprecated, src.Deprecated)
+	for _, r := range src.Required {
+		if !slices.Contains(dst.Required, r) {
+			dst.Required = append(dst.Required, r)
+		}
+	}
+
+	// constraints
+	for _, err := range []error{
+		m.mergeTypes(dst, src),
+		m.mergeValues(dst, src),
+		m.mergeUpperBound(dst, src),
+		m.mergeLowerBound(dst, src),
+		m.mergeMultipleOf(dst, src),
+		m.mergeString("pattern", &dst.Pattern, src.Pattern),
+		m.mergeString("format", &dst.Format, src.Format),
+		mergeBound(m, "maxLength", &dst.MaxLength, src.MaxLength, true),
+		mergeBound(m, "minLength", &dst.MinLength, src.MinLength, false),
+		mergeBound(m, "maxItems", &dst.MaxItems, src.MaxItems, true),
+		mergeBound(m, "minItems", &dst.MinItems, src.MinItems, false),
+		mergeBound(m, "maxProperties", &dst.MaxProperties, src.MaxProperties, true),
+		mergeBound(m, "minProperties", &dst.MinProperties, src.MinProperties, false),
+		mergeBound(m, "maxContains", &dst.MaxContains, src.MaxContains, true),
+		mergeBound(m, "minContains", &dst.MinContains, src.MinContains, false),
+		m.mergeNullable(dst, src),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+
+	// child schemas
+	var err error
+	if dst.Properties, err = m.mergeProxyMap(dst.Properties, src.Properties, active); err != nil {
+		return err
+	}
+	if dst.PatternProperties, err = m.mergeProxyMap(dst.PatternProperties, src.PatternProperties, active); err != nil {
+		return err
+	}
+	if dst.DependentSchemas, err = m.mergeProxyMap(dst.DependentSchemas, src.DependentSchemas, active); err != nil {
+		return err
+	}
+	if dst.Items, err = m.mergeDynamic("items", dst.Items, src.Items, active); err != nil {
+		return err
+	}
+	if dst.AdditionalProperties, err = m.mergeDynamic("additionalProperties", dst.AdditionalProperties,
+		src.AdditionalProperties, active); err != nil {
+		return err
+	}
+	if dst.UnevaluatedProperties, err = m.mergeDynamic("unevaluatedProperties", dst.UnevaluatedProperties,
+		src.UnevaluatedProperties, active); err != nil {
+		return err
+	}
+
+	// anything that cannot be merged is moved to the allOf of the result, if the destination already has it.
+	leftover := &Schema{}
+	keep := false
+	keepSlice := func(dst, left *[]*SchemaProxy, src []*SchemaProxy) {
+		if len(src) == 0 {
+			return
+		}
+		if len(*dst) == 0 {
+			*dst = slices.Clone(src)
+			return
+		}
+		*left = src
+		keep = true
+	}
+	keepProxy := func(dst, left **SchemaProxy, src *SchemaProxy) {
+		if src == nil {
+			return
+		}
+		if *dst == nil {
+			*dst = src
+			return
+		}
+		*left = src
+		keep = true
+	}
+	keepSlice(&dst.OneOf, &leftover.OneOf, src.OneOf)
+	keepSlice(&dst.AnyOf, &leftover.AnyOf, src.AnyOf)
+	keepSlice(&dst.PrefixItems, &leftover.PrefixItems, src.PrefixItems)
+	keepProxy(&dst.Not, &leftover.Not, src.Not)
+	keepProxy(&dst.Contains, &leftover.Contains, src.Contains)
+	keepProxy(&dst.PropertyNames, &leftover.PropertyNames, src.PropertyNames)
+	keepProxy(&dst.UnevaluatedItems, &leftover.UnevaluatedItems, src.UnevaluatedItems)
+	if src.If != nil || src.Then != nil || src.Else != nil {
+		if dst.If == nil && dst.Then == nil && dst.Else == nil {
+			dst.If, dst.Then, dst.Else = src.If, src.Then, src.Else
+		} else {
+			leftover.If, leftover.Then, leftover.Else = src.If, src.Then, src.Else
+			keep = true
+		}
+	}
+	if keep {
+		dst.AllOf = append(dst.AllOf, CreateSchemaProxy(leftover))
+	}
+	return nil
+}
+
+func (m *allOfMerger) mergeTypes(dst, src *Schema) error {
+	if len(src.Type) == 0 {
+		return nil
+	}
+	if len(dst.Type) == 0 {
+		dst.Type = slices.Clone(src.Type)
+		return nil
+	}
+	var common []string
+	for _, t := range dst.Type {
+		switch {
+		case slices.Contains(src.Type, t):
+		case t == "number" && slices.Contains(src.Type, "integer"), t == "integer" && slices.Contains(src.Type, "number"):
+			t = "integer"
+		default:
+			continue
+		}
+		// number and integer can both become integer, it's only kept once.
+		if !slices.Contains(common, t) {
+			common = append(common, t)
+		}
+	}
+	if len(common) == len(dst.Type) && len(common) == len(src.Type) && slices.Equal(common, dst.Type) {
+		return nil
+	}
+	switch m.strategy {
+	case MergeFirst:
+		return nil
+	case MergeError:
+		return m.conflict("type", dst.Type, src.Type)
+	}
+	if len(common) == 0 {
+		return fmt.Errorf("allOf members have no type in common (%v and %v)", dst.Type, src.Type)
+	}
+	dst.Type = common
+	return nil
+}
+
+// mergeValues merges enum and const.
+func (m *allOfMerger) mergeValues(dst, src *Schema) error {
+	if src.Const != nil {
+		switch {
+		case dst.Const == nil:
+			dst.Const = src.Const
+		case !nodesEqual(dst.Const, src.Const, make(map[[2]*yaml.Node]bool)) && m.strategy != MergeFirst:
+			return m.conflict("const", dst.Const.Value, src.Const.Value)
+		}
+	}
+	if len(src.Enum) == 0 {
+		return nil
+	}
+	if len(dst.Enum) == 0 {
+		dst.Enum = slices.Clone(src.Enum)
+		return nil
+	}
+	var common []*yaml.Node
+	for _, d := range dst.Enum {
+		for _, e := range src.Enum {
+			if valuesEqual(dst.nodeValue(d), src.nodeValue(e)) {
+				common = append(common, d)
+				break
+			}
+		}
+	}
+	if len(common) == len(dst.Enum) && len(common) == len(src.Enum) {
+		return nil
+	}
+	switch m.strategy {
+	case MergeFirst:
+		return nil
+	case MergeError:
+		return m.conflict("enum", len(dst.Enum), len(src.Enum))
+	}
+	if len(common) == 0 {
+		return fmt.Errorf("allOf members have no enum values in common")
+	}
+	dst.Enum = common
+	return nil
+}
+
+// mergeUpperBound merges maximum and exclusiveMaximum, in both the 3.0 and 3.1 forms.
+func (m *allOfMerger) mergeUpperBound(dst, src *Schema) error {
+	sv, sex, ok := src.upperBound()
+	if !ok {
+		return nil
+	}
+	if dv, dex, dok := dst.upperBound(); dok && (dv != sv || dex != sex) {
+		switch m.strategy {
+		case MergeFirst:
+			return nil
+		case MergeError:
+			return m.conflict("maximum", dv, sv)
+		}
+		_, max, _, maxExcl := IntersectRanges(dst, src)
+		sv, sex = *max, maxExcl
+	}
+	numeric := (src.ExclusiveMaximum != nil && src.ExclusiveMaximum.IsB()) ||
+		(dst.ExclusiveMaximum != nil && dst.ExclusiveMaximum.IsB())
+	dst.Maximum, dst.ExclusiveMaximum = exclusiveBound(sv, sex, numeric)
+	return nil
+}
+
+// mergeLowerBound merges minimum and exclusiveMinimum, in both the 3.0 and 3.1 forms.
+func (m *allOfMerger) mergeLowerBound(dst, src *Schema) error {
+	sv, sex, ok := src.lowerBound()
+	if !ok {
+		return nil
+	}
+	if dv, dex, dok := dst.lowerBound(); dok && (dv != sv || dex != sex) {
+		switch m.strategy {
+		case MergeFirst:
+			return nil
+		case MergeError:
+			return m.conflict("minimum", dv, sv)
+		}
+		min, _, minExcl, _ := IntersectRanges(dst, src)
+		sv, sex = *min, minExcl
+	}
+	numeric := (src.ExclusiveMinimum != nil && src.ExclusiveMinimum.IsB()) ||
+		(dst.ExclusiveMinimum != nil && dst.ExclusiveMinimum.IsB())
+	dst.Minimum, dst.ExclusiveMinimum = exclusiveBound(sv, sex, numeric)
+	return nil
+}
+
+// exclusiveBound returns the bound and exclusive values of a schema for a bound. The 3.1 numeric form of the
+// exclusive keyword is used if numeric is true, otherwise the 3.0 boolean form is used.
+func exclusiveBound(value float64, exclusive, numeric bool) (*float64, *DynamicValue[bool, float64]) {
+	switch {
+	case !exclusive:
+		return &value, nil
+	case numeric:
+		return nil, &DynamicValue[bool, float64]{N: 1, B: value}
+	default:
+		return &value, &DynamicValue[bool, float64]{A: true}
+	}
+}
+
+func (m *allOfMerger) mergeMultipleOf(dst, src *Schema) error {
+	if src.MultipleOf == nil {
+		return nil
+	}
+	if dst.MultipleOf == nil || *dst.MultipleOf == *src.MultipleOf {
+		dst.MultipleOf = src.MultipleOf
+		return nil
+	}
+	switch m.strategy {
+	case MergeFirst:
+		return nil
+	case MergeError:
+		return m.conflict("multipleOf", *dst.MultipleOf, *src.MultipleOf)
+	}
+	// the stricter multiple is the one that is also a multiple of the other.
+	switch {
+	case isMultipleOf(*dst.MultipleOf, *src.MultipleOf):
+		return nil
+	case isMultipleOf(*src.MultipleOf, *dst.MultipleOf):
+		dst.MultipleOf = src.MultipleOf
+		return nil
+	}
+	return m.conflict("multipleOf", *dst.MultipleOf, *src.MultipleOf)
+}
+
+// mergeString merges a string constraint that cannot be tightened, like a pattern or a format.
+func (m *allOfMerger) mergeString(keyword string, dst *string, src string) error {
+	if src == "" || *dst == src {
+		return nil
+	}
+	if *dst == "" {
+		*dst = src
+		return nil
+	}
+	if m.strategy == MergeFirst {
+		return nil
+	}
+	return m.conflict(keyword, *dst, src)
+}
+
+func (m *allOfMerger) mergeNullable(dst, src *Schema) error {
+	if src.Nullable == nil {
+		return nil
+	}
+	if dst.Nullable == nil || *dst.Nullable == *src.Nullable {
+		dst.Nullable = src.Nullable
+		return nil
+	}
+	switch m.strategy {
+	case MergeFirst:
+		return nil
+	case MergeError:
+		return m.conflict("nullable", *dst.Nullable, *src.Nullable)
+	}
+	// null is only allowed if every member allows it.
+	nullable := false
+	dst.Nullable = &nullable
+	return nil
+}
+
+// mergeBound merges an integer bound, upper is true for a maximum (where smaller is stricter) and false for a
+// minimum (where larger is stricter).
+func mergeBound(m *allOfMerger, keyword string, dst **int64, src *int64, upper bool) error {
+	if src == nil {
+		return nil
+	}
+	if *dst == nil || **dst == *src {
+		*dst = src
+		return nil
+	}
+	switch m.strategy {
+	case MergeFirst:
+		return nil
+	case MergeError:
+		return m.conflict(keyword, **dst, *src)
+	}
+	if (upper && *src < **dst) || (!upper && *src > **dst) {
+		*dst = src
+	}
+	return nil
+}
+
+// mergeProxyMap merges the schemas of two maps by key, schemas found in both are merged recursively.
+func (m *allOfMerger) mergeProxyMap(dst, src *orderedmap.Map[string, *SchemaProxy],
+	active [][2]any,
+) (*orderedmap.Map[string, *SchemaProxy], error) {
+	if orderedmap.Len(src) == 0 {
+		return dst, nil
+	}
+	merged := mergeMaps(dst, nil)
+	if merged == nil {
+		merged = orderedmap.New[string, *SchemaProxy]()
+	}
+	for pair := orderedmap.First(src); pair != nil; pair = pair.Next() {
+		existing := merged.GetOrZero(pair.Key())
+		if existing == nil {
+			merged.Set(pair.Key(), pair.Value())
+			continue
+		}
+		sp, err := m.mergeProxy(pair.Key(), existing, pair.Value(), active)
+		if err != nil {
+			return nil, err
+		}
+		merged.Set(pair.Key(), sp)
+	}
+	return merged, nil
+}
+
+// mergeProxy merges two child schemas into one.
+func (m *allOfMerger) mergeProxy(keyword string, a, b *SchemaProxy, active [][2]any) (*SchemaProxy, error) {
+	ab, aIsBool := a.IsBooleanSchema()
+	bb, bIsBool := b.IsBooleanSchema()
+	switch {
+	case aIsBool && bIsBool:
+		if ab == bb || m.strategy == MergeFirst {
+			return a, nil
+		}
+		if m.strategy == MergeError {
+			return nil, m.conflict(keyword, ab, bb)
+		}
+		if !ab {
+			return a, nil
+		}
+		return b, nil
+	case aIsBool:
+		if ab {
+			return b, nil
+		}
+		return a, nil
+	case bIsBool:
+		if bb {
+			return a, nil
+		}
+		return b, nil
+	}
+
+	sa, sb := a.Schema(), b.Schema()
+	if sa == nil || sb == nil {
+		if sa == nil {
+			return b, nil
+		}
+		return a, nil
+	}
+	pair := [2]any{schemaKey(sa), schemaKey(sb)}
+	if pair[0] == pair[1] {
+		return a, nil
+	}
+	if slices.Contains(active, pair) {
+		// circular, leave the pair as an allOf.
+		return CreateSchemaProxy(&Schema{AllOf: []*SchemaProxy{a, b}}), nil
+	}
+	merged, err := m.flatten(&Schema{AllOf: []*SchemaProxy{a, b}, opts: sa.opts}, append(active, pair))
+	if err != nil {
+		return nil, err
+	}
+	return CreateSchemaProxy(merged), nil
+}
+
+// mergeDynamic merges items, additionalProperties or unevaluatedProperties. A schema and a boolean are reduced to
+// the stricter of the two, regardless of the strategy, 'true' allows anything, 'false' allows nothing.
+func (m *allOfMerger) mergeDynamic(keyword string, dst, src *DynamicValue[*SchemaProxy, bool],
+	active [][2]any,
+) (*DynamicValue[*SchemaProxy, bool], error) {
+	if src == nil {
+		return dst, nil
+	}
+	if dst == nil {
+		return src, nil
+	}
+	switch {
+	case dst.IsB() && src.IsB():
+		if dst.B == src.B || m.strategy == MergeFirst {
+			return dst, nil
+		}
+		if m.strategy == MergeError {
+			return nil, m.conflict(keyword, dst.B, src.B)
+		}
+		return &DynamicValue[*SchemaProxy, bool]{N: 1, B: false}, nil
+	case dst.IsB():
+		if dst.B {
+			return src, nil
+		}
+		return dst, nil
+	case src.IsB():
+		if src.B {
+			return dst, nil
+		}
+		return src, nil
+	}
+	sp, err := m.mergeProxy(keyword, dst.A, src.A, active)
+	if err != nil {
+		return nil, err
+	}
+	return &DynamicValue[*SchemaProxy, bool]{A: sp}, nil
+}
+
+func firstString(dst *string, src string) {
+	if *dst == "" {
+		*dst = src
+	}
+}
+
+func firstPointer[T any](dst **T, src *T) {
+	if *dst == nil {
+		*dst = src
+	}
+}
+
+func anyTrue(dst **bool, src *bool) {
+	if src != nil && (*dst == nil || (*src && !**dst)) {
+		*dst = src
+	}
+}
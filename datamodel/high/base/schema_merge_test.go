@@ -0,0 +1,187 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema_MergeAllOf(t *testing.T) {
+	sch := getHighSchema(t, `title: Dog
+type: object
+required: [name]
+properties:
+  name:
+    type: string
+    maxLength: 50
+allOf:
+  - type: object
+    required: [age]
+    properties:
+      name:
+        type: string
+        minLength: 1
+      age:
+        type: integer
+  - allOf:
+      - properties:
+          bark:
+            type: boolean`)
+
+	merged, err := sch.MergeAllOf(MergeStrictest)
+	assert.NoError(t, err)
+	assert.Nil(t, merged.AllOf)
+	assert.Equal(t, "Dog", merged.Title)
+	assert.Equal(t, []string{"object"}, merged.Type)
+	assert.Equal(t, []string{"name", "age"}, merged.Required)
+	assert.Equal(t, []string{"name", "age", "bark"}, []string{
+		merged.Properties.First().Key(),
+		merged.Properties.First().Next().Key(),
+		merged.Properties.First().Next().Next().Key(),
+	})
+
+	// the name property from both members is merged.
+	name := merged.Properties.GetOrZero("name").Schema()
+	assert.Equal(t, int64(50), *name.MaxLength)
+	assert.Equal(t, int64(1), *name.MinLength)
+
+	// the original is untouched.
+	assert.Len(t, sch.AllOf, 2)
+	assert.Equal(t, []string{"name"}, sch.Required)
+}
+
+func TestSchema_MergeAllOf_ConflictingMaximum(t *testing.T) {
+	sch := getHighSchema(t, `allOf:
+  - type: number
+    maximum: 10
+    minimum: 1
+  - type: integer
+    maximum: 5
+    minimum: 3`)
+
+	strictest, err := sch.MergeAllOf(MergeStrictest)
+	assert.NoError(t, err)
+	assert.Equal(t, 5.0, *strictest.Maximum)
+	assert.Equal(t, 3.0, *strictest.Minimum)
+	assert.Equal(t, []string{"integer"}, strictest.Type)
+
+	first, err := sch.MergeAllOf(MergeFirst)
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, *first.Maximum)
+	assert.Equal(t, 1.0, *first.Minimum)
+	assert.Equal(t, []string{"number"}, first.Type)
+
+	_, err = sch.MergeAllOf(MergeError)
+	assert.EqualError(t, err, "allOf members have conflicting 'type' values ([number] and [integer])")
+
+	_, err = getHighSchema(t, `allOf:
+  - maximum: 10
+  - maximum: 5`).MergeAllOf(MergeError)
+	assert.EqualError(t, err, "allOf members have conflicting 'maximum' values (10 and 5)")
+
+	// the same value is not a conflict.
+	same, err := getHighSchema(t, `allOf:
+  - maximum: 10
+  - maximum: 10`).MergeAllOf(MergeError)
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, *same.Maximum)
+}
+
+func TestSchema_MergeAllOf_ExclusiveBounds(t *testing.T) {
+	merged, err := getHighSchema(t, `allOf:
+  - maximum: 10
+  - exclusiveMaximum: 10
+  - exclusiveMinimum: 1
+  - minimum: 2`).MergeAllOf(MergeStrictest)
+	assert.NoError(t, err)
+	assert.Nil(t, merged.Maximum)
+	assert.True(t, merged.ExclusiveMaximum.IsB())
+	assert.Equal(t, 10.0, merged.ExclusiveMaximum.B)
+	assert.Equal(t, 2.0, *merged.Minimum)
+	assert.Nil(t, merged.ExclusiveMinimum)
+}
+
+func TestSchema_MergeAllOf_NumericTypes(t *testing.T) {
+	// number and integer on one side both narrow to the integer on the other, which is only kept once.
+	for _, yml := range []string{`allOf:
+  - type: [integer, number]
+  - type: integer`, `allOf:
+  - type: integer
+  - type: [integer, number]`} {
+		merged, err := getHighSchema(t, yml).MergeAllOf(MergeStrictest)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"integer"}, merged.Type)
+	}
+}
+
+func TestSchema_MergeAllOf_Nullable(t *testing.T) {
+	yml := `allOf:
+  - type: string
+    nullable: true
+  - type: string
+    maxLength: 10`
+	sch := getHighSchema(t, yml)
+
+	// strictest only allows null if every member does.
+	strictest, err := sch.MergeAllOf(MergeStrictest)
+	assert.NoError(t, err)
+	assert.False(t, *strictest.Nullable)
+
+	first, err := sch.MergeAllOf(MergeFirst)
+	assert.NoError(t, err)
+	assert.True(t, *first.Nullable)
+
+	// members without a type have no say.
+	merged, err := getHighSchema(t, `allOf:
+  - type: string
+    nullable: true
+  - maxLength: 10`).MergeAllOf(MergeStrictest)
+	assert.NoError(t, err)
+	assert.True(t, *merged.Nullable)
+
+	merged, err = getHighSchema(t, `allOf:
+  - type: string
+    nullable: true
+  - type: string
+    nullable: true`).MergeAllOf(MergeStrictest)
+	assert.NoError(t, err)
+	assert.True(t, *merged.Nullable)
+}
+
+func TestSchema_MergeAllOf_Unmergeable(t *testing.T) {
+	merged, err := getHighSchema(t, `allOf:
+  - pattern: "^a"
+    enum: [a, b, c]
+    oneOf:
+      - type: string
+  - pattern: "^b"
+    enum: [b, c, d]
+    oneOf:
+      - type: integer`).MergeAllOf(MergeFirst)
+	assert.NoError(t, err)
+	assert.Equal(t, "^a", merged.Pattern)
+	assert.Len(t, merged.Enum, 3)
+	assert.Len(t, merged.OneOf, 1)
+	assert.Len(t, merged.AllOf, 1)
+	assert.Len(t, merged.AllOf[0].Schema().OneOf, 1)
+
+	_, err = getHighSchema(t, `allOf:
+  - pattern: "^a"
+  - pattern: "^b"`).MergeAllOf(MergeStrictest)
+	assert.EqualError(t, err, "allOf members have conflicting 'pattern' values (^a and ^b)")
+
+	merged, err = getHighSchema(t, `allOf:
+  - enum: [a, b, c]
+  - enum: [b, c, d]`).MergeAllOf(MergeStrictest)
+	assert.NoError(t, err)
+	assert.Len(t, merged.Enum, 2)
+	assert.Equal(t, "b", merged.Enum[0].Value)
+
+	_, err = getHighSchema(t, `allOf:
+  - type: string
+  - type: boolean`).MergeAllOf(MergeStrictest)
+	assert.EqualError(t, err, "allOf members have no type in common ([string] and [boolean])")
+}
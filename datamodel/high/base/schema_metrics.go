@@ -0,0 +1,86 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// BuildMetrics records how schemas were built. Set SchemaBuildOptions.Metrics to collect them, every schema built
+// with those options (including children, which are built when first used) adds to the same metrics. Metrics are
+// safe to read while building, but they only describe the builds completed so far.
+//
+// Useful for deciding if the number of goroutines used to build a specification is a good fit. When no metrics
+// are set, nothing is recorded.
+type BuildMetrics struct {
+	active  atomic.Int64
+	peak    atomic.Int64
+	built   atomic.Int64
+	started atomic.Int64
+	ended   atomic.Int64
+}
+
+// PeakGoroutines returns the highest number of goroutines that were building schemas at the same time.
+func (m *BuildMetrics) PeakGoroutines() int64 {
+	return m.peak.Load()
+}
+
+// SchemasBuilt returns the total number of schemas built.
+func (m *BuildMetrics) SchemasBuilt() int64 {
+	return m.built.Load()
+}
+
+// WallTime returns the time between the first build starting, and the last build finishing.
+func (m *BuildMetrics) WallTime() time.Duration {
+	started, ended := m.started.Load(), m.ended.Load()
+	if started == 0 || ended < started {
+		return 0
+	}
+	return time.Duration(ended - started)
+}
+
+// enter records a goroutine starting to build, leave must be called when it's done. Both are no-ops on nil metrics.
+func (m *BuildMetrics) enter() {
+	if m == nil {
+		return
+	}
+	n := m.active.Add(1)
+	for {
+		peak := m.peak.Load()
+		if n <= peak || m.peak.CompareAndSwap(peak, n) {
+			return
+		}
+	}
+}
+
+func (m *BuildMetrics) leave() {
+	if m != nil {
+		m.active.Add(-1)
+	}
+}
+
+// record counts a built schema, that started building at the time given.
+func (m *BuildMetrics) record(start time.Time) {
+	if m == nil {
+		return
+	}
+	m.built.Add(1)
+	m.started.CompareAndSwap(0, start.UnixNano())
+	for s := m.started.Load(); start.UnixNano() < s && !m.started.CompareAndSwap(s, start.UnixNano()); {
+		s = m.started.Load()
+	}
+	end := time.Now().UnixNano()
+	for e := m.ended.Load(); end > e && !m.ended.CompareAndSwap(e, end); {
+		e = m.ended.Load()
+	}
+}
+
+// buildMetrics returns the metrics to record builds against, or nil if there are none.
+func (o *SchemaBuildOptions) buildMetrics() *BuildMetrics {
+	if o == nil {
+		return nil
+	}
+	return o.Metrics
+}
@@ -0,0 +1,121 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pb33f/libopenapi/datamodel/low"
+	lowbase "github.com/pb33f/libopenapi/datamodel/low/base"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSchema_BuildMetrics(t *testing.T) {
+	yml := `type: object
+properties:
+  name:
+    type: string
+  age:
+    type: integer
+  pet:
+    oneOf:
+      - type: string
+      - type: object
+        properties:
+          name:
+            type: string
+  tags:
+    type: array
+    prefixItems:
+      - type: string
+    items:
+      type: string`
+
+	// these schemas are small, so they are only built concurrently when forced to.
+	metrics := &BuildMetrics{}
+	sch := getHighSchemaWithOptions(t, yml, &SchemaBuildOptions{Metrics: metrics, SyncBuildThreshold: -1})
+	assert.Equal(t, int64(1), metrics.SchemasBuilt())
+
+	// children are built when used, and are recorded against the same metrics.
+	for pair := sch.Properties.First(); pair != nil; pair = pair.Next() {
+		assert.NotNil(t, pair.Value().Schema())
+	}
+	pet := sch.Properties.GetOrZero("pet").Schema()
+	for _, m := range pet.OneOf {
+		assert.NotNil(t, m.Schema())
+	}
+	assert.Equal(t, int64(7), metrics.SchemasBuilt())
+	assert.GreaterOrEqual(t, metrics.PeakGoroutines(), int64(1))
+	assert.Greater(t, metrics.WallTime(), time.Duration(0))
+	assert.Zero(t, metrics.active.Load())
+
+	// without metrics, nothing is recorded.
+	assert.Zero(t, (&BuildMetrics{}).WallTime())
+	assert.NotNil(t, getHighSchemaWithOptions(t, yml, &SchemaBuildOptions{}))
+}
+
+func TestSchema_SyncBuildThreshold(t *testing.T) {
+	yml := `oneOf:
+  - type: string
+  - type: integer
+prefixItems:
+  - type: boolean`
+
+	// three children is below the default threshold, so no goroutines are used.
+	metrics := &BuildMetrics{}
+	sch := getHighSchemaWithOptions(t, yml, &SchemaBuildOptions{Metrics: metrics})
+	assert.Zero(t, metrics.PeakGoroutines())
+	assert.Len(t, sch.OneOf, 2)
+	assert.Equal(t, []string{"integer"}, sch.OneOf[1].Schema().Type)
+	assert.Equal(t, []string{"boolean"}, sch.PrefixItems[0].Schema().Type)
+
+	// a lower threshold builds the same schema concurrently.
+	metrics = &BuildMetrics{}
+	sch = getHighSchemaWithOptions(t, yml, &SchemaBuildOptions{Metrics: metrics, SyncBuildThreshold: 3})
+	assert.GreaterOrEqual(t, metrics.PeakGoroutines(), int64(1))
+	assert.Equal(t, []string{"integer"}, sch.OneOf[1].Schema().Type)
+
+	assert.False(t, (*SchemaBuildOptions)(nil).buildAsync(3))
+	assert.True(t, (*SchemaBuildOptions)(nil).buildAsync(4))
+	assert.True(t, (&SchemaBuildOptions{SyncBuildThreshold: -1}).buildAsync(0))
+}
+
+func benchmarkSmallLowSchema(b *testing.B) *lowbase.Schema {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(`oneOf:
+  - type: string
+  - type: integer`), &node); err != nil {
+		b.Fatal(err)
+	}
+	var lowSchema lowbase.Schema
+	if err := low.BuildModel(node.Content[0], &lowSchema); err != nil {
+		b.Fatal(err)
+	}
+	if err := lowSchema.Build(context.Background(), node.Content[0], nil); err != nil {
+		b.Fatal(err)
+	}
+	return &lowSchema
+}
+
+func BenchmarkNewSchema_Small(b *testing.B) {
+	lowSchema := benchmarkSmallLowSchema(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		_ = NewSchema(lowSchema)
+	}
+}
+
+func BenchmarkNewSchema_SmallAsync(b *testing.B) {
+	lowSchema := benchmarkSmallLowSchema(b)
+	opts := &SchemaBuildOptions{SyncBuildThreshold: -1}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		_ = NewSchemaWithOptions(lowSchema, opts)
+	}
+}
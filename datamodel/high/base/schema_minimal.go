@@ -0,0 +1,213 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// minimalFormats holds the smallest value used for each string format that can be asserted, so a minimal instance
+// is still valid when formats are checked.
+var minimalFormats = map[string]string{
+	"email":         "a@example.com",
+	"date":          "2000-01-01",
+	"date-time":     "2000-01-01T00:00:00Z",
+	"time":          "00:00:00Z",
+	"uuid":          "00000000-0000-0000-0000-000000000000",
+	"ipv4":          "0.0.0.0",
+	"ipv6":          "::1",
+	"hostname":      "a",
+	"uri":           "http://a",
+	"uri-reference": "a",
+}
+
+// MinimalInstance returns the smallest value that is valid against the schema, useful as the leanest possible
+// request body when smoke-testing an API. Objects only hold their required properties (including those required by
+// allOf members), optional properties are left out. Every other value is as small as the schema allows:
+//
+//   - a const, or the first enum value, is used as it is.
+//   - strings are empty, or 'a' repeated minLength times. Known formats (like uuid or date-time) get a valid value.
+//   - numbers are 0, moved inside minimum / maximum, and rounded up to a multipleOf (or a whole number, for
+//     integers). If that leaves the number outside the other bound, there is no valid number.
+//   - arrays have minItems items, each the minimal instance of items (or prefixItems).
+//   - booleans are false, a schema with no type is null, unless its first oneOf / anyOf member says otherwise.
+//
+// The instance is checked against the schema before it's returned (with formats asserted), an error is returned if
+// it's not valid, for example when a pattern has to be matched, or if required properties refer back to themselves
+// so no finite instance exists.
+func (s *Schema) MinimalInstance() (any, error) {
+	if s == nil {
+		return nil, fmt.Errorf("cannot create an instance of a nil schema")
+	}
+	instance, err := s.minimalInstance(nil)
+	if err != nil {
+		return nil, err
+	}
+	if errs := s.Validate(instance, AssertFormat(true)); len(errs) > 0 {
+		return nil, fmt.Errorf("cannot create a minimal instance: %w", errs[0])
+	}
+	return instance, nil
+}
+
+// minimalInstance builds the minimal instance of a schema, active holds the schemas being built, to detect cycles.
+func (s *Schema) minimalInstance(active []any) (any, error) {
+	if slices.Contains(active, schemaKey(s)) {
+		return nil, fmt.Errorf("required properties are circular, there is no finite instance")
+	}
+	active = append(active, schemaKey(s))
+
+	if s.Const != nil {
+		return s.nodeValue(s.Const), nil
+	}
+	if len(s.Enum) > 0 {
+		return s.nodeValue(s.Enum[0]), nil
+	}
+
+	switch s.EffectiveType() {
+	case "object":
+		return s.minimalObject(active)
+	case "array":
+		return s.minimalArray(active)
+	case "string":
+		return s.minimalString(), nil
+	case "integer":
+		value, err := s.minimalNumber(true)
+		if err != nil {
+			return nil, err
+		}
+		return int64(value), nil
+	case "number":
+		return s.minimalNumber(false)
+	case "boolean":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+
+	// no single type, the first union member (or the inferred type) decides.
+	for _, members := range [][]*SchemaProxy{s.OneOf, s.AnyOf} {
+		if len(members) > 0 {
+			return minimalProxy(members[0], active)
+		}
+	}
+	switch s.JSONType() {
+	case "object":
+		return s.minimalObject(active)
+	case "array":
+		return s.minimalArray(active)
+	case "string":
+		return s.minimalString(), nil
+	case "number", "integer":
+		return s.minimalNumber(false)
+	}
+	return nil, nil
+}
+
+// minimalProxy builds the minimal instance of a proxy, a true (or missing) schema accepts null.
+func minimalProxy(sp *SchemaProxy, active []any) (any, error) {
+	if sp == nil {
+		return nil, nil
+	}
+	if b, isBool := sp.IsBooleanSchema(); isBool {
+		if !b {
+			return nil, fmt.Errorf("schema is false, no value is allowed")
+		}
+		return nil, nil
+	}
+	sch, err := sp.BuildSchema()
+	if sch == nil {
+		if err == nil {
+			err = fmt.Errorf("schema is empty")
+		}
+		return nil, err
+	}
+	return sch.minimalInstance(active)
+}
+
+func (s *Schema) minimalObject(active []any) (any, error) {
+	instance := make(map[string]any)
+	props := s.effectiveProperties()
+	for _, name := range s.EffectiveRequired() {
+		value, err := minimalProxy(props.GetOrZero(name), active)
+		if err != nil {
+			return nil, fmt.Errorf("required property '%s': %w", name, err)
+		}
+		instance[name] = value
+	}
+	return instance, nil
+}
+
+func (s *Schema) minimalArray(active []any) (any, error) {
+	count := 0
+	if s.MinItems != nil {
+		count = int(*s.MinItems)
+	}
+	instance := make([]any, count)
+	for i := range instance {
+		var item *SchemaProxy
+		switch {
+		case i < len(s.PrefixItems):
+			item = s.PrefixItems[i]
+		case s.Items != nil && s.Items.IsA():
+			item = s.Items.A
+		}
+		value, err := minimalProxy(item, active)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+		instance[i] = value
+	}
+	return instance, nil
+}
+
+func (s *Schema) minimalString() string {
+	value := minimalFormats[s.Format]
+	if s.MinLength != nil && int64(len(value)) < *s.MinLength {
+		value += strings.Repeat("a", int(*s.MinLength)-len(value))
+	}
+	return value
+}
+
+// minimalNumber returns the number closest to zero inside the bounds, that is a multiple of multipleOf (and a whole
+// number, if integer is true). An error is returned if the bounds leave no such number.
+func (s *Schema) minimalNumber(integer bool) (float64, error) {
+	var value float64
+	step := 1.0
+	if s.MultipleOf != nil && *s.MultipleOf > 0 {
+		step = *s.MultipleOf
+	}
+	min, minExclusive, hasMin := s.lowerBound()
+	max, maxExclusive, hasMax := s.upperBound()
+	if hasMin && (value < min || (minExclusive && value == min)) {
+		value = math.Ceil(min/step) * step
+		if minExclusive && value == min {
+			value += step
+		}
+		if integer {
+			value = math.Ceil(value)
+		}
+	} else if hasMax && (value > max || (maxExclusive && value == max)) {
+		value = math.Floor(max/step) * step
+		if maxExclusive && value == max {
+			value -= step
+		}
+		if integer {
+			value = math.Floor(value)
+		}
+	}
+	// moving inside one bound can leave the value outside the other, like an integer between 1.2 and 1.5.
+	if (hasMin && (value < min || (minExclusive && value == min))) ||
+		(hasMax && (value > max || (maxExclusive && value == max))) {
+		kind := "number"
+		if integer {
+			kind = "integer"
+		}
+		return 0, fmt.Errorf("there is no %s between the minimum and maximum", kind)
+	}
+	return value, nil
+}
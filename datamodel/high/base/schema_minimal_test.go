@@ -0,0 +1,141 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema_MinimalInstance(t *testing.T) {
+	sch := getHighSchema(t, `type: object
+required: [name]
+properties:
+  name:
+    type: string
+  nickname:
+    type: string`)
+
+	instance, err := sch.MinimalInstance()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"name": ""}, instance)
+}
+
+func TestSchema_MinimalInstance_Constraints(t *testing.T) {
+	sch := getHighSchema(t, `type: object
+required: [id, size, kind, tags, age, price, owner, created]
+properties:
+  id:
+    type: string
+    format: uuid
+  size:
+    type: string
+    enum: [small, large]
+  kind:
+    const: pizza
+  tags:
+    type: array
+    minItems: 2
+    items:
+      type: string
+      minLength: 3
+  age:
+    type: integer
+    exclusiveMinimum: 17
+  price:
+    type: number
+    minimum: 2.5
+    multipleOf: 2
+  owner:
+    type: object
+    required: [email]
+    properties:
+      email:
+        type: string
+        format: email
+      phone:
+        type: string
+  created:
+    type: string
+    format: date-time
+  notes:
+    type: string`)
+
+	instance, err := sch.MinimalInstance()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"id":      "00000000-0000-0000-0000-000000000000",
+		"size":    "small",
+		"kind":    "pizza",
+		"tags":    []any{"aaa", "aaa"},
+		"age":     int64(18),
+		"price":   4.0,
+		"owner":   map[string]any{"email": "a@example.com"},
+		"created": "2000-01-01T00:00:00Z",
+	}, instance)
+}
+
+func TestSchema_MinimalInstance_AllOf(t *testing.T) {
+	yml := `openapi: 3.1.0
+components:
+  schemas:
+    Base:
+      type: object
+      required: [id]
+      properties:
+        id:
+          type: integer
+          maximum: -5
+    Sub:
+      allOf:
+        - $ref: '#/components/schemas/Base'
+        - required: [name]
+          properties:
+            name:
+              type: string
+    Loop:
+      type: object
+      required: [next]
+      properties:
+        next:
+          $ref: '#/components/schemas/Loop'`
+
+	instance, err := buildComponentSchema(t, yml, "Sub").MinimalInstance()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"id": int64(-5), "name": ""}, instance)
+
+	_, err = buildComponentSchema(t, yml, "Loop").MinimalInstance()
+	assert.EqualError(t, err, "required property 'next': required properties are circular, there is no finite instance")
+}
+
+func TestSchema_MinimalInstance_Invalid(t *testing.T) {
+	_, err := getHighSchema(t, `type: string
+pattern: '^[0-9]+$'`).MinimalInstance()
+	assert.EqualError(t, err, "cannot create a minimal instance: /: value '' does not match pattern '^[0-9]+$'")
+
+	// rounding up to a whole number passes the maximum.
+	_, err = getHighSchema(t, `type: integer
+minimum: 1.2
+maximum: 1.5`).MinimalInstance()
+	assert.EqualError(t, err, "there is no integer between the minimum and maximum")
+
+	_, err = getHighSchema(t, `type: number
+minimum: 1.2
+maximum: 1.5
+multipleOf: 1`).MinimalInstance()
+	assert.EqualError(t, err, "there is no number between the minimum and maximum")
+
+	// a whole number in range is fine.
+	instance, err := getHighSchema(t, `type: integer
+minimum: 1.2
+maximum: 2.5`).MinimalInstance()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), instance)
+
+	instance, err = getHighSchema(t, `type: integer
+maximum: -1.5`).MinimalInstance()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-2), instance)
+}
@@ -0,0 +1,277 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/pb33f/libopenapi/datamodel/low/base"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/libopenapi/utils"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaBuildOptions is used to configure how a high-level Schema is built by NewSchemaWithOptions. The options are
+// inherited by every child schema (properties, items, allOf etc.) when they are built.
+type SchemaBuildOptions struct {
+	// ValueDecoder will be used to decode the default, example, examples, enum and const values of a schema, instead
+	// of the standard YAML decoder. Useful when a specification uses custom tags (like '!duration 5m') for values.
+	// If the decoder returns (nil, nil), the standard decoder is used for that node.
+	ValueDecoder func(node *yaml.Node) (any, error)
+
+	// Metrics, if set, records how many schemas were built, how long that took and how many goroutines were used.
+	Metrics *BuildMetrics
+
+	// SyncBuildThreshold is the number of child schemas (properties, dependentSchemas, patternProperties and the
+	// members of allOf, anyOf, oneOf and prefixItems) a schema needs before its compositions are built concurrently.
+	// Smaller schemas are built on the calling goroutine. Zero uses DefaultSyncBuildThreshold, a negative value
+	// always builds concurrently.
+	SyncBuildThreshold int
+
+	// BaseURI is the location of the document the schema is built from, like 'specs/api/openapi.yaml' (or the
+	// same as a 'file:' URI). Relative external references are resolved against it by SchemaProxy.ResolveExternal,
+	// so '../models/pet.yaml' refers to 'specs/models/pet.yaml'. Without a base, references are relative to the
+	// root of the loader. Schemas built from a file loaded by ResolveExternal have the path of that file as their
+	// base.
+	BaseURI string
+
+	// ExtensionPrefixes changes which keys of a schema are extensions. By default, keys starting with 'x-' are
+	// extensions, the prefixes given replace that default, so include 'x-' to keep it. For example, with 'acme-' and
+	// 'x-' a key 'acme-owner' is collected into Extensions, rather than being reported as an unknown keyword.
+	ExtensionPrefixes []string
+
+	// components holds the schemas built by NewSchemas, so references between them can be resolved.
+	components map[string]*Schema
+
+	// resolver is shared by every proxy built with these options, it's created by the first call to
+	// SchemaProxy.ResolveExternal, so each file is only loaded once.
+	resolver *externalResolver
+
+	// loaded is set on the options of schemas built from a file loaded by the resolver, references in those
+	// schemas are found through it.
+	loaded *externalResolver
+}
+
+// DefaultSyncBuildThreshold is the number of child schemas a schema needs before its compositions are built
+// concurrently, when SchemaBuildOptions.SyncBuildThreshold is not set. Below it, the goroutines and channels cost
+// more than building the schema.
+const DefaultSyncBuildThreshold = 4
+
+// buildAsync returns true if a schema with the number of children given should be built concurrently.
+func (o *SchemaBuildOptions) buildAsync(children int) bool {
+	threshold := DefaultSyncBuildThreshold
+	if o != nil && o.SyncBuildThreshold != 0 {
+		threshold = o.SyncBuildThreshold
+	}
+	return threshold < 0 || children >= threshold
+}
+
+// NewSchemaWithOptions will create a new high-level schema from a low-level one, using the options supplied.
+// A nil SchemaBuildOptions has the same behavior as NewSchema.
+func NewSchemaWithOptions(schema *base.Schema, opts *SchemaBuildOptions) *Schema {
+	metrics := opts.buildMetrics()
+	if metrics == nil {
+		s := newSchema(context.Background(), schema, opts)
+		s.setOptions(opts)
+		return s
+	}
+	start := time.Now()
+	s := newSchema(context.Background(), schema, opts)
+	s.setOptions(opts)
+	metrics.record(start)
+	return s
+}
+
+// setOptions records the options against the schema, and hands them down to all child proxies, so they are used
+// when those children are built.
+func (s *Schema) setOptions(opts *SchemaBuildOptions) {
+	s.opts = opts
+	for _, sp := range s.childProxies() {
+		sp.opts = opts
+	}
+}
+
+// childProxies returns every proxy directly held by this schema, in a stable order. Nil proxies are skipped.
+func (s *Schema) childProxies() []*SchemaProxy {
+	children := s.namedChildProxies()
+	proxies := make([]*SchemaProxy, len(children))
+	for i := range children {
+		proxies[i] = children[i].proxy
+	}
+	return proxies
+}
+
+// schemaChild is a proxy held by a schema, along with the JSON Pointer to it from that schema, like '/items'.
+type schemaChild struct {
+	proxy *SchemaProxy
+	path  string
+}
+
+// namedChildProxies returns every proxy directly held by this schema along with its location, in the same order
+// as childProxies.
+func (s *Schema) namedChildProxies() []schemaChild {
+	var children []schemaChild
+	add := func(path string, sp *SchemaProxy) {
+		if sp != nil {
+			children = append(children, schemaChild{proxy: sp, path: path})
+		}
+	}
+	addList := func(keyword string, proxies []*SchemaProxy) {
+		for i, sp := range proxies {
+			add("/"+keyword+"/"+strconv.Itoa(i), sp)
+		}
+	}
+	addMap := func(keyword string, m *orderedmap.Map[string, *SchemaProxy]) {
+		for pair := orderedmap.First(m); pair != nil; pair = pair.Next() {
+			add("/"+keyword+"/"+escapePointer(pair.Key()), pair.Value())
+		}
+	}
+	addDynamic := func(keyword string, dv *DynamicValue[*SchemaProxy, bool]) {
+		if dv != nil && dv.IsA() {
+			add("/"+keyword, dv.A)
+		}
+	}
+	addList("allOf", s.AllOf)
+	addList("oneOf", s.OneOf)
+	addList("anyOf", s.AnyOf)
+	addList("prefixItems", s.PrefixItems)
+	add("/contains", s.Contains)
+	add("/if", s.If)
+	add("/else", s.Else)
+	add("/then", s.Then)
+	add("/propertyNames", s.PropertyNames)
+	add("/unevaluatedItems", s.UnevaluatedItems)
+	add("/not", s.Not)
+	addDynamic("items", s.Items)
+	addDynamic("unevaluatedProperties", s.UnevaluatedProperties)
+	addDynamic("additionalProperties", s.AdditionalProperties)
+	addMap("properties", s.Properties)
+	addMap("patternProperties", s.PatternProperties)
+	addMap("dependentSchemas", s.DependentSchemas)
+	return children
+}
+
+// extensionPrefixes returns the extension prefixes from the options, or nil if the default 'x-' should be used.
+func (o *SchemaBuildOptions) extensionPrefixes() []string {
+	if o == nil {
+		return nil
+	}
+	return o.ExtensionPrefixes
+}
+
+// decodeValue decodes a value node (default, example, enum or const) using the ValueDecoder from the build options,
+// if there is one, otherwise the standard YAML decoder is used.
+func (s *Schema) decodeValue(n *yaml.Node) (any, error) {
+	if n == nil {
+		return nil, nil
+	}
+	if s.opts != nil && s.opts.ValueDecoder != nil {
+		v, err := s.opts.ValueDecoder(n)
+		if err != nil || v != nil {
+			return v, err
+		}
+	}
+	// integers of int64 schemas are parsed directly, so large values can never pass through a float64. Quoted
+	// values are strings, as they would be for a 'type: string, format: int64' schema.
+	if s.Format == "int64" && utils.NodeAlias(n).ShortTag() == "!!int" {
+		if i, ok := scalarInt64(n); ok {
+			return i, nil
+		}
+	}
+	var v any
+	err := n.Decode(&v)
+	return v, err
+}
+
+// scalarInt64 parses an integer scalar node into an int64, without going through a float64. Both the plain
+// and quoted forms are understood ('12' and "12"), as are the hex, octal and binary forms YAML allows. Callers
+// that need to keep quoted values as strings check the tag first.
+func scalarInt64(n *yaml.Node) (int64, bool) {
+	n = utils.NodeAlias(n)
+	if n == nil || n.Kind != yaml.ScalarNode {
+		return 0, false
+	}
+	if tag := n.ShortTag(); tag != "!!int" && tag != "!!str" {
+		return 0, false
+	}
+	i, err := strconv.ParseInt(n.Value, 0, 64)
+	if err != nil {
+		return 0, false
+	}
+	return i, true
+}
+
+// nodeValue decodes a value node in the same way as decodeValue, values that cannot be decoded are nil.
+func (s *Schema) nodeValue(n *yaml.Node) any {
+	v, err := s.decodeValue(n)
+	if err != nil {
+		return nil
+	}
+	return normalizeInstance(v)
+}
+
+// DefaultValue returns the decoded default value of the schema, or nil if there is no default.
+func (s *Schema) DefaultValue() (any, error) {
+	return s.decodeValue(s.Default)
+}
+
+// DefaultInt64 returns the default value of the schema as a precise int64, the value is parsed straight from the
+// document so large values (like 9223372036854775807) never lose precision. The second value is false if there is
+// no default, or if the default is not an integer that fits into an int64.
+func (s *Schema) DefaultInt64() (int64, bool) {
+	return scalarInt64(s.Default)
+}
+
+// ExampleValue returns the decoded example value of the schema, or nil if there is no example.
+func (s *Schema) ExampleValue() (any, error) {
+	return s.decodeValue(s.Example)
+}
+
+// PreferredExample returns the decoded example that best represents the schema, useful when generating samples.
+// An example in 'examples' can be marked as the preferred one using the 'x-example-default' extension, either by
+// a mapping example holding 'x-example-default: true' (the marker is not part of the value returned), or by the
+// schema holding 'x-example-default' set to the index of the example. Without a marker, the first example in
+// 'examples' is used, then the singular 'example'. The second value is false if there is no example to use.
+func (s *Schema) PreferredExample() (any, bool) {
+	decode := func(n *yaml.Node) (any, bool) {
+		v, err := s.decodeValue(n)
+		return v, err == nil && n != nil
+	}
+	if i, ok := scalarInt64(s.Extensions.GetOrZero("x-example-default")); ok && i >= 0 && i < int64(len(s.Examples)) {
+		return decode(s.Examples[i])
+	}
+	for _, e := range s.Examples {
+		e = utils.NodeAlias(e)
+		if e == nil || e.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 0; i < len(e.Content)-1; i += 2 {
+			if e.Content[i].Value == "x-example-default" && e.Content[i+1].Value == "true" {
+				unmarked := *e
+				unmarked.Content = append(slices.Clone(e.Content[:i]), e.Content[i+2:]...)
+				return decode(&unmarked)
+			}
+		}
+	}
+	if len(s.Examples) > 0 {
+		return decode(s.Examples[0])
+	}
+	return decode(s.Example)
+}
+
+// EnumValues returns the decoded enum values of the schema, in the order they are declared.
+func (s *Schema) EnumValues() ([]any, error) {
+	var values []any
+	for _, e := range s.Enum {
+		v, err := s.decodeValue(e)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
@@ -0,0 +1,197 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pb33f/libopenapi/datamodel/low"
+	lowbase "github.com/pb33f/libopenapi/datamodel/low/base"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func getHighSchemaWithOptions(t *testing.T, yml string, opts *SchemaBuildOptions) *Schema {
+	var node yaml.Node
+	assert.NoError(t, yaml.Unmarshal([]byte(yml), &node))
+	var lowSchema lowbase.Schema
+	assert.NoError(t, low.BuildModel(node.Content[0], &lowSchema))
+	assert.NoError(t, lowSchema.Build(context.Background(), node.Content[0], nil))
+	return NewSchemaWithOptions(&lowSchema, opts)
+}
+
+func TestNewSchemaWithOptions_ValueDecoder(t *testing.T) {
+	yml := `type: object
+properties:
+  timeout:
+    type: string
+    default: !duration 5m
+    example: !duration 90s
+    enum: [!duration 5m, !duration 10m, 1h]`
+
+	opts := &SchemaBuildOptions{
+		ValueDecoder: func(node *yaml.Node) (any, error) {
+			if node.Tag == "!duration" {
+				return time.ParseDuration(node.Value)
+			}
+			return nil, nil
+		},
+	}
+
+	sch := getHighSchemaWithOptions(t, yml, opts)
+	timeout := sch.Properties.GetOrZero("timeout").Schema()
+
+	def, err := timeout.DefaultValue()
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, def)
+
+	ex, err := timeout.ExampleValue()
+	assert.NoError(t, err)
+	assert.Equal(t, 90*time.Second, ex)
+
+	enum, err := timeout.EnumValues()
+	assert.NoError(t, err)
+	assert.Equal(t, []any{5 * time.Minute, 10 * time.Minute, "1h"}, enum)
+
+	// without the decoder, the tag is ignored.
+	plain := getHighSchema(t, yml).Properties.GetOrZero("timeout").Schema()
+	def, err = plain.DefaultValue()
+	assert.NoError(t, err)
+	assert.Equal(t, "5m", def)
+
+	// decoder errors are returned.
+	opts.ValueDecoder = func(node *yaml.Node) (any, error) {
+		return time.ParseDuration("pizza")
+	}
+	broken := getHighSchemaWithOptions(t, yml, opts).Properties.GetOrZero("timeout").Schema()
+	_, err = broken.DefaultValue()
+	assert.Error(t, err)
+	_, err = broken.EnumValues()
+	assert.Error(t, err)
+}
+
+func TestNewSchemaWithOptions_ExtensionPrefixes(t *testing.T) {
+	yml := `type: object
+acme-foo: bar
+x-cake: yummy
+properties:
+  name:
+    type: string
+    acme-owner: team`
+
+	sch := getHighSchemaWithOptions(t, yml, &SchemaBuildOptions{ExtensionPrefixes: []string{"acme-"}})
+	assert.Equal(t, 1, sch.Extensions.Len())
+	assert.Equal(t, "bar", sch.Extensions.GetOrZero("acme-foo").Value)
+	assert.Len(t, sch.Warnings(), 1)
+	assert.Equal(t, "unknown schema keyword 'x-cake'", sch.Warnings()[0].Message)
+
+	// child schemas are built with the same prefixes.
+	name := sch.Properties.GetOrZero("name").Schema()
+	assert.Equal(t, "team", name.Extensions.GetOrZero("acme-owner").Value)
+	assert.Empty(t, name.Warnings())
+
+	// include 'x-' to keep the default.
+	sch = getHighSchemaWithOptions(t, yml, &SchemaBuildOptions{ExtensionPrefixes: []string{"acme-", "x-"}})
+	assert.Equal(t, 2, sch.Extensions.Len())
+	assert.Empty(t, sch.Warnings())
+
+	// by default, only 'x-' is an extension.
+	sch = getHighSchema(t, yml)
+	assert.Equal(t, 1, sch.Extensions.Len())
+	assert.Equal(t, "yummy", sch.Extensions.GetOrZero("x-cake").Value)
+	assert.Equal(t, "unknown schema keyword 'acme-foo'", sch.Warnings()[0].Message)
+}
+
+func TestSchema_DefaultInt64(t *testing.T) {
+	sch := getHighSchema(t, `type: integer
+format: int64
+default: 9223372036854775807
+example: 9223372036854775806`)
+
+	def, ok := sch.DefaultInt64()
+	assert.True(t, ok)
+	assert.Equal(t, int64(9223372036854775807), def)
+
+	value, err := sch.DefaultValue()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(9223372036854775807), value)
+	value, err = sch.ExampleValue()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(9223372036854775806), value)
+
+	enum := getHighSchema(t, `type: integer
+format: int64
+enum: [9223372036854775807]`)
+	assert.Empty(t, enum.Validate(int64(9223372036854775807)))
+	assert.Len(t, enum.Validate(int64(9223372036854775806)), 1)
+
+	rend, err := sch.Render()
+	assert.NoError(t, err)
+	assert.Contains(t, string(rend), "default: 9223372036854775807")
+
+	quoted := getHighSchema(t, `type: integer
+format: int64
+default: "-9223372036854775808"`)
+	def, ok = quoted.DefaultInt64()
+	assert.True(t, ok)
+	assert.Equal(t, int64(-9223372036854775808), def)
+
+	// quoted values are only read as an int64 by DefaultInt64, decoded they are still strings.
+	value, err = quoted.DefaultValue()
+	assert.NoError(t, err)
+	assert.Equal(t, "-9223372036854775808", value)
+
+	ids := getHighSchema(t, `type: string
+format: int64
+default: '7'
+enum: ['1', '2', '7']`)
+	value, err = ids.DefaultValue()
+	assert.NoError(t, err)
+	assert.Equal(t, "7", value)
+	assert.Empty(t, ids.Validate("1"))
+	assert.Len(t, ids.Validate("3"), 1)
+	assert.Empty(t, ids.Contradictions())
+
+	for _, yml := range []string{"type: integer\ndefault: 9223372036854775808", "type: integer\ndefault: 1.5",
+		"type: integer\ndefault: pizza", "type: integer"} {
+		_, ok = getHighSchema(t, yml).DefaultInt64()
+		assert.False(t, ok, yml)
+	}
+}
+
+func TestSchema_PreferredExample(t *testing.T) {
+	sch := getHighSchema(t, `type: object
+examples:
+  - name: first
+  - name: second
+    x-example-default: true
+  - name: third`)
+	ex, ok := sch.PreferredExample()
+	assert.True(t, ok)
+	assert.Equal(t, map[string]any{"name": "second"}, ex)
+	assert.Len(t, sch.Examples[1].Content, 4)
+
+	// the schema can point at the preferred example.
+	ex, ok = getHighSchema(t, `type: string
+x-example-default: 2
+examples: [small, medium, large]`).PreferredExample()
+	assert.True(t, ok)
+	assert.Equal(t, "large", ex)
+
+	ex, ok = getHighSchema(t, `type: string
+examples: [small, medium]
+example: large`).PreferredExample()
+	assert.True(t, ok)
+	assert.Equal(t, "small", ex)
+
+	ex, ok = getHighSchema(t, `type: string
+example: large`).PreferredExample()
+	assert.True(t, ok)
+	assert.Equal(t, "large", ex)
+
+	_, ok = getHighSchema(t, `type: string`).PreferredExample()
+	assert.False(t, ok)
+}
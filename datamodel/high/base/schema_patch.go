@@ -0,0 +1,139 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"github.com/pb33f/libopenapi/orderedmap"
+	"golang.org/x/exp/slices"
+)
+
+// Patch returns a copy of the schema with the overlay applied on top of it, the original schema and the overlay
+// are not modified. This is useful for layering overrides (environment specific tweaks for example) over a base
+// schema. The merge rules are:
+//
+//   - Scalar and single value fields (Title, Description, Format, Pattern, Maximum, Minimum, MaxLength, Nullable,
+//     ReadOnly, Items, AdditionalProperties, Discriminator, XML, Default, Example etc.) are replaced by the overlay,
+//     when the overlay sets them.
+//   - List fields (Type, Enum, Examples, AllOf, OneOf, AnyOf, PrefixItems) are replaced as a whole by the overlay,
+//     when the overlay sets them.
+//   - Required is a union of both lists, in order, with the base schema names first.
+//   - Properties, PatternProperties, DependentSchemas, DependentRequired and Extensions are merged by key. Keys from
+//     the base schema keep their order, new keys from the overlay are appended. When both define a key, the overlay
+//     wins.
+//
+// Properties are not merged recursively, an overlay property replaces the base property entirely. The result is
+// still backed by the low-level model of the base schema, so GoLow() returns the same low-level schema.
+func (s *Schema) Patch(overlay *Schema) *Schema {
+	if s == nil {
+		return nil
+	}
+	p := *s
+	p.Properties = mergeMaps(s.Properties, nil)
+	p.PatternProperties = mergeMaps(s.PatternProperties, nil)
+	p.DependentSchemas = mergeMaps(s.DependentSchemas, nil)
+	p.DependentRequired = mergeMaps(s.DependentRequired, nil)
+	p.Extensions = mergeMaps(s.Extensions, nil)
+	p.Required = slices.Clone(s.Required)
+	if overlay == nil {
+		return &p
+	}
+
+	// single values
+	patchString(&p.SchemaTypeRef, overlay.SchemaTypeRef)
+	patchString(&p.Anchor, overlay.Anchor)
+	patchString(&p.Title, overlay.Title)
+	patchString(&p.Description, overlay.Description)
+	patchString(&p.Pattern, overlay.Pattern)
+	patchString(&p.Format, overlay.Format)
+	patchPointer(&p.ExclusiveMaximum, overlay.ExclusiveMaximum)
+	patchPointer(&p.ExclusiveMinimum, overlay.ExclusiveMinimum)
+	patchPointer(&p.Discriminator, overlay.Discriminator)
+	patchPointer(&p.Contains, overlay.Contains)
+	patchPointer(&p.MinContains, overlay.MinContains)
+	patchPointer(&p.MaxContains, overlay.MaxContains)
+	patchPointer(&p.If, overlay.If)
+	patchPointer(&p.Else, overlay.Else)
+	patchPointer(&p.Then, overlay.Then)
+	patchPointer(&p.PropertyNames, overlay.PropertyNames)
+	patchPointer(&p.UnevaluatedItems, overlay.UnevaluatedItems)
+	patchPointer(&p.UnevaluatedProperties, overlay.UnevaluatedProperties)
+	patchPointer(&p.Items, overlay.Items)
+	patchPointer(&p.Not, overlay.Not)
+	patchPointer(&p.MultipleOf, overlay.MultipleOf)
+	patchPointer(&p.Maximum, overlay.Maximum)
+	patchPointer(&p.Minimum, overlay.Minimum)
+	patchPointer(&p.MaxLength, overlay.MaxLength)
+	patchPointer(&p.MinLength, overlay.MinLength)
+	patchPointer(&p.MaxItems, overlay.MaxItems)
+	patchPointer(&p.MinItems, overlay.MinItems)
+	patchPointer(&p.UniqueItems, overlay.UniqueItems)
+	patchPointer(&p.MaxProperties, overlay.MaxProperties)
+	patchPointer(&p.MinProperties, overlay.MinProperties)
+	patchPointer(&p.AdditionalProperties, overlay.AdditionalProperties)
+	patchPointer(&p.Default, overlay.Default)
+	patchPointer(&p.Const, overlay.Const)
+	patchPointer(&p.Nullable, overlay.Nullable)
+	patchPointer(&p.ReadOnly, overlay.ReadOnly)
+	patchPointer(&p.WriteOnly, overlay.WriteOnly)
+	patchPointer(&p.XML, overlay.XML)
+	patchPointer(&p.ExternalDocs, overlay.ExternalDocs)
+	patchPointer(&p.Example, overlay.Example)
+	patchPointer(&p.Deprecated, overlay.Deprecated)
+
+	// lists
+	patchSlice(&p.Type, overlay.Type)
+	patchSlice(&p.AllOf, overlay.AllOf)
+	patchSlice(&p.OneOf, overlay.OneOf)
+	patchSlice(&p.AnyOf, overlay.AnyOf)
+	patchSlice(&p.PrefixItems, overlay.PrefixItems)
+	patchSlice(&p.Examples, overlay.Examples)
+	patchSlice(&p.Enum, overlay.Enum)
+	for _, r := range overlay.Required {
+		if !slices.Contains(p.Required, r) {
+			p.Required = append(p.Required, r)
+		}
+	}
+
+	// maps
+	p.Properties = mergeMaps(s.Properties, overlay.Properties)
+	p.PatternProperties = mergeMaps(s.PatternProperties, overlay.PatternProperties)
+	p.DependentSchemas = mergeMaps(s.DependentSchemas, overlay.DependentSchemas)
+	p.DependentRequired = mergeMaps(s.DependentRequired, overlay.DependentRequired)
+	p.Extensions = mergeMaps(s.Extensions, overlay.Extensions)
+	return &p
+}
+
+func patchString(dst *string, src string) {
+	if src != "" {
+		*dst = src
+	}
+}
+
+func patchPointer[T any](dst **T, src *T) {
+	if src != nil {
+		*dst = src
+	}
+}
+
+func patchSlice[T any](dst *[]T, src []T) {
+	if len(src) > 0 {
+		*dst = slices.Clone(src)
+	}
+}
+
+// mergeMaps returns a new map holding the keys of the base map, followed by any new keys from the overlay. Values
+// from the overlay replace values from the base. If both maps are empty, nil is returned.
+func mergeMaps[V any](base, overlay *orderedmap.Map[string, V]) *orderedmap.Map[string, V] {
+	if orderedmap.Len(base) == 0 && orderedmap.Len(overlay) == 0 {
+		return nil
+	}
+	merged := orderedmap.New[string, V]()
+	for pair := orderedmap.First(base); pair != nil; pair = pair.Next() {
+		merged.Set(pair.Key(), pair.Value())
+	}
+	for pair := orderedmap.First(overlay); pair != nil; pair = pair.Next() {
+		merged.Set(pair.Key(), pair.Value())
+	}
+	return merged
+}
@@ -0,0 +1,58 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"testing"
+
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema_Patch(t *testing.T) {
+	sch := getHighSchema(t, `type: object
+description: a pizza
+required: [name]
+properties:
+  name:
+    type: string
+  size:
+    type: integer
+    maximum: 20`)
+
+	max := 12.0
+	overlay := &Schema{
+		Required:   []string{"name", "crust"},
+		Properties: orderedmap.New[string, *SchemaProxy](),
+	}
+	overlay.Properties.Set("size", CreateSchemaProxy(&Schema{Type: []string{"integer"}, Maximum: &max}))
+	overlay.Properties.Set("crust", CreateSchemaProxy(&Schema{Type: []string{"string"}}))
+
+	patched := sch.Patch(overlay)
+
+	assert.Equal(t, []string{"object"}, patched.Type)
+	assert.Equal(t, "a pizza", patched.Description)
+	assert.Equal(t, []string{"name", "crust"}, patched.Required)
+
+	var keys []string
+	for pair := orderedmap.First(patched.Properties); pair != nil; pair = pair.Next() {
+		keys = append(keys, pair.Key())
+	}
+	assert.Equal(t, []string{"name", "size", "crust"}, keys)
+	assert.Equal(t, 12.0, *patched.Properties.GetOrZero("size").Schema().Maximum)
+
+	// the original is untouched.
+	assert.Equal(t, []string{"name"}, sch.Required)
+	assert.Equal(t, 2, sch.Properties.Len())
+	assert.Equal(t, 20.0, *sch.Properties.GetOrZero("size").Schema().Maximum)
+
+	rend, err := patched.Render()
+	assert.NoError(t, err)
+	assert.Contains(t, string(rend), "crust:")
+	assert.NotContains(t, string(rend), "maximum: 20")
+
+	title := &Schema{Title: "Pizza"}
+	assert.Equal(t, "Pizza", sch.Patch(title).Title)
+	assert.Equal(t, sch.Properties.Len(), sch.Patch(nil).Properties.Len())
+}
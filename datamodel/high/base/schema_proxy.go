@@ -4,6 +4,7 @@
 package base
 
 import (
+	"strconv"
 	"sync"
 
 	"github.com/pb33f/libopenapi/datamodel/high"
@@ -53,6 +54,7 @@ type SchemaProxy struct {
 	rendered   *Schema
 	refStr     string
 	lock       *sync.Mutex
+	opts       *SchemaBuildOptions
 }
 
 // NewSchemaProxy creates a new high-level SchemaProxy from a low-level one.
@@ -78,14 +80,28 @@ func CreateSchemaProxyRef(ref string) *SchemaProxy {
 func (sp *SchemaProxy) Schema() *Schema {
 	sp.lock.Lock()
 	if sp.rendered == nil {
+		// references to schemas already built by NewSchemas are not built again.
+		if sch := sp.opts.component(sp); sch != nil {
+			sp.rendered = sch
+			sp.lock.Unlock()
+			return sch
+		}
 
+		// schemas from a file loaded by ResolveExternal need the references they hold located first.
+		opts := sp.opts
+		if opts != nil && opts.loaded != nil {
+			opts.loaded.prepare(opts.BaseURI, sp.schema.Value.GetValueNode())
+		}
 		s := sp.schema.Value.Schema()
 		if s == nil {
 			sp.buildError = sp.schema.Value.GetBuildError()
 			sp.lock.Unlock()
 			return nil
 		}
-		sch := NewSchema(s)
+		if opts != nil && opts.loaded != nil {
+			opts = opts.forSource(s.SourcePath(), opts.loaded)
+		}
+		sch := NewSchemaWithOptions(s, opts)
 		sch.ParentProxy = sp
 
 		sp.rendered = sch
@@ -112,6 +128,75 @@ func (sp *SchemaProxy) IsReference() bool {
 	return false
 }
 
+// IsBooleanSchema will return true as the second value if the proxy is for a boolean schema (a literal true or
+// false) rather than a schema object. The first value is the boolean value of the schema.
+//
+// JSON Schema allows a schema to be true (accept everything) or false (reject everything), these are common
+// when used with additionalProperties, items or properties.
+func (sp *SchemaProxy) IsBooleanSchema() (value bool, isBool bool) {
+	if sp == nil || sp.schema == nil {
+		return false, false
+	}
+	return sp.schema.Value.IsBooleanSchema()
+}
+
+// Equals returns true if both proxies are for the same schema, without building either of them. Two references are
+// equal when they have the same reference string. Two inline schemas are equal when the nodes they are built from
+// are structurally equal; the same keys and values, regardless of key order, comments, style or position.
+// A reference is never equal to an inline schema.
+//
+// Proxies created from a high-level Schema (using CreateSchemaProxy) have no nodes, so they are rendered to compare.
+func (sp *SchemaProxy) Equals(other *SchemaProxy) bool {
+	if sp == nil || other == nil {
+		return sp == other
+	}
+	if sp == other {
+		return true
+	}
+	if sp.IsReference() || other.IsReference() {
+		return sp.IsReference() && other.IsReference() && sp.GetReference() == other.GetReference()
+	}
+	return nodesEqual(sp.valueNode(), other.valueNode(), make(map[[2]*yaml.Node]bool))
+}
+
+// EqualsSemantic returns true if both proxies are for schemas with the same meaning, a looser test than Equals. Inline
+// schemas are compared by their Signature, so annotations (like title, description and examples), the order of
+// properties and the order of required properties are ignored. References are equal when they have the same
+// reference string, they are never followed. Boolean schemas are only equal to the same boolean schema, and a schema
+// that cannot be built is never equal to anything else.
+func (sp *SchemaProxy) EqualsSemantic(other *SchemaProxy) bool {
+	if sp == nil || other == nil {
+		return sp == other
+	}
+	if sp == other {
+		return true
+	}
+	if sp.IsReference() || other.IsReference() {
+		return sp.IsReference() && other.IsReference() && sp.GetReference() == other.GetReference()
+	}
+	b, isBool := sp.IsBooleanSchema()
+	ob, otherIsBool := other.IsBooleanSchema()
+	if isBool || otherIsBool {
+		return isBool && otherIsBool && b == ob
+	}
+	s, os := sp.Schema(), other.Schema()
+	return s != nil && os != nil && s.Signature() == os.Signature()
+}
+
+// valueNode returns the node the proxy is built from, or the rendered node if the proxy was created from a Schema.
+func (sp *SchemaProxy) valueNode() *yaml.Node {
+	if sp.schema != nil && sp.schema.Value != nil && sp.schema.Value.GetValueNode() != nil {
+		return sp.schema.Value.GetValueNode()
+	}
+	if sp.rendered != nil {
+		n, _ := sp.rendered.MarshalYAML()
+		if rn, ok := n.(*yaml.Node); ok {
+			return rn
+		}
+	}
+	return nil
+}
+
 // GetReference returns the location of the $ref if this SchemaProxy is a reference to another Schema.
 func (sp *SchemaProxy) GetReference() string {
 	if sp.refStr != "" {
@@ -153,6 +238,18 @@ func (sp *SchemaProxy) BuildSchema() (*Schema, error) {
 	return schema, er
 }
 
+// Resolved returns the schema the proxy has already built or resolved (with Schema, BuildSchema or
+// ResolveExternal), and true. Nothing is built, if the proxy has not been resolved yet (or resolving failed),
+// nil and false are returned. A proxy created from a schema with CreateSchemaProxy is always resolved.
+func (sp *SchemaProxy) Resolved() (*Schema, bool) {
+	if sp == nil {
+		return nil, false
+	}
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+	return sp.rendered, sp.rendered != nil
+}
+
 // GetBuildError returns any error that was thrown when calling Schema()
 func (sp *SchemaProxy) GetBuildError() error {
 	return sp.buildError
@@ -181,6 +278,15 @@ func (sp *SchemaProxy) Render() ([]byte, error) {
 func (sp *SchemaProxy) MarshalYAML() (interface{}, error) {
 	var s *Schema
 	var err error
+	// boolean schemas render as they are.
+	if b, ok := sp.IsBooleanSchema(); ok {
+		return utils.CreateBoolNode(strconv.FormatBool(b)), nil
+	}
+	// recursive YAML aliases render as the alias, rendering them out would never end. JSON has no aliases, so
+	// rendering a schema holding one as JSON returns an error.
+	if sp.isRecursiveAlias() {
+		return sp.schema.Value.GetValueNode(), nil
+	}
 	// if this schema isn't a reference, then build it out.
 	if !sp.IsReference() {
 		s, err = sp.BuildSchema()
@@ -188,7 +294,7 @@ func (sp *SchemaProxy) MarshalYAML() (interface{}, error) {
 			return nil, err
 		}
 		nb := high.NewNodeBuilder(s, s.low)
-		return nb.Render(), nil
+		return sp.keepAnchor(nb.Render()), nil
 	} else {
 		refNode := sp.GetReferenceNode()
 		if refNode != nil {
@@ -203,6 +309,12 @@ func (sp *SchemaProxy) MarshalYAML() (interface{}, error) {
 // MarshalYAMLInline will create a ready to render YAML representation of the ExternalDoc object. The
 // $ref values will be inlined instead of kept as is.
 func (sp *SchemaProxy) MarshalYAMLInline() (interface{}, error) {
+	if b, ok := sp.IsBooleanSchema(); ok {
+		return utils.CreateBoolNode(strconv.FormatBool(b)), nil
+	}
+	if sp.isRecursiveAlias() {
+		return sp.schema.Value.GetValueNode(), nil
+	}
 	var s *Schema
 	var err error
 	s, err = sp.BuildSchema()
@@ -224,5 +336,20 @@ func (sp *SchemaProxy) MarshalYAMLInline() (interface{}, error) {
 	}
 	nb := high.NewNodeBuilder(s, s.low)
 	nb.Resolve = true
-	return nb.Render(), nil
+	return sp.keepAnchor(nb.Render()), nil
+}
+
+// isRecursiveAlias returns true if the proxy is for a YAML alias that refers to an anchor containing itself.
+func (sp *SchemaProxy) isRecursiveAlias() bool {
+	return sp.schema != nil && sp.schema.Value.IsRecursiveAlias()
+}
+
+// keepAnchor copies the YAML anchor (&name) of the original node to the rendered node, so any aliases that are
+// rendered as they are (recursive aliases) still point to something. The flow style of the original is kept too.
+func (sp *SchemaProxy) keepAnchor(rendered *yaml.Node) *yaml.Node {
+	if sp.schema != nil && sp.schema.Value != nil && sp.schema.Value.GetValueNode() != nil {
+		rendered.Anchor = sp.schema.Value.GetValueNode().Anchor
+		keepStyle(utils.NodeAlias(sp.schema.Value.GetValueNode()), rendered)
+	}
+	return rendered
 }
@@ -0,0 +1,78 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import "fmt"
+
+// SchemaProxySet is an ordered list of schema proxies, like the members of an allOf, with helpers that save building
+// each member by hand. It's a plain slice, so converting to and from []*SchemaProxy costs nothing.
+type SchemaProxySet []*SchemaProxy
+
+// Len returns the number of proxies in the set.
+func (ps SchemaProxySet) Len() int {
+	return len(ps)
+}
+
+// At returns the proxy at index i, or nil if i is out of range.
+func (ps SchemaProxySet) At(i int) *SchemaProxy {
+	if i < 0 || i >= len(ps) {
+		return nil
+	}
+	return ps[i]
+}
+
+// Built builds every proxy in the set, and returns the schemas in the order they are declared. The first proxy that
+// cannot be built is returned as an error, along with its index.
+func (ps SchemaProxySet) Built() ([]*Schema, error) {
+	built := make([]*Schema, 0, len(ps))
+	for i, sp := range ps {
+		if sp == nil {
+			return nil, fmt.Errorf("schema %d cannot be built: schema is empty", i)
+		}
+		sch, err := sp.BuildSchema()
+		if err != nil {
+			return nil, fmt.Errorf("schema %d cannot be built: %w", i, err)
+		}
+		if sch == nil {
+			return nil, fmt.Errorf("schema %d cannot be built: schema is empty", i)
+		}
+		built = append(built, sch)
+	}
+	return built, nil
+}
+
+// Find builds the proxies in order, and returns the first schema the predicate is true for, along with its index.
+// Proxies after the match are not built. Proxies that cannot be built are skipped. If nothing matches, nil and -1
+// are returned.
+func (ps SchemaProxySet) Find(pred func(sch *Schema) bool) (*Schema, int) {
+	for i, sp := range ps {
+		if sp == nil {
+			continue
+		}
+		if sch := sp.Schema(); sch != nil && pred(sch) {
+			return sch, i
+		}
+	}
+	return nil, -1
+}
+
+// AllOfSet returns the allOf members of the schema as a SchemaProxySet.
+func (s *Schema) AllOfSet() SchemaProxySet {
+	return s.AllOf
+}
+
+// OneOfSet returns the oneOf members of the schema as a SchemaProxySet.
+func (s *Schema) OneOfSet() SchemaProxySet {
+	return s.OneOf
+}
+
+// AnyOfSet returns the anyOf members of the schema as a SchemaProxySet.
+func (s *Schema) AnyOfSet() SchemaProxySet {
+	return s.AnyOf
+}
+
+// PrefixItemsSet returns the prefixItems of the schema as a SchemaProxySet.
+func (s *Schema) PrefixItemsSet() SchemaProxySet {
+	return s.PrefixItems
+}
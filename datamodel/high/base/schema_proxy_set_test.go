@@ -0,0 +1,53 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/exp/slices"
+)
+
+func TestSchemaProxySet(t *testing.T) {
+	sch := buildComponentSchema(t, `components:
+  schemas:
+    Pet:
+      oneOf:
+        - type: string
+        - $ref: '#/components/schemas/Cat'
+        - type: integer
+    Cat:
+      type: object
+      title: Cat`, "Pet")
+
+	set := sch.OneOfSet()
+	assert.Equal(t, 3, set.Len())
+	assert.True(t, set.At(1).IsReference())
+	assert.Nil(t, set.At(3))
+	assert.Nil(t, set.At(-1))
+
+	built, err := set.Built()
+	assert.NoError(t, err)
+	assert.Len(t, built, 3)
+	assert.Equal(t, []string{"string"}, built[0].Type)
+	assert.Equal(t, "Cat", built[1].Title)
+	assert.Equal(t, []string{"integer"}, built[2].Type)
+
+	found, i := set.Find(func(s *Schema) bool { return slices.Contains(s.Type, "object") })
+	assert.Equal(t, 1, i)
+	assert.Equal(t, "Cat", found.Title)
+	found, i = set.Find(func(s *Schema) bool { return slices.Contains(s.Type, "boolean") })
+	assert.Nil(t, found)
+	assert.Equal(t, -1, i)
+
+	assert.Equal(t, 0, sch.AllOfSet().Len())
+	built, err = sch.AnyOfSet().Built()
+	assert.NoError(t, err)
+	assert.Empty(t, built)
+
+	built, err = SchemaProxySet{sch.OneOf[0], nil}.Built()
+	assert.Error(t, err)
+	assert.Nil(t, built)
+}
@@ -169,3 +169,122 @@ components:
 	rend, _ := sp.MarshalYAMLInline()
 	assert.NotNil(t, rend)
 }
+
+func TestSchemaProxy_Equals(t *testing.T) {
+	yml := `components:
+  schemas:
+    Pizza:
+      type: object
+      properties:
+        cheese:
+          $ref: '#/components/schemas/Cheese'
+        topping:
+          $ref: '#/components/schemas/Cheese'
+        sauce:
+          $ref: '#/components/schemas/Sauce'
+        size:
+          type: integer # inches
+          minimum: 8
+        diameter: {minimum: 8, type: integer}
+        weight:
+          type: integer
+          minimum: 100
+    Cheese:
+      type: string
+    Sauce:
+      type: string`
+
+	sch := buildComponentSchema(t, yml, "Pizza")
+	prop := sch.Properties.GetOrZero
+
+	assert.True(t, prop("cheese").Equals(prop("topping")))
+	assert.False(t, prop("cheese").Equals(prop("sauce")))
+	assert.True(t, prop("size").Equals(prop("diameter")))
+	assert.False(t, prop("size").Equals(prop("weight")))
+	assert.False(t, prop("cheese").Equals(prop("size")))
+
+	// nothing was built to compare them.
+	assert.Nil(t, prop("size").rendered)
+
+	assert.True(t, CreateSchemaProxyRef("#/a").Equals(CreateSchemaProxyRef("#/a")))
+	assert.False(t, CreateSchemaProxyRef("#/a").Equals(CreateSchemaProxyRef("#/b")))
+	assert.True(t, CreateSchemaProxy(&Schema{Type: []string{"string"}}).Equals(
+		CreateSchemaProxy(&Schema{Type: []string{"string"}})))
+	assert.False(t, CreateSchemaProxy(&Schema{Type: []string{"string"}}).Equals(nil))
+	assert.True(t, (*SchemaProxy)(nil).Equals(nil))
+}
+
+func TestSchemaProxy_EqualsSemantic(t *testing.T) {
+	sch := getHighSchema(t, `type: object
+properties:
+  a:
+    type: object
+    description: an address
+    required: [street, city]
+    properties:
+      street: {type: string}
+      city: {type: string}
+  b:
+    title: Address
+    type: object
+    required: [city, street]
+    properties:
+      city: {type: string}
+      street: {type: string}
+  c:
+    type: object
+    required: [city]
+    properties:
+      street: {type: string}
+      city: {type: string}
+  d: true
+  e: true
+  f: false`)
+	prop := sch.Properties.GetOrZero
+
+	// annotations and order do not matter.
+	assert.False(t, prop("a").Equals(prop("b")))
+	assert.True(t, prop("a").EqualsSemantic(prop("b")))
+	assert.False(t, prop("a").EqualsSemantic(prop("c")))
+
+	assert.True(t, prop("d").EqualsSemantic(prop("e")))
+	assert.False(t, prop("d").EqualsSemantic(prop("f")))
+	assert.False(t, prop("d").EqualsSemantic(prop("a")))
+
+	assert.True(t, CreateSchemaProxyRef("#/a").EqualsSemantic(CreateSchemaProxyRef("#/a")))
+	assert.False(t, CreateSchemaProxyRef("#/a").EqualsSemantic(prop("a")))
+	assert.False(t, prop("a").EqualsSemantic(nil))
+	assert.True(t, (*SchemaProxy)(nil).EqualsSemantic(nil))
+}
+
+func TestSchemaProxy_Resolved(t *testing.T) {
+	sch := getHighSchema(t, `type: object
+properties:
+  name:
+    type: string`)
+
+	name := sch.Properties.GetOrZero("name")
+	resolved, ok := name.Resolved()
+	assert.False(t, ok)
+	assert.Nil(t, resolved)
+
+	built := name.Schema()
+	resolved, ok = name.Resolved()
+	assert.True(t, ok)
+	assert.Same(t, built, resolved)
+
+	ref := CreateSchemaProxyRef("pizza.yaml#/Pizza")
+	_, ok = ref.Resolved()
+	assert.False(t, ok)
+	loader := func(string) ([]byte, error) { return []byte("Pizza:\n  type: string"), nil }
+	resolved, err := ref.ResolveExternal(loader)
+	assert.NoError(t, err)
+	again, ok := ref.Resolved()
+	assert.True(t, ok)
+	assert.Same(t, resolved, again)
+
+	_, ok = CreateSchemaProxy(&Schema{}).Resolved()
+	assert.True(t, ok)
+	_, ok = (*SchemaProxy)(nil).Resolved()
+	assert.False(t, ok)
+}
@@ -0,0 +1,145 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"github.com/pb33f/libopenapi/orderedmap"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// ShapeKind is the kind of structure a ShapeInfo describes.
+type ShapeKind string
+
+// The kinds of ShapeInfo. ShapeAny is a schema that accepts anything (or can't be worked out), ShapeReference is a
+// child schema that is a reference, and is not expanded.
+const (
+	ShapeObject    ShapeKind = "object"
+	ShapeArray     ShapeKind = "array"
+	ShapeUnion     ShapeKind = "union"
+	ShapeEnum      ShapeKind = "enum"
+	ShapePrimitive ShapeKind = "primitive"
+	ShapeAny       ShapeKind = "any"
+	ShapeReference ShapeKind = "reference"
+)
+
+// ShapeInfo is a language independent description of the structure of a schema, it has everything a code generator
+// needs to emit a type (a TypeScript interface, a Go struct or a Python dataclass for example) without walking the
+// schema again. Only the fields that make sense for the Kind are set.
+type ShapeInfo struct {
+	Kind ShapeKind
+
+	// Type and Format are the JSON type and format of a primitive, for example 'integer' and 'int64'. Enums also
+	// carry the type of their values (if it can be worked out).
+	Type   string
+	Format string
+
+	// Nullable is true if null is allowed as well as the shape.
+	Nullable bool
+
+	// Fields are the properties of an object (including those from allOf), in the order they are declared.
+	Fields []ShapeField
+
+	// MapValue is the shape of the additionalProperties of an object, if it has an additionalProperties schema.
+	MapValue *ShapeInfo
+
+	// Element is the shape of the items of an array, nil if the items can be anything.
+	Element *ShapeInfo
+
+	// Members are the shapes of the oneOf and anyOf members of a union, in the order they are declared.
+	Members []ShapeInfo
+
+	// Values are the literal values of an enum (or the const value).
+	Values []any
+
+	// Reference is the reference of a child schema that is a reference, it's not expanded, a generator would use
+	// the name of the referenced type instead. This also keeps circular schemas from expanding forever.
+	Reference string
+}
+
+// ShapeField is a single field of an object ShapeInfo.
+type ShapeField struct {
+	Name string
+
+	// Required is true if the field must be present, when false the field is optional.
+	Required bool
+
+	Shape ShapeInfo
+}
+
+// ShapeInfo describes the structure of the schema; an object (with its fields), an array (with its element), a union
+// (with its members), an enum (with its values) or a primitive (with its format). Child schemas are described
+// recursively, except for references which are described by their reference only.
+func (s *Schema) ShapeInfo() ShapeInfo {
+	return s.shapeInfo(nil)
+}
+
+func (s *Schema) shapeInfo(active []any) ShapeInfo {
+	if s == nil {
+		return ShapeInfo{Kind: ShapeAny}
+	}
+	active = append(active, schemaKey(s))
+	shape := ShapeInfo{Nullable: s.IsNullable(), Type: s.JSONType(), Format: s.Format}
+
+	child := func(sp *SchemaProxy) ShapeInfo {
+		if sp == nil {
+			return ShapeInfo{Kind: ShapeAny}
+		}
+		if sp.IsReference() {
+			return ShapeInfo{Kind: ShapeReference, Reference: sp.GetReference()}
+		}
+		sch := sp.Schema()
+		if sch == nil || slices.Contains(active, schemaKey(sch)) {
+			return ShapeInfo{Kind: ShapeAny}
+		}
+		return sch.shapeInfo(active)
+	}
+
+	switch {
+	case len(s.Enum) > 0 || s.Const != nil:
+		shape.Kind = ShapeEnum
+		values := s.Enum
+		if s.Const != nil {
+			values = []*yaml.Node{s.Const}
+		}
+		for _, v := range values {
+			shape.Values = append(shape.Values, s.nodeValue(v))
+		}
+	case len(s.OneOf) > 0 || len(s.AnyOf) > 0:
+		shape.Kind = ShapeUnion
+		for _, sp := range append(slices.Clone(s.OneOf), s.AnyOf...) {
+			shape.Members = append(shape.Members, child(sp))
+		}
+	case shape.Type == "array":
+		shape.Kind = ShapeArray
+		if s.Items != nil && s.Items.IsA() {
+			element := child(s.Items.A)
+			shape.Element = &element
+		}
+	case shape.Type == "object" || orderedmap.Len(s.effectiveProperties()) > 0:
+		shape.Kind = ShapeObject
+		shape.Type = "object"
+		var required []string
+		s.walkAllOf(func(sch *Schema) bool {
+			required = append(required, sch.Required...)
+			return true
+		})
+		for pair := orderedmap.First(s.effectiveProperties()); pair != nil; pair = pair.Next() {
+			shape.Fields = append(shape.Fields, ShapeField{
+				Name:     pair.Key(),
+				Required: slices.Contains(required, pair.Key()),
+				Shape:    child(pair.Value()),
+			})
+		}
+		if s.AdditionalProperties != nil && s.AdditionalProperties.IsA() {
+			value := child(s.AdditionalProperties.A)
+			shape.MapValue = &value
+		}
+	case shape.Type != "":
+		shape.Kind = ShapePrimitive
+	default:
+		shape.Kind = ShapeAny
+	}
+	return shape
+}
@@ -0,0 +1,90 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema_ShapeInfo_Object(t *testing.T) {
+	sch := buildComponentSchema(t, `openapi: 3.1.0
+components:
+  schemas:
+    Owner:
+      type: object
+    Order:
+      type: object
+      required: [id]
+      properties:
+        id:
+          type: integer
+          format: int64
+        size:
+          type: string
+          enum: [small, large]
+        tags:
+          type: array
+          items:
+            type: string
+        owner:
+          $ref: '#/components/schemas/Owner'
+        note:
+          type: [string, "null"]
+      additionalProperties:
+        type: boolean`, "Order")
+
+	shape := sch.ShapeInfo()
+	assert.Equal(t, ShapeObject, shape.Kind)
+	assert.Len(t, shape.Fields, 5)
+
+	id := shape.Fields[0]
+	assert.Equal(t, "id", id.Name)
+	assert.True(t, id.Required)
+	assert.Equal(t, ShapeInfo{Kind: ShapePrimitive, Type: "integer", Format: "int64"}, id.Shape)
+
+	size := shape.Fields[1]
+	assert.False(t, size.Required)
+	assert.Equal(t, ShapeEnum, size.Shape.Kind)
+	assert.Equal(t, []any{"small", "large"}, size.Shape.Values)
+
+	tags := shape.Fields[2].Shape
+	assert.Equal(t, ShapeArray, tags.Kind)
+	assert.Equal(t, ShapePrimitive, tags.Element.Kind)
+	assert.Equal(t, "string", tags.Element.Type)
+
+	owner := shape.Fields[3].Shape
+	assert.Equal(t, ShapeReference, owner.Kind)
+	assert.Equal(t, "#/components/schemas/Owner", owner.Reference)
+
+	note := shape.Fields[4].Shape
+	assert.Equal(t, "string", note.Type)
+	assert.True(t, note.Nullable)
+
+	assert.Equal(t, "boolean", shape.MapValue.Type)
+}
+
+func TestSchema_ShapeInfo_Union(t *testing.T) {
+	sch := getHighSchema(t, `oneOf:
+  - type: object
+    allOf:
+      - required: [name]
+        properties:
+          name:
+            type: string
+  - type: integer
+anyOf:
+  - {}`)
+
+	shape := sch.ShapeInfo()
+	assert.Equal(t, ShapeUnion, shape.Kind)
+	assert.Len(t, shape.Members, 3)
+	assert.Equal(t, ShapeObject, shape.Members[0].Kind)
+	assert.Equal(t, []ShapeField{{Name: "name", Required: true, Shape: ShapeInfo{Kind: ShapePrimitive, Type: "string"}}},
+		shape.Members[0].Fields)
+	assert.Equal(t, ShapePrimitive, shape.Members[1].Kind)
+	assert.Equal(t, "integer", shape.Members[1].Type)
+	assert.Equal(t, ShapeAny, shape.Members[2].Kind)
+}
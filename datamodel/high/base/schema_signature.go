@@ -0,0 +1,64 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// Signature returns a canonical signature of the shape of the schema, two schemas that are structurally identical
+// share a signature, which makes it useful as a map key when de-duplicating the anonymous schemas of a large
+// specification (so one type is generated per shape, for example).
+//
+// Annotations (title, description, example, examples and externalDocs) are ignored, as are the order properties
+// are declared in and the order of required properties. Everything else counts, including extensions.
+// References are not followed, two references share a signature if they point at the same place.
+func (s *Schema) Signature() string {
+	if s == nil {
+		return ""
+	}
+	rendered, _ := s.StripAnnotations().MarshalYAML()
+	node, _ := rendered.(*yaml.Node)
+	node, _ = rewriteSchemas(node, sortRequired)
+	var value any
+	if node != nil {
+		_ = node.Decode(&value)
+	}
+
+	// maps are marshalled with sorted keys, so property order does not matter.
+	b, err := json.Marshal(value)
+	if err != nil {
+		b, _ = yaml.Marshal(node)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(b))
+}
+
+// sortRequired returns a copy of a rendered schema with its list of required properties sorted. It's applied to
+// schemas only, so a 'required' key inside a value (like an example or const) keeps its order.
+func sortRequired(sch *yaml.Node) (*yaml.Node, bool, error) {
+	if sch.Kind != yaml.MappingNode {
+		return sch, false, nil
+	}
+	for i := 1; i < len(sch.Content); i += 2 {
+		if sch.Content[i-1].Value != "required" || sch.Content[i].Kind != yaml.SequenceNode {
+			continue
+		}
+		required := *sch.Content[i]
+		required.Content = slices.Clone(required.Content)
+		slices.SortFunc(required.Content, func(a, b *yaml.Node) int {
+			return strings.Compare(a.Value, b.Value)
+		})
+		c := *sch
+		c.Content = slices.Clone(sch.Content)
+		c.Content[i] = &required
+		return &c, false, nil
+	}
+	return sch, false, nil
+}
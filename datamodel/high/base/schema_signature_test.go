@@ -0,0 +1,72 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema_Signature(t *testing.T) {
+	a := getHighSchema(t, `type: object
+title: Pizza
+required: [name, size]
+properties:
+  name:
+    type: string
+    description: the name of the pizza
+  size:
+    type: integer
+    minimum: 1`)
+	b := getHighSchema(t, `type: object
+required: [size, name]
+properties:
+  size:
+    minimum: 1
+    type: integer
+  name:
+    type: string
+    example: margherita`)
+	c := getHighSchema(t, `type: object
+required: [name, size]
+properties:
+  name:
+    type: string
+  size:
+    type: number
+    minimum: 1`)
+
+	assert.Len(t, a.Signature(), 64)
+	assert.Equal(t, a.Signature(), b.Signature())
+	assert.NotEqual(t, a.Signature(), c.Signature())
+
+	// the original is left alone.
+	assert.Equal(t, "Pizza", a.Title)
+	assert.Empty(t, (*Schema)(nil).Signature())
+}
+
+func TestSchema_Signature_RequiredValues(t *testing.T) {
+	// nested schemas have their required properties sorted.
+	a := getHighSchema(t, `type: object
+properties:
+  owner:
+    type: object
+    required: [name, email]`)
+	b := getHighSchema(t, `type: object
+properties:
+  owner:
+    type: object
+    required: [email, name]`)
+	assert.Equal(t, a.Signature(), b.Signature())
+
+	// a 'required' key in a value is not a list of properties, its order counts.
+	a = getHighSchema(t, `type: object
+const:
+  required: [name, email]`)
+	b = getHighSchema(t, `type: object
+const:
+  required: [email, name]`)
+	assert.NotEqual(t, a.Signature(), b.Signature())
+}
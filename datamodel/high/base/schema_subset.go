@@ -0,0 +1,283 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"github.com/pb33f/libopenapi/orderedmap"
+	"golang.org/x/exp/slices"
+)
+
+// IsSubsetOf returns true when every value that is valid against this schema, is also valid against the other
+// schema. For example, a schema with a tighter maximum, or one that requires more properties, is a subset of the
+// looser schema.
+//
+// The check is conservative. Only constraints that can be compared directly are understood, those are types,
+// numeric bounds, string lengths, array and object sizes, required properties, enums, const values, patterns,
+// formats and properties (which are compared recursively). If either schema uses a composition (allOf, anyOf,
+// oneOf, not, if/then/else), or the other schema uses a constraint that cannot be compared, the answer is
+// unknown, and false is returned.
+func (s *Schema) IsSubsetOf(other *Schema) bool {
+	return s.isSubsetOf(other, make(map[[2]any]bool))
+}
+
+func (s *Schema) isSubsetOf(other *Schema, seen map[[2]any]bool) bool {
+	if s == nil || other == nil {
+		return false
+	}
+	if s == other {
+		return true
+	}
+	// circular check, assume the pair holds while it's being checked, the rest of the tree decides.
+	pair := [2]any{schemaKey(s), schemaKey(other)}
+	if seen[pair] {
+		return true
+	}
+	seen[pair] = true
+
+	if s.hasUnknownConstraints() || other.hasUnknownConstraints() {
+		return false
+	}
+
+	// types, an integer is also a number.
+	if len(other.Type) > 0 {
+		if len(s.Type) == 0 {
+			return false
+		}
+		for _, t := range s.Type {
+			if !slices.Contains(other.Type, t) && !(t == "integer" && slices.Contains(other.Type, "number")) {
+				return false
+			}
+		}
+	}
+	if s.Nullable != nil && *s.Nullable && (other.Nullable == nil || !*other.Nullable) {
+		return false
+	}
+
+	// numeric bounds
+	if ov, oex, ok := other.upperBound(); ok {
+		sv, sex, sok := s.upperBound()
+		if !sok || sv > ov || (sv == ov && oex && !sex) {
+			return false
+		}
+	}
+	if ov, oex, ok := other.lowerBound(); ok {
+		sv, sex, sok := s.lowerBound()
+		if !sok || sv < ov || (sv == ov && oex && !sex) {
+			return false
+		}
+	}
+	if other.MultipleOf != nil {
+		if s.MultipleOf == nil || *other.MultipleOf == 0 {
+			return false
+		}
+		if !isMultipleOf(*s.MultipleOf, *other.MultipleOf) {
+			return false
+		}
+	}
+
+	// lengths and sizes
+	if !maxWithin(s.MaxLength, other.MaxLength) || !minWithin(s.MinLength, other.MinLength) ||
+		!maxWithin(s.MaxItems, other.MaxItems) || !minWithin(s.MinItems, other.MinItems) ||
+		!maxWithin(s.MaxProperties, other.MaxProperties) || !minWithin(s.MinProperties, other.MinProperties) {
+		return false
+	}
+	if other.UniqueItems != nil && *other.UniqueItems && (s.UniqueItems == nil || !*s.UniqueItems) {
+		return false
+	}
+	if other.Pattern != "" && s.Pattern != other.Pattern {
+		return false
+	}
+	if other.Format != "" && s.Format != other.Format {
+		return false
+	}
+
+	// every required property of the other schema must be required by this one.
+	for _, r := range other.Required {
+		if !slices.Contains(s.Required, r) {
+			return false
+		}
+	}
+
+	// enums and const
+	if !s.valuesSubsetOf(other) {
+		return false
+	}
+
+	// properties
+	for pair := orderedmap.First(other.Properties); pair != nil; pair = pair.Next() {
+		sp := s.Properties.GetOrZero(pair.Key())
+		if sp == nil {
+			// this schema does not define the property, it can only be narrower if additional properties are
+			// not accepted at all.
+			if s.AdditionalProperties == nil || !s.AdditionalProperties.IsB() || s.AdditionalProperties.B {
+				return false
+			}
+			continue
+		}
+		if !sp.Schema().isSubsetOf(pair.Value().Schema(), seen) {
+			return false
+		}
+	}
+	if other.AdditionalProperties != nil {
+		if other.AdditionalProperties.IsB() && !other.AdditionalProperties.B {
+			if s.AdditionalProperties == nil || !s.AdditionalProperties.IsB() || s.AdditionalProperties.B {
+				return false
+			}
+			for pair := orderedmap.First(s.Properties); pair != nil; pair = pair.Next() {
+				if other.Properties.GetOrZero(pair.Key()) == nil {
+					return false
+				}
+			}
+		}
+		if other.AdditionalProperties.IsA() && other.AdditionalProperties.A != nil {
+			if s.AdditionalProperties == nil || !s.AdditionalProperties.IsA() || s.AdditionalProperties.A == nil {
+				return false
+			}
+			if !s.AdditionalProperties.A.Schema().isSubsetOf(other.AdditionalProperties.A.Schema(), seen) {
+				return false
+			}
+		}
+	}
+
+	// items
+	if other.Items != nil && other.Items.IsA() && other.Items.A != nil {
+		if s.Items == nil || !s.Items.IsA() || s.Items.A == nil {
+			return false
+		}
+		if !s.Items.A.Schema().isSubsetOf(other.Items.A.Schema(), seen) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasUnknownConstraints returns true if the schema uses anything that IsSubsetOf cannot reason about.
+func (s *Schema) hasUnknownConstraints() bool {
+	return len(s.AllOf) > 0 || len(s.AnyOf) > 0 || len(s.OneOf) > 0 || s.Not != nil ||
+		s.If != nil || s.Then != nil || s.Else != nil || len(s.PrefixItems) > 0 || s.Contains != nil ||
+		orderedmap.Len(s.PatternProperties) > 0 || orderedmap.Len(s.DependentSchemas) > 0 ||
+		orderedmap.Len(s.DependentRequired) > 0 ||
+		s.PropertyNames != nil || s.UnevaluatedItems != nil || s.UnevaluatedProperties != nil
+}
+
+// valuesSubsetOf checks the enum and const values of this schema are accepted by the other schema.
+func (s *Schema) valuesSubsetOf(other *Schema) bool {
+	var allowed []any
+	for _, e := range s.Enum {
+		allowed = append(allowed, s.nodeValue(e))
+	}
+	if s.Const != nil {
+		allowed = []any{s.nodeValue(s.Const)}
+	}
+	if len(other.Enum) > 0 {
+		if len(allowed) == 0 {
+			return false
+		}
+		for _, a := range allowed {
+			found := false
+			for _, e := range other.Enum {
+				if valuesEqual(a, other.nodeValue(e)) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+	if other.Const != nil {
+		if len(allowed) != 1 || !valuesEqual(allowed[0], other.nodeValue(other.Const)) {
+			return false
+		}
+	}
+	return true
+}
+
+// upperBound returns the effective maximum of the schema, and if that maximum is exclusive. Both the 3.0 boolean
+// and 3.1 numeric forms of exclusiveMaximum are understood.
+func (s *Schema) upperBound() (float64, bool, bool) {
+	if s.ExclusiveMaximum != nil && s.ExclusiveMaximum.IsB() {
+		if s.Maximum != nil && *s.Maximum < s.ExclusiveMaximum.B {
+			return *s.Maximum, false, true
+		}
+		return s.ExclusiveMaximum.B, true, true
+	}
+	if s.Maximum != nil {
+		return *s.Maximum, s.ExclusiveMaximum != nil && s.ExclusiveMaximum.A, true
+	}
+	return 0, false, false
+}
+
+// lowerBound returns the effective minimum of the schema, and if that minimum is exclusive. Both the 3.0 boolean
+// and 3.1 numeric forms of exclusiveMinimum are understood.
+func (s *Schema) lowerBound() (float64, bool, bool) {
+	if s.ExclusiveMinimum != nil && s.ExclusiveMinimum.IsB() {
+		if s.Minimum != nil && *s.Minimum > s.ExclusiveMinimum.B {
+			return *s.Minimum, false, true
+		}
+		return s.ExclusiveMinimum.B, true, true
+	}
+	if s.Minimum != nil {
+		return *s.Minimum, s.ExclusiveMinimum != nil && s.ExclusiveMinimum.A, true
+	}
+	return 0, false, false
+}
+
+// IntersectRanges returns the tightest numeric range allowed by both schemas, the larger of the two minimums and the
+// smaller of the two maximums, along with whether each bound is exclusive. Both the 3.0 boolean and 3.1 numeric
+// forms of exclusiveMinimum and exclusiveMaximum are understood. When both schemas share a bound value and only
+// one is exclusive, the result is exclusive. A nil bound is open-ended, neither schema sets it (a nil schema sets
+// no bounds at all).
+//
+// The ranges are not checked for overlap. If they are disjoint, the minimum returned is above the maximum (or the
+// two are equal, and at least one is exclusive), no number can satisfy both schemas.
+func IntersectRanges(a, b *Schema) (min, max *float64, minExcl, maxExcl bool) {
+	bound := func(s *Schema, upper bool) (float64, bool, bool) {
+		if s == nil {
+			return 0, false, false
+		}
+		if upper {
+			return s.upperBound()
+		}
+		return s.lowerBound()
+	}
+	tightest := func(upper bool) (*float64, bool) {
+		av, aex, aok := bound(a, upper)
+		bv, bex, bok := bound(b, upper)
+		switch {
+		case !aok && !bok:
+			return nil, false
+		case !bok:
+			return &av, aex
+		case !aok:
+			return &bv, bex
+		case av == bv:
+			return &av, aex || bex
+		case (upper && av < bv) || (!upper && av > bv):
+			return &av, aex
+		default:
+			return &bv, bex
+		}
+	}
+	min, minExcl = tightest(false)
+	max, maxExcl = tightest(true)
+	return min, max, minExcl, maxExcl
+}
+
+// maxWithin returns true if the maximum value 'a' is at least as tight as the maximum value 'b'.
+func maxWithin(a, b *int64) bool {
+	if b == nil {
+		return true
+	}
+	return a != nil && *a <= *b
+}
+
+// minWithin returns true if the minimum value 'a' is at least as tight as the minimum value 'b'.
+func minWithin(a, b *int64) bool {
+	if b == nil {
+		return true
+	}
+	return a != nil && *a >= *b
+}
@@ -0,0 +1,115 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema_IsSubsetOf_TighterMaximum(t *testing.T) {
+	loose := getHighSchema(t, `type: number
+maximum: 100`)
+	tight := getHighSchema(t, `type: integer
+maximum: 10
+minimum: 0`)
+
+	assert.True(t, tight.IsSubsetOf(loose))
+	assert.False(t, loose.IsSubsetOf(tight))
+	assert.True(t, loose.IsSubsetOf(loose))
+}
+
+func TestSchema_IsSubsetOf_ExclusiveMaximum(t *testing.T) {
+	inclusive := getHighSchema(t, `type: number
+maximum: 10`)
+	exclusive := getHighSchema(t, `type: number
+exclusiveMaximum: 10`)
+
+	assert.True(t, exclusive.IsSubsetOf(inclusive))
+	assert.False(t, inclusive.IsSubsetOf(exclusive))
+}
+
+func TestSchema_IsSubsetOf_AddedRequired(t *testing.T) {
+	base := getHighSchema(t, `type: object
+required: [name]
+properties:
+  name:
+    type: string
+    maxLength: 50
+  email:
+    type: string`)
+	narrowed := getHighSchema(t, `type: object
+required: [name, email]
+properties:
+  name:
+    type: string
+    maxLength: 20
+  email:
+    type: string`)
+
+	assert.True(t, narrowed.IsSubsetOf(base))
+	assert.False(t, base.IsSubsetOf(narrowed))
+}
+
+func TestSchema_IsSubsetOf_Enum(t *testing.T) {
+	wide := getHighSchema(t, `type: string
+enum: [a, b, c]`)
+	narrow := getHighSchema(t, `type: string
+enum: [a, c]`)
+	constant := getHighSchema(t, `type: string
+const: b`)
+
+	assert.True(t, narrow.IsSubsetOf(wide))
+	assert.False(t, wide.IsSubsetOf(narrow))
+	assert.True(t, constant.IsSubsetOf(wide))
+	assert.False(t, constant.IsSubsetOf(narrow))
+}
+
+func TestSchema_IsSubsetOf_UnknownIsFalse(t *testing.T) {
+	composed := getHighSchema(t, `oneOf:
+  - type: string
+  - type: integer`)
+	str := getHighSchema(t, `type: string`)
+
+	assert.False(t, composed.IsSubsetOf(str))
+	assert.False(t, str.IsSubsetOf(composed))
+	assert.False(t, str.IsSubsetOf(nil))
+}
+
+func TestIntersectRanges(t *testing.T) {
+	// overlapping
+	min, max, minExcl, maxExcl := IntersectRanges(getHighSchema(t, `minimum: 1
+maximum: 10`), getHighSchema(t, `minimum: 5
+maximum: 20`))
+	assert.Equal(t, 5.0, *min)
+	assert.Equal(t, 10.0, *max)
+	assert.False(t, minExcl)
+	assert.False(t, maxExcl)
+
+	// disjoint, the minimum ends up above the maximum.
+	min, max, _, _ = IntersectRanges(getHighSchema(t, `minimum: 1
+maximum: 3`), getHighSchema(t, `minimum: 5
+maximum: 8`))
+	assert.Equal(t, 5.0, *min)
+	assert.Equal(t, 3.0, *max)
+
+	// one side open-ended.
+	min, max, minExcl, maxExcl = IntersectRanges(getHighSchema(t, `exclusiveMinimum: 0`),
+		getHighSchema(t, `maximum: 100`))
+	assert.Equal(t, 0.0, *min)
+	assert.True(t, minExcl)
+	assert.Equal(t, 100.0, *max)
+	assert.False(t, maxExcl)
+
+	// the same bound, exclusive on one side, is exclusive. Both 3.0 and 3.1 forms are understood.
+	_, max, _, maxExcl = IntersectRanges(getHighSchema(t, `maximum: 10
+exclusiveMaximum: true`), getHighSchema(t, `maximum: 10`))
+	assert.Equal(t, 10.0, *max)
+	assert.True(t, maxExcl)
+
+	min, max, _, _ = IntersectRanges(getHighSchema(t, `type: number`), nil)
+	assert.Nil(t, min)
+	assert.Nil(t, max)
+}
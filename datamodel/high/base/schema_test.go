@@ -4,8 +4,11 @@
 package base
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"math"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -14,6 +17,7 @@ import (
 	"github.com/pb33f/libopenapi/datamodel/low"
 	lowbase "github.com/pb33f/libopenapi/datamodel/low/base"
 	"github.com/pb33f/libopenapi/index"
+	"github.com/pb33f/libopenapi/orderedmap"
 	"github.com/pb33f/libopenapi/utils"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v3"
@@ -572,6 +576,21 @@ func getHighSchema(t *testing.T, yml string) *Schema {
 	return NewSchema(&lowSchema)
 }
 
+// buildComponentSchema indexes a document and returns the named schema from components, so references resolve.
+func buildComponentSchema(t *testing.T, yml, name string) *Schema {
+	var node yaml.Node
+	assert.NoError(t, yaml.Unmarshal([]byte(yml), &node))
+	idx := index.NewSpecIndexWithConfig(&node, index.CreateOpenAPIIndexConfig())
+
+	_, components := utils.FindKeyNodeTop("components", node.Content[0].Content)
+	_, schemas := utils.FindKeyNodeTop("schemas", components.Content)
+	kn, vn := utils.FindKeyNodeTop(name, schemas.Content)
+
+	sp := new(lowbase.SchemaProxy)
+	assert.NoError(t, sp.Build(context.Background(), kn, vn, idx))
+	return NewSchemaProxy(&low.NodeReference[*lowbase.SchemaProxy]{Value: sp, KeyNode: kn, ValueNode: vn}).Schema()
+}
+
 func TestSchemaNumberNoValidation(t *testing.T) {
 	yml := `
 type: number
@@ -1296,3 +1315,813 @@ func TestSchema_RenderProxyWithConfig_Corrected_3(t *testing.T) {
 	schemaBytes, _ = compiled.RenderInline()
 	assert.Equal(t, testSpecCorrect, strings.TrimSpace(string(schemaBytes)))
 }
+
+func TestSchema_Warnings(t *testing.T) {
+	yml := `type: object
+description: a schema with a typo
+maxLenght: 10
+properties:
+  name:
+    type: string`
+
+	highSchema := getHighSchema(t, yml)
+	warnings := highSchema.Warnings()
+	assert.Len(t, warnings, 1)
+	assert.Equal(t, "/maxLenght", warnings[0].Path)
+	assert.Equal(t, "unknown schema keyword 'maxLenght'", warnings[0].Message)
+	assert.Equal(t, 3, warnings[0].Line)
+	assert.Equal(t, 1, warnings[0].Column)
+
+	// child schemas hold their own warnings.
+	assert.Empty(t, highSchema.Properties.GetOrZero("name").Schema().Warnings())
+}
+
+func TestSchema_PropertyKeyNode(t *testing.T) {
+	yml := `type: object
+properties:
+  name:
+    type: string
+  age:
+    type: integer`
+
+	highSchema := getHighSchema(t, yml)
+
+	kn := highSchema.PropertyKeyNode("age")
+	assert.NotNil(t, kn)
+	assert.Equal(t, "age", kn.Value)
+	assert.Equal(t, 5, kn.Line)
+	assert.Equal(t, 3, kn.Column)
+
+	assert.Nil(t, highSchema.PropertyKeyNode("pizza"))
+	assert.Nil(t, (&Schema{}).PropertyKeyNode("age"))
+}
+
+func TestSchema_IsNullable(t *testing.T) {
+	assert.True(t, getHighSchema(t, `type: string
+nullable: true`).IsNullable())
+	assert.False(t, getHighSchema(t, `type: string
+nullable: false`).IsNullable())
+	assert.True(t, getHighSchema(t, `type: [string, "null"]`).IsNullable())
+	assert.False(t, getHighSchema(t, `type: [string, integer]`).IsNullable())
+	assert.False(t, getHighSchema(t, `type: string`).IsNullable())
+}
+
+func TestSchema_AllowsNull(t *testing.T) {
+	sch := getHighSchema(t, `type: null`)
+	assert.Equal(t, []string{"null"}, sch.Type)
+	assert.True(t, sch.AllowsNull())
+	assert.Empty(t, sch.Validate(nil))
+	errs := sch.Validate("pizza")
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "/: expected type 'null', got 'string'", errs[0].Error())
+
+	assert.True(t, getHighSchema(t, `type: [string, ~]`).AllowsNull())
+	assert.True(t, getHighSchema(t, `enum: [pizza, null]`).AllowsNull())
+	assert.True(t, getHighSchema(t, `oneOf:
+  - type: string
+  - type: "null"`).AllowsNull())
+	assert.True(t, getHighSchema(t, `type: string
+nullable: true`).AllowsNull())
+
+	// no type accepts null, but doesn't say so.
+	absent := getHighSchema(t, `description: anything`)
+	assert.Nil(t, absent.Type)
+	assert.False(t, absent.AllowsNull())
+	assert.Empty(t, absent.Validate(nil))
+	assert.False(t, getHighSchema(t, `type: string`).AllowsNull())
+
+	// a type left blank is no type at all, rather than the null type.
+	blank := getHighSchema(t, `type:
+minLength: 2`)
+	assert.Nil(t, blank.Type)
+	assert.False(t, blank.AllowsNull())
+	assert.Empty(t, blank.Validate(nil))
+	assert.Empty(t, blank.Validate(int64(5)))
+	assert.Len(t, blank.Validate("a"), 1)
+}
+
+func TestNewSchemaFromNode(t *testing.T) {
+	scalar := func(v string) *yaml.Node {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+	}
+	mapping := func(content ...*yaml.Node) *yaml.Node {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: content}
+	}
+	node := mapping(
+		scalar("type"), scalar("object"),
+		scalar("required"), &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{scalar("name")}},
+		scalar("properties"), mapping(
+			scalar("name"), mapping(scalar("type"), scalar("string")),
+			scalar("home"), mapping(scalar("$ref"), scalar("#/$defs/Address")),
+		),
+		scalar("$defs"), mapping(
+			scalar("Address"), mapping(scalar("type"), scalar("string"), scalar("maxLength"),
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: "100"}),
+		),
+	)
+
+	sch, err := NewSchemaFromNode(node)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"object"}, sch.Type)
+	assert.Equal(t, []string{"name"}, sch.Required)
+	assert.Equal(t, []string{"string"}, sch.Properties.GetOrZero("name").Schema().Type)
+	home := sch.Properties.GetOrZero("home")
+	assert.True(t, home.IsReference())
+	assert.Equal(t, int64(100), *home.Schema().MaxLength)
+	assert.Same(t, node.Content[1], sch.GoLow().Type.ValueNode)
+
+	doc := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{node}}
+	sch, err = NewSchemaFromNode(doc)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"object"}, sch.Type)
+
+	_, err = NewSchemaFromNode(nil)
+	assert.EqualError(t, err, "cannot build schema: node is empty")
+	_, err = NewSchemaFromNode(&yaml.Node{Kind: yaml.SequenceNode, Line: 2, Column: 3})
+	assert.EqualError(t, err, "cannot build schema: expected a mapping at line 2, column 3")
+}
+
+func TestSchema_NonFiniteBounds(t *testing.T) {
+	sch := getHighSchema(t, `type: number
+maximum: .inf
+minimum: -.inf
+multipleOf: .nan`)
+
+	assert.Nil(t, sch.Maximum)
+	assert.Nil(t, sch.Minimum)
+	assert.Nil(t, sch.MultipleOf)
+	assert.True(t, sch.HasFiniteBounds())
+
+	warnings := sch.Warnings()
+	assert.Len(t, warnings, 3)
+	assert.Equal(t, "/multipleOf", warnings[0].Path)
+	assert.Equal(t, "'multipleOf' is not a finite number (NaN), the constraint has been dropped", warnings[0].Message)
+	assert.Equal(t, "/maximum", warnings[1].Path)
+	assert.Equal(t, 2, warnings[1].Line)
+	assert.Equal(t, "/minimum", warnings[2].Path)
+
+	// the validator is not broken by the dropped constraints.
+	assert.Empty(t, sch.Validate(12.5))
+
+	sch = getHighSchema(t, `type: number
+maximum: 10`)
+	assert.True(t, sch.HasFiniteBounds())
+	inf := math.Inf(1)
+	sch.Maximum = &inf
+	assert.False(t, sch.HasFiniteBounds())
+	sch.Maximum = nil
+	sch.ExclusiveMinimum = &DynamicValue[bool, float64]{N: 1, B: math.NaN()}
+	assert.False(t, sch.HasFiniteBounds())
+}
+
+func TestSchema_Render_Deterministic(t *testing.T) {
+	yml := `type: object
+x-zebra: stripes
+x-aardvark: ants
+properties:
+  zulu:
+    type: string
+  alpha:
+    type: integer
+  mike:
+    type: boolean`
+
+	render := func() ([]byte, []byte) {
+		sch := getHighSchema(t, yml)
+		// new values have no position in the original document.
+		sch.Title = "pizza"
+		sch.Description = "a pizza"
+		sch.Extensions.Set("x-new", utils.CreateStringNode("value"))
+		y, err := sch.Render()
+		assert.NoError(t, err)
+		j, err := sch.RenderJSON("  ")
+		assert.NoError(t, err)
+		return y, j
+	}
+
+	y, j := render()
+	for i := 0; i < 20; i++ {
+		y2, j2 := render()
+		assert.Equal(t, y, y2)
+		assert.Equal(t, j, j2)
+	}
+
+	js := string(j)
+	assert.Less(t, strings.Index(js, `"title"`), strings.Index(js, `"description"`))
+	assert.Less(t, strings.Index(js, `"zulu"`), strings.Index(js, `"alpha"`))
+	assert.Less(t, strings.Index(js, `"alpha"`), strings.Index(js, `"mike"`))
+	assert.Less(t, strings.Index(js, `"x-zebra"`), strings.Index(js, `"x-aardvark"`))
+	assert.Less(t, strings.Index(js, `"x-aardvark"`), strings.Index(js, `"x-new"`))
+}
+
+func TestSchema_EnumNodes(t *testing.T) {
+	yml := `type: string
+enum:
+  - pepperoni # the best
+  - 'cheese'
+  - "ham"`
+
+	highSchema := getHighSchema(t, yml)
+	en := highSchema.EnumNodes()
+	assert.Len(t, en, len(highSchema.Enum))
+	assert.Equal(t, "pepperoni", en[0].Value)
+	assert.Equal(t, "# the best", en[0].LineComment)
+	assert.Equal(t, yaml.SingleQuotedStyle, en[1].Style)
+	assert.Equal(t, 5, en[2].Line)
+
+	assert.Empty(t, getHighSchema(t, `type: string`).EnumNodes())
+	assert.Len(t, (&Schema{Enum: []*yaml.Node{utils.CreateStringNode("a")}}).EnumNodes(), 1)
+}
+
+func TestSchema_YAMLAliases(t *testing.T) {
+	yml := `type: object
+properties:
+  home: &address
+    type: object
+    required: [street]
+    properties:
+      street:
+        type: string
+  work: *address
+  holiday: *address`
+
+	sch := getHighSchema(t, yml)
+	home := sch.Properties.GetOrZero("home").Schema()
+	for _, name := range []string{"work", "holiday"} {
+		aliased, err := sch.Properties.GetOrZero(name).BuildSchema()
+		assert.NoError(t, err)
+		assert.Equal(t, home.Type, aliased.Type)
+		assert.Equal(t, home.Required, aliased.Required)
+		assert.Equal(t, home.GoLow().Hash(), aliased.GoLow().Hash())
+
+		homeBytes, _ := home.Render()
+		aliasedBytes, _ := aliased.Render()
+		assert.Equal(t, string(homeBytes), string(aliasedBytes))
+	}
+
+	// aliases that aren't recursive are expanded in JSON.
+	j, err := sch.RenderJSONMinified()
+	assert.NoError(t, err)
+	address := `{"type":"object","required":["street"],"properties":{"street":{"type":"string"}}}`
+	assert.Equal(t, `{"type":"object","properties":{"home":`+address+`,"work":`+address+`,"holiday":`+address+`}}`, string(j))
+
+	errs := sch.Validate(map[string]any{"work": map[string]any{"street": 1}})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "/work/street", errs[0].Path)
+}
+
+func TestSchema_RecursiveYAMLAlias(t *testing.T) {
+	yml := `type: object
+properties:
+  tree: &node
+    type: object
+    properties:
+      name:
+        type: string
+      child: *node`
+
+	sch := getHighSchema(t, yml)
+	tree := sch.Properties.GetOrZero("tree").Schema()
+	child := tree.Properties.GetOrZero("child").Schema()
+	grandChild := child.Properties.GetOrZero("child").Schema()
+	assert.Equal(t, []string{"object"}, grandChild.Type)
+	assert.Equal(t, 2, grandChild.Properties.Len())
+
+	// hashing and rendering must not loop forever.
+	assert.NotEqual(t, [32]byte{}, sch.GoLow().Hash())
+	rendered, err := sch.Render()
+	assert.NoError(t, err)
+	assert.Equal(t, yml, strings.TrimSpace(strings.ReplaceAll(string(rendered), "    ", "  ")))
+
+	// JSON can't hold the alias, rendering it returns an error rather than panicking or never ending.
+	_, err = sch.RenderJSON("  ")
+	assert.EqualError(t, err, "alias '*node' is recursive and cannot be converted to json")
+	_, err = sch.RenderJSONMinified()
+	assert.Error(t, err)
+	var buf bytes.Buffer
+	assert.Error(t, sch.RenderJSONTo(&buf))
+
+	errs := sch.Validate(map[string]any{"tree": map[string]any{"child": map[string]any{"child": map[string]any{"name": 1}}}})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "/tree/child/child/name", errs[0].Path)
+	assert.True(t, tree.IsSubsetOf(child))
+}
+
+func TestSchema_ExtensionKeys(t *testing.T) {
+	yml := `type: object
+x-zebra: stripes
+description: pizza
+x-apple: red
+x-mango: sweet`
+
+	sch := getHighSchema(t, yml)
+	assert.Equal(t, []string{"x-zebra", "x-apple", "x-mango"}, sch.ExtensionKeys())
+
+	rend, err := sch.Render()
+	assert.NoError(t, err)
+	out := string(rend)
+	assert.Less(t, strings.Index(out, "x-zebra"), strings.Index(out, "description"))
+	assert.Less(t, strings.Index(out, "description"), strings.Index(out, "x-apple"))
+	assert.Less(t, strings.Index(out, "x-apple"), strings.Index(out, "x-mango"))
+
+	built := &Schema{Extensions: orderedmap.New[string, *yaml.Node]()}
+	built.Extensions.Set("x-b", utils.CreateStringNode("b"))
+	built.Extensions.Set("x-a", utils.CreateStringNode("a"))
+	assert.Equal(t, []string{"x-b", "x-a"}, built.ExtensionKeys())
+}
+
+func TestSchema_Property(t *testing.T) {
+	sch := getHighSchema(t, `type: object
+properties:
+  name:
+    type: string
+  age:
+    type: integer`)
+
+	age, ok := sch.Property("age")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"integer"}, age.Type)
+
+	// only the property asked for is built.
+	_, built := sch.Properties.GetOrZero("name").Resolved()
+	assert.False(t, built)
+	again, _ := sch.Property("age")
+	assert.Same(t, age, again)
+
+	_, ok = sch.Property("height")
+	assert.False(t, ok)
+	_, ok = (&Schema{}).Property("age")
+	assert.False(t, ok)
+}
+
+// wideSchema returns a low-level object schema with the number of properties given, each with a few constraints.
+func wideSchema(b *testing.B, properties int) *lowbase.Schema {
+	var yml strings.Builder
+	yml.WriteString("type: object\nproperties:\n")
+	for i := 0; i < properties; i++ {
+		fmt.Fprintf(&yml, "  prop%d:\n    type: string\n    maxLength: %d\n    description: property %d\n", i, i, i)
+	}
+	var node yaml.Node
+	_ = yaml.Unmarshal([]byte(yml.String()), &node)
+	var lowSchema lowbase.Schema
+	if err := low.BuildModel(node.Content[0], &lowSchema); err != nil {
+		b.Fatal(err)
+	}
+	if err := lowSchema.Build(context.Background(), node.Content[0], nil); err != nil {
+		b.Fatal(err)
+	}
+	return &lowSchema
+}
+
+func BenchmarkSchema_Property(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sch := NewSchema(wideSchema(b, 500))
+		if _, ok := sch.Property("prop250"); !ok {
+			b.Fatal("prop250 not found")
+		}
+	}
+}
+
+func BenchmarkSchema_Property_BuildAll(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sch := NewSchema(wideSchema(b, 500))
+		if err := sch.BuildAll(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestSchema_SourcePath(t *testing.T) {
+	yml := `components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name:
+          type: string`
+
+	var node yaml.Node
+	assert.NoError(t, yaml.Unmarshal([]byte(yml), &node))
+	cfg := index.CreateClosedAPIIndexConfig()
+	cfg.SpecAbsolutePath = "/specs/pets.yaml"
+	idx := index.NewSpecIndexWithConfig(&node, cfg)
+	_, pet := utils.FindKeyNodeTop("Pet", node.Content[0].Content[1].Content[1].Content)
+
+	var lowSchema lowbase.Schema
+	assert.NoError(t, low.BuildModel(pet, &lowSchema))
+	assert.NoError(t, lowSchema.Build(context.Background(), pet, idx))
+	sch := NewSchema(&lowSchema)
+	assert.Equal(t, "/specs/pets.yaml", sch.SourcePath())
+	assert.Equal(t, "/specs/pets.yaml", sch.Properties.GetOrZero("name").Schema().SourcePath())
+
+	// a schema from another file reports that file.
+	files := map[string]string{
+		"schemas/pet.yaml": `type: object
+properties:
+  owner:
+    $ref: '../people.yaml#/Person'`,
+		"people.yaml": `Person:
+  type: string`,
+	}
+	external, err := CreateSchemaProxyRef("schemas/pet.yaml").ResolveExternal(stubLoader(files, map[string]int{}))
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.FromSlash("/schemas/pet.yaml"), external.SourcePath())
+	assert.Equal(t, filepath.FromSlash("/people.yaml"), external.Properties.GetOrZero("owner").Schema().SourcePath())
+
+	assert.Empty(t, getHighSchema(t, `type: string`).SourcePath())
+	assert.Empty(t, (&Schema{}).SourcePath())
+}
+
+func TestSchema_GoTypeOverride(t *testing.T) {
+	sch := getHighSchema(t, `type: string
+format: uuid
+x-go-type: uuid.UUID
+x-go-type-import:
+  name: uuid
+  path: github.com/google/uuid`)
+	name, path, ok := sch.GoTypeOverride()
+	assert.True(t, ok)
+	assert.Equal(t, "uuid.UUID", name)
+	assert.Equal(t, "github.com/google/uuid", path)
+
+	sch = getHighSchema(t, `type: string
+x-go-type: decimal.Decimal
+x-go-type-import: github.com/shopspring/decimal`)
+	name, path, ok = sch.GoTypeOverride()
+	assert.True(t, ok)
+	assert.Equal(t, "decimal.Decimal", name)
+	assert.Equal(t, "github.com/shopspring/decimal", path)
+
+	sch = getHighSchema(t, `type: string
+x-go-type: time.Duration`)
+	name, path, ok = sch.GoTypeOverride()
+	assert.True(t, ok)
+	assert.Equal(t, "time.Duration", name)
+	assert.Empty(t, path)
+
+	_, _, ok = getHighSchema(t, `type: string
+x-go-type-import: github.com/google/uuid`).GoTypeOverride()
+	assert.False(t, ok)
+	_, _, ok = (&Schema{}).GoTypeOverride()
+	assert.False(t, ok)
+}
+
+func TestSchema_DecimalPrecision(t *testing.T) {
+	precision, scale, ok := getHighSchema(t, `type: number
+format: decimal
+x-precision: 18
+x-scale: 4`).DecimalPrecision()
+	assert.True(t, ok)
+	assert.Equal(t, 18, precision)
+	assert.Equal(t, 4, scale)
+
+	precision, scale, ok = getHighSchema(t, `type: number
+format: decimal
+multipleOf: 0.01`).DecimalPrecision()
+	assert.True(t, ok)
+	assert.Equal(t, 0, precision)
+	assert.Equal(t, 2, scale)
+
+	// an explicit scale wins over the multipleOf hint.
+	_, scale, _ = getHighSchema(t, `type: number
+multipleOf: 0.01
+x-scale: 6`).DecimalPrecision()
+	assert.Equal(t, 6, scale)
+
+	_, _, ok = getHighSchema(t, `type: number
+multipleOf: 5
+x-precision: lots`).DecimalPrecision()
+	assert.False(t, ok)
+}
+
+func TestSchema_IsFreeFormObject(t *testing.T) {
+	assert.True(t, getHighSchema(t, `type: object
+additionalProperties: true`).IsFreeFormObject())
+	assert.True(t, getHighSchema(t, `type: object`).IsFreeFormObject())
+	assert.True(t, getHighSchema(t, `type: object
+additionalProperties: {}`).IsFreeFormObject())
+
+	assert.False(t, getHighSchema(t, `type: object
+properties:
+  name:
+    type: string`).IsFreeFormObject())
+	assert.False(t, getHighSchema(t, `type: object
+additionalProperties:
+  type: string`).IsFreeFormObject())
+	assert.False(t, getHighSchema(t, `type: object
+additionalProperties: false`).IsFreeFormObject())
+	assert.False(t, getHighSchema(t, `type: string`).IsFreeFormObject())
+}
+
+func TestSchema_IsAnyType(t *testing.T) {
+	assert.True(t, getHighSchema(t, `{}`).IsAnyType())
+	assert.True(t, getHighSchema(t, `description: anything goes`).IsAnyType())
+	assert.True(t, getHighSchema(t, `title: pizza
+example: 12
+x-pizza: hot`).IsAnyType())
+	assert.False(t, getHighSchema(t, `type: object`).IsAnyType())
+	assert.False(t, getHighSchema(t, `minimum: 1`).IsAnyType())
+	assert.False(t, getHighSchema(t, `oneOf:
+  - type: string`).IsAnyType())
+	assert.False(t, getHighSchema(t, `properties:
+  name:
+    type: string`).IsAnyType())
+	assert.False(t, (*Schema)(nil).IsAnyType())
+}
+
+func TestSchema_JSONType(t *testing.T) {
+	types := map[string]string{
+		`type: string`:                "string",
+		`type: [integer, "null"]`:     "integer",
+		`type: ["null"]`:              "null",
+		`type: [string, integer]`:     "",
+		"properties:\n  name: {}":     "object",
+		"additionalProperties: false": "object",
+		"items:\n  type: string":      "array",
+		`maxLength: 10`:               "string",
+		`minimum: 1`:                  "number",
+		`const: true`:                 "boolean",
+		`enum: [1, 2, 3]`:             "integer",
+		`enum: [pizza, 2]`:            "",
+		`description: no type at all`: "",
+	}
+	for yml, expected := range types {
+		assert.Equal(t, expected, getHighSchema(t, yml).JSONType(), yml)
+	}
+}
+
+func TestSchema_RawMaximum(t *testing.T) {
+	sch := getHighSchema(t, `type: number
+maximum: 1e3
+minimum: 1.0`)
+
+	assert.Equal(t, 1000.0, *sch.Maximum)
+	assert.Equal(t, "1e3", sch.RawMaximum())
+	assert.Equal(t, "1.0", sch.RawMinimum())
+
+	rend, err := sch.Render()
+	assert.NoError(t, err)
+	assert.Equal(t, "type: number\nmaximum: 1e3\nminimum: 1.0\n", string(rend))
+
+	// a changed value is rendered as the new value.
+	changed := 500.0
+	sch.Maximum = &changed
+	assert.Equal(t, "500", sch.RawMaximum())
+	rend, _ = sch.Render()
+	assert.Contains(t, string(rend), "maximum: 500\n")
+
+	fraction := 2.5
+	sch.Minimum = &fraction
+	rend, _ = sch.Render()
+	assert.Contains(t, string(rend), "minimum: 2.5\n")
+
+	assert.Empty(t, getHighSchema(t, `type: number`).RawMaximum())
+	built := &Schema{Minimum: &changed}
+	assert.Equal(t, "500", built.RawMinimum())
+}
+
+func TestSchema_AdditionalPropertiesSchema(t *testing.T) {
+	sch := getHighSchema(t, `type: object
+additionalProperties:
+  type: object
+  required: [price]
+  properties:
+    price:
+      type: number
+      minimum: 0`)
+
+	value := sch.AdditionalPropertiesSchema()
+	assert.NotNil(t, value)
+	assert.Equal(t, []string{"object"}, value.Type)
+	assert.Equal(t, []string{"price"}, value.Required)
+	assert.Equal(t, 0.0, *value.Properties.GetOrZero("price").Schema().Minimum)
+
+	assert.Nil(t, getHighSchema(t, "type: object\nadditionalProperties: false").AdditionalPropertiesSchema())
+	assert.Nil(t, getHighSchema(t, "type: object\nadditionalProperties: true").AdditionalPropertiesSchema())
+	assert.Nil(t, getHighSchema(t, "type: object").AdditionalPropertiesSchema())
+}
+
+func TestSchema_RenderYAMLTo_RenderJSONTo(t *testing.T) {
+	sch := getHighSchema(t, `type: object
+description: a pizza <with> toppings
+required: [name]
+properties:
+  name:
+    type: string
+    enum: [margherita, pepperoni]
+  slices:
+    type: integer
+    maximum: 12
+  toppings:
+    type: array
+    items:
+      type: string`)
+
+	expected, err := sch.Render()
+	assert.NoError(t, err)
+	var buf bytes.Buffer
+	assert.NoError(t, sch.RenderYAMLTo(&buf))
+	assert.Equal(t, string(expected), buf.String())
+
+	expected, err = sch.RenderJSON("  ")
+	assert.NoError(t, err)
+	buf.Reset()
+	assert.NoError(t, sch.RenderJSONTo(&buf))
+	assert.Equal(t, string(expected), buf.String())
+}
+
+func TestSchema_RenderJSONMinified(t *testing.T) {
+	sch := getHighSchema(t, `# a comment
+type: object
+description: a pizza with toppings
+required: [name]
+properties:
+  name:
+    type: string # trailing comment
+    enum: [margherita, pepperoni]
+  toppings:
+    type: array
+    items:
+      type: string`)
+
+	minified, err := sch.RenderJSONMinified()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"type":"object","description":"a pizza with toppings","required":["name"],`+
+		`"properties":{"name":{"type":"string","enum":["margherita","pepperoni"]},`+
+		`"toppings":{"type":"array","items":{"type":"string"}}}}`, string(minified))
+
+	// parses back to the same schema.
+	rendered, _ := sch.Render()
+	var original, parsed map[string]any
+	assert.NoError(t, yaml.Unmarshal(rendered, &original))
+	assert.NoError(t, yaml.Unmarshal(minified, &parsed))
+	assert.Equal(t, original, parsed)
+
+	again, _ := sch.RenderJSONMinified()
+	assert.Equal(t, minified, again)
+}
+
+func TestSchema_RenderJSON_Error(t *testing.T) {
+	// a recursive alias can't be expanded, so there is no JSON for it.
+	sch := getHighSchema(t, `type: object
+properties:
+  tree: &node
+    type: object
+    properties:
+      child: *node`)
+
+	rendered, err := sch.RenderJSON("  ")
+	assert.Error(t, err)
+	assert.Nil(t, rendered)
+
+	rendered, err = sch.RenderJSONMinified()
+	assert.Error(t, err)
+	assert.Nil(t, rendered)
+}
+
+func TestSchema_SourceStyle(t *testing.T) {
+	sch := buildComponentSchema(t, `components:
+  schemas:
+    Pet: {type: object, properties: {id: {type: string}}, required: [id]}`, "Pet")
+	assert.Equal(t, yaml.FlowStyle, sch.SourceStyle())
+
+	rendered, err := sch.Render()
+	assert.NoError(t, err)
+	assert.Equal(t, "{type: object, properties: {id: {type: string}}, required: [id]}\n", string(rendered))
+
+	block := buildComponentSchema(t, `components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        id:
+          type: string
+      required:
+        - id
+      enum: [a, b]`, "Pet")
+	assert.Equal(t, yaml.Style(0), block.SourceStyle())
+	rendered, _ = block.Render()
+	assert.Equal(t, `type: object
+properties:
+    id:
+        type: string
+required:
+    - id
+enum: [a, b]
+`, string(rendered))
+
+	assert.Equal(t, yaml.Style(0), (&Schema{Type: []string{"string"}}).SourceStyle())
+
+	// JSON is flow style too, it still renders as block style YAML.
+	json := buildComponentSchema(t, `{"components": {"schemas": {"Pet": {"type": "object", "required": ["id"]}}}}`, "Pet")
+	assert.Equal(t, yaml.FlowStyle, json.SourceStyle())
+	rendered, _ = json.Render()
+	assert.Equal(t, `type: "object"
+required:
+    - "id"
+`, string(rendered))
+}
+
+func TestSchema_NullablePaths(t *testing.T) {
+	sch := buildComponentSchema(t, `components:
+  schemas:
+    Person:
+      type: object
+      properties:
+        name:
+          type: string
+        nickname:
+          type: string
+          nullable: true
+        age:
+          type: [integer, "null"]
+        nothing:
+          type: "null"
+        home:
+          $ref: '#/components/schemas/Address'
+        work:
+          $ref: '#/components/schemas/Address'
+        friends:
+          type: array
+          items:
+            $ref: '#/components/schemas/Person'
+    Address:
+      type: object
+      properties:
+        street:
+          type: string
+        flat:
+          type: [string, "null"]`, "Person")
+
+	assert.Equal(t, []string{
+		"/properties/nickname",
+		"/properties/age",
+		"/properties/nothing",
+		"/properties/home/properties/flat",
+		"/properties/work/properties/flat",
+	}, sch.NullablePaths())
+
+	assert.Equal(t, []string{""}, getHighSchema(t, `type: [object, "null"]`).NullablePaths())
+	assert.Empty(t, getHighSchema(t, `type: string`).NullablePaths())
+}
+
+func TestSchema_Snapshot(t *testing.T) {
+	yml := `type: object
+# the person
+required: [name, age]
+x-zebra: {stripes: 12}
+x-apple: "red"
+properties:
+  name:
+    type: string
+    enum: ['123', "abc"]
+  age:
+    type: integer
+  tags:
+    type: array
+    items: {type: string, maxLength: 5}
+  address:
+    type: object
+    properties:
+      street:
+        type: string
+      city:
+        type: string`
+
+	expected := getHighSchema(t, yml).Snapshot()
+	assert.Equal(t, `type: object
+required:
+  - name
+  - age
+x-zebra:
+  stripes: 12
+x-apple: red
+properties:
+  name:
+    type: string
+    enum:
+      - "123"
+      - abc
+  age:
+    type: integer
+  tags:
+    type: array
+    items:
+      type: string
+      maxLength: 5
+  address:
+    type: object
+    properties:
+      street:
+        type: string
+      city:
+        type: string
+`, expected)
+
+	// children are built concurrently, the snapshot never changes.
+	for i := 0; i < 50; i++ {
+		assert.Equal(t, expected, getHighSchema(t, yml).Snapshot())
+	}
+}
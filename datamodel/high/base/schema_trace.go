@@ -0,0 +1,55 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"context"
+
+	"github.com/pb33f/libopenapi/datamodel/low/base"
+)
+
+// SchemaTracer starts spans that trace how a schema is built, it's deliberately small so that an OpenTelemetry
+// trace.Tracer (or any other tracer) can be adapted to it in a few lines, without this module depending on it.
+type SchemaTracer interface {
+	// Start starts a new span, a child of any span in the context, and returns a context holding the new span.
+	Start(ctx context.Context, name string) (context.Context, SchemaSpan)
+}
+
+// SchemaSpan is a span started by a SchemaTracer.
+type SchemaSpan interface {
+	End()
+}
+
+type schemaTracerKey struct{}
+
+// ContextWithSchemaTracer returns a copy of the context holding a SchemaTracer, used by NewSchemaWithContext.
+func ContextWithSchemaTracer(ctx context.Context, tracer SchemaTracer) context.Context {
+	return context.WithValue(ctx, schemaTracerKey{}, tracer)
+}
+
+// NewSchemaWithContext will create a new high-level schema from a low-level one, in the same way as NewSchema. If
+// the context holds a SchemaTracer (see ContextWithSchemaTracer) a 'schema.build' span is started around the build,
+// with child spans for the fan-out of properties ('schema.build.properties') and each composition
+// ('schema.build.allOf', 'schema.build.oneOf', 'schema.build.anyOf' and 'schema.build.prefixItems'). Child schemas
+// are built lazily, when they are first used, so they are not part of the trace.
+func NewSchemaWithContext(ctx context.Context, schema *base.Schema) *Schema {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return newSchema(ctx, schema, nil)
+}
+
+// noopSpan is used when there is no tracer.
+type noopSpan struct{}
+
+func (noopSpan) End() {}
+
+// startSchemaSpan starts a span using the SchemaTracer held by the context, if there is no tracer nothing is done.
+func startSchemaSpan(ctx context.Context, name string) (context.Context, SchemaSpan) {
+	tracer, ok := ctx.Value(schemaTracerKey{}).(SchemaTracer)
+	if !ok || tracer == nil {
+		return ctx, noopSpan{}
+	}
+	return tracer.Start(ctx, name)
+}
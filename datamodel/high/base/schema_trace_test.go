@@ -0,0 +1,82 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/pb33f/libopenapi/datamodel/low"
+	lowbase "github.com/pb33f/libopenapi/datamodel/low/base"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+type stubSpanKey struct{}
+
+// stubTracer records every span started, as 'parent > name', and every span ended.
+type stubTracer struct {
+	lock    sync.Mutex
+	started []string
+	ended   int
+}
+
+type stubSpan struct {
+	tracer *stubTracer
+}
+
+func (s *stubSpan) End() {
+	s.tracer.lock.Lock()
+	defer s.tracer.lock.Unlock()
+	s.tracer.ended++
+}
+
+func (t *stubTracer) Start(ctx context.Context, name string) (context.Context, SchemaSpan) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if parent, ok := ctx.Value(stubSpanKey{}).(string); ok {
+		t.started = append(t.started, parent+" > "+name)
+	} else {
+		t.started = append(t.started, name)
+	}
+	return context.WithValue(ctx, stubSpanKey{}, name), &stubSpan{tracer: t}
+}
+
+func TestNewSchemaWithContext(t *testing.T) {
+	yml := `type: object
+properties:
+  name:
+    type: string
+allOf:
+  - type: object
+oneOf:
+  - type: string
+  - type: integer`
+
+	var node yaml.Node
+	_ = yaml.Unmarshal([]byte(yml), &node)
+	var lowSchema lowbase.Schema
+	_ = low.BuildModel(node.Content[0], &lowSchema)
+	_ = lowSchema.Build(context.Background(), node.Content[0], nil)
+
+	tracer := new(stubTracer)
+	sch := NewSchemaWithContext(ContextWithSchemaTracer(context.Background(), tracer), &lowSchema)
+	assert.Equal(t, []string{"object"}, sch.Type)
+	assert.Len(t, sch.OneOf, 2)
+
+	slices.Sort(tracer.started)
+	assert.Equal(t, []string{
+		"schema.build",
+		"schema.build > schema.build.allOf",
+		"schema.build > schema.build.oneOf",
+		"schema.build > schema.build.properties",
+	}, tracer.started)
+	assert.Equal(t, 4, tracer.ended)
+
+	// without a tracer, nothing is traced.
+	sch = NewSchemaWithContext(context.Background(), &lowSchema)
+	assert.Equal(t, []string{"object"}, sch.Type)
+}
@@ -0,0 +1,295 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"strconv"
+
+	"github.com/pb33f/libopenapi/orderedmap"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// schemaTransform is called for every schema in a tree by transform. It gets a copy of the schema (which it's free
+// to change) and a JSON Pointer to the schema from the root of the tree.
+type schemaTransform func(sch *Schema, path string)
+
+// transform returns a copy of the schema tree, every inline child schema is copied and handed to fn, parents before
+// their children. The original tree is not modified. References and boolean schemas are kept as they are, and
+// circular children (recursive YAML aliases) are only copied once. Children are read from the copy after fn has run,
+// so a child removed by fn is not visited.
+func (s *Schema) transform(fn schemaTransform) *Schema {
+	return s.transformPath(fn, "", nil)
+}
+
+func (s *Schema) transformPath(fn schemaTransform, path string, active []any) *Schema {
+	if s == nil {
+		return nil
+	}
+	active = append(active, schemaKey(s))
+	d := *s
+	fn(&d, path)
+
+	child := func(sp *SchemaProxy, childPath string) *SchemaProxy {
+		if sp == nil || sp.IsReference() {
+			return sp
+		}
+		if _, isBool := sp.IsBooleanSchema(); isBool {
+			return sp
+		}
+		sch := sp.Schema()
+		if sch == nil || slices.Contains(active, schemaKey(sch)) {
+			return sp
+		}
+		return CreateSchemaProxy(sch.transformPath(fn, childPath, active))
+	}
+	list := func(proxies []*SchemaProxy, keyword string) []*SchemaProxy {
+		if proxies == nil {
+			return nil
+		}
+		transformed := make([]*SchemaProxy, len(proxies))
+		for i, sp := range proxies {
+			transformed[i] = child(sp, path+"/"+keyword+"/"+strconv.Itoa(i))
+		}
+		return transformed
+	}
+	mapped := func(m *orderedmap.Map[string, *SchemaProxy], keyword string) *orderedmap.Map[string, *SchemaProxy] {
+		if m == nil {
+			return nil
+		}
+		transformed := orderedmap.New[string, *SchemaProxy]()
+		for pair := orderedmap.First(m); pair != nil; pair = pair.Next() {
+			transformed.Set(pair.Key(), child(pair.Value(), path+"/"+keyword+"/"+escapePointer(pair.Key())))
+		}
+		return transformed
+	}
+	dynamic := func(dv *DynamicValue[*SchemaProxy, bool], keyword string) *DynamicValue[*SchemaProxy, bool] {
+		if dv == nil || !dv.IsA() {
+			return dv
+		}
+		return &DynamicValue[*SchemaProxy, bool]{A: child(dv.A, path+"/"+keyword)}
+	}
+
+	d.AllOf = list(d.AllOf, "allOf")
+	d.OneOf = list(d.OneOf, "oneOf")
+	d.AnyOf = list(d.AnyOf, "anyOf")
+	d.PrefixItems = list(d.PrefixItems, "prefixItems")
+	d.Not = child(d.Not, path+"/not")
+	d.Contains = child(d.Contains, path+"/contains")
+	d.If = child(d.If, path+"/if")
+	d.Then = child(d.Then, path+"/then")
+	d.Else = child(d.Else, path+"/else")
+	d.PropertyNames = child(d.PropertyNames, path+"/propertyNames")
+	d.UnevaluatedItems = child(d.UnevaluatedItems, path+"/unevaluatedItems")
+	d.Items = dynamic(d.Items, "items")
+	d.AdditionalProperties = dynamic(d.AdditionalProperties, "additionalProperties")
+	d.UnevaluatedProperties = dynamic(d.UnevaluatedProperties, "unevaluatedProperties")
+	d.Properties = mapped(d.Properties, "properties")
+	d.PatternProperties = mapped(d.PatternProperties, "patternProperties")
+	d.DependentSchemas = mapped(d.DependentSchemas, "dependentSchemas")
+	return &d
+}
+
+// StripAnnotations returns a copy of the schema tree with the annotations (title, description, example, examples
+// and externalDocs) removed from every inline schema, to reduce the size of a schema that is shipped to a browser
+// for example. Validation keywords are untouched, and the original tree is not modified. Referenced schemas are not
+// copied, so they keep their annotations. '$comment' does not need to be stripped, it's not part of the model and
+// is never rendered.
+func (s *Schema) StripAnnotations() *Schema {
+	return s.transform(func(sch *Schema, _ string) {
+		sch.Title = ""
+		sch.Description = ""
+		sch.Example = nil
+		sch.Examples = nil
+		sch.ExternalDocs = nil
+	})
+}
+
+// SchemaContext is the direction a schema is used in, see Schema.ForContext.
+type SchemaContext int
+
+const (
+	// Request is a schema describing a payload sent to the API, readOnly properties are not part of it.
+	Request SchemaContext = iota
+
+	// Response is a schema describing a payload returned by the API, writeOnly properties are not part of it.
+	Response
+)
+
+// ForContext returns a copy of the schema tree projected for a request or a response. In the Request projection
+// every readOnly property is removed, in the Response projection every writeOnly property is removed, in this schema
+// and every inline child schema. A removed property is also removed from 'required', it can never be sent (or
+// returned) so it cannot be required. This is the projection a code generator uses to create separate request and
+// response types from one schema. The original tree is not modified.
+//
+// A property that is a reference is removed if the schema it points to is readOnly (or writeOnly), but referenced
+// schemas are not copied, so properties inside them are left alone.
+func (s *Schema) ForContext(ctx SchemaContext) *Schema {
+	excluded := func(sp *SchemaProxy) bool {
+		if _, isBool := sp.IsBooleanSchema(); isBool {
+			return false
+		}
+		sch := sp.Schema()
+		if sch == nil {
+			return false
+		}
+		if ctx == Request {
+			return sch.ReadOnly != nil && *sch.ReadOnly
+		}
+		return sch.WriteOnly != nil && *sch.WriteOnly
+	}
+	return s.transform(func(sch *Schema, _ string) {
+		if orderedmap.Len(sch.Properties) == 0 {
+			return
+		}
+		kept := orderedmap.New[string, *SchemaProxy]()
+		var removed []string
+		for pair := orderedmap.First(sch.Properties); pair != nil; pair = pair.Next() {
+			if pair.Value() != nil && excluded(pair.Value()) {
+				removed = append(removed, pair.Key())
+				continue
+			}
+			kept.Set(pair.Key(), pair.Value())
+		}
+		if len(removed) == 0 {
+			return
+		}
+		sch.Properties = kept
+		if len(sch.Required) > 0 {
+			sch.Required = slices.DeleteFunc(slices.Clone(sch.Required), func(r string) bool {
+				return slices.Contains(removed, r)
+			})
+		}
+	})
+}
+
+// CollapseSingletons returns a copy of the schema tree, with every allOf, oneOf or anyOf that has a single member
+// folded into that member. A composition of one is the member itself, so this removes a layer of indirection a
+// code generator would otherwise turn into a wrapper type. The original tree is not modified.
+//
+// A schema is only collapsed when the composition is all it holds apart from annotations (title, description,
+// default, examples, deprecated, readOnly, writeOnly, xml, externalDocs, nullable and extensions), those are
+// merged into the member, and win over the same annotations in the member. A member that is a reference is kept,
+// so the name of the referenced type is not lost.
+func (s *Schema) CollapseSingletons() *Schema {
+	return s.transform(func(sch *Schema, _ string) {
+		seen := make(map[any]bool)
+		for {
+			member := singletonMember(sch)
+			if member == nil || member.IsReference() {
+				return
+			}
+			if _, isBool := member.IsBooleanSchema(); isBool {
+				return
+			}
+			m := member.Schema()
+			if m == nil || seen[schemaKey(m)] {
+				return
+			}
+			seen[schemaKey(m)] = true
+			collapsed := *m
+			collapsed.ParentProxy = sch.ParentProxy
+			mergeAnnotations(&collapsed, sch)
+			*sch = collapsed
+		}
+	})
+}
+
+// DedupeOneOf returns a copy of the schema tree with duplicate oneOf and anyOf members removed, from this schema and
+// every inline child schema. Members are compared with SchemaProxy.EqualsSemantic, so two inline members that only
+// differ by annotations or key order are duplicates, as are two references to the same schema. The first of each
+// duplicate is kept, so the order of the remaining members does not change. A union listing the same member twice
+// (usually a copy-paste mistake) would otherwise turn into redundant variants in generated code. The original tree
+// is not modified.
+func (s *Schema) DedupeOneOf() *Schema {
+	return s.transform(func(sch *Schema, _ string) {
+		sch.OneOf = dedupeProxies(sch.OneOf)
+		sch.AnyOf = dedupeProxies(sch.AnyOf)
+	})
+}
+
+// dedupeProxies returns the proxies without duplicates, the list is only copied if something is removed.
+func dedupeProxies(proxies []*SchemaProxy) []*SchemaProxy {
+	var unique []*SchemaProxy
+	for i, sp := range proxies {
+		duplicate := slices.ContainsFunc(proxies[:i], func(prev *SchemaProxy) bool { return prev.EqualsSemantic(sp) })
+		switch {
+		case duplicate && unique == nil:
+			unique = slices.Clone(proxies[:i])
+		case !duplicate && unique != nil:
+			unique = append(unique, sp)
+		}
+	}
+	if unique == nil {
+		return proxies
+	}
+	return unique
+}
+
+// singletonMember returns the only member of a schema holding nothing but annotations and a composition of one.
+func singletonMember(s *Schema) *SchemaProxy {
+	var member *SchemaProxy
+	bare := *s
+	switch {
+	case len(s.AllOf) == 1 && len(s.OneOf) == 0 && len(s.AnyOf) == 0:
+		member, bare.AllOf = s.AllOf[0], nil
+	case len(s.OneOf) == 1 && len(s.AllOf) == 0 && len(s.AnyOf) == 0:
+		member, bare.OneOf = s.OneOf[0], nil
+	case len(s.AnyOf) == 1 && len(s.AllOf) == 0 && len(s.OneOf) == 0:
+		member, bare.AnyOf = s.AnyOf[0], nil
+	default:
+		return nil
+	}
+	if member == nil || bare.Anchor != "" || !bare.IsAnyType() {
+		return nil
+	}
+	return member
+}
+
+// mergeAnnotations copies the annotations set on src into dst, replacing any dst already has.
+func mergeAnnotations(dst, src *Schema) {
+	if src.Title != "" {
+		dst.Title = src.Title
+	}
+	if src.Description != "" {
+		dst.Description = src.Description
+	}
+	if src.Deprecated != nil {
+		dst.Deprecated = src.Deprecated
+	}
+	if src.ReadOnly != nil {
+		dst.ReadOnly = src.ReadOnly
+	}
+	if src.WriteOnly != nil {
+		dst.WriteOnly = src.WriteOnly
+	}
+	if src.Nullable != nil {
+		dst.Nullable = src.Nullable
+	}
+	if src.Default != nil {
+		dst.Default = src.Default
+	}
+	if src.Example != nil {
+		dst.Example = src.Example
+	}
+	if len(src.Examples) > 0 {
+		dst.Examples = src.Examples
+	}
+	if src.XML != nil {
+		dst.XML = src.XML
+	}
+	if src.ExternalDocs != nil {
+		dst.ExternalDocs = src.ExternalDocs
+	}
+	if orderedmap.Len(src.Extensions) > 0 {
+		merged := orderedmap.New[string, *yaml.Node]()
+		for pair := orderedmap.First(dst.Extensions); pair != nil; pair = pair.Next() {
+			merged.Set(pair.Key(), pair.Value())
+		}
+		for pair := orderedmap.First(src.Extensions); pair != nil; pair = pair.Next() {
+			merged.Set(pair.Key(), pair.Value())
+		}
+		dst.Extensions = merged
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema_StripAnnotations(t *testing.T) {
+	sch := getHighSchema(t, `title: Order
+description: an order of pizza
+type: object
+required: [size]
+externalDocs:
+  url: https://pb33f.io
+example:
+  size: large
+properties:
+  size:
+    description: how big
+    type: string
+    enum: [small, large]
+    examples: [small]
+  toppings:
+    type: array
+    maxItems: 3
+    items:
+      title: Topping
+      description: something tasty
+      type: string
+      minLength: 1`)
+
+	stripped := sch.StripAnnotations()
+	rend, err := stripped.Render()
+	assert.NoError(t, err)
+	assert.Equal(t, `type: object
+required:
+    - size
+properties:
+    size:
+        type: string
+        enum:
+            - small
+            - large
+    toppings:
+        type: array
+        maxItems: 3
+        items:
+            type: string
+            minLength: 1
+`, string(rend))
+
+	// constraints are identical.
+	instance := map[string]any{"size": "huge", "toppings": []any{"", "b", "c", "d"}}
+	assert.Equal(t, sch.Validate(instance), stripped.Validate(instance))
+	assert.Len(t, stripped.Validate(instance), 3)
+
+	// the original is untouched.
+	assert.Equal(t, "an order of pizza", sch.Description)
+	assert.Equal(t, "how big", sch.Properties.GetOrZero("size").Schema().Description)
+}
+
+func TestSchema_ForContext(t *testing.T) {
+	sch := getHighSchema(t, `type: object
+required: [id, name, password]
+properties:
+  id:
+    type: string
+    readOnly: true
+  name:
+    type: string
+  password:
+    type: string
+    writeOnly: true
+  owner:
+    type: object
+    required: [id]
+    properties:
+      id:
+        type: integer
+        readOnly: true
+      email:
+        type: string`)
+
+	request := sch.ForContext(Request)
+	_, ok := request.Properties.Get("id")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"name", "password"}, request.Required)
+	owner := request.Properties.GetOrZero("owner").Schema()
+	assert.Equal(t, 1, owner.Properties.Len())
+	assert.Empty(t, owner.Required)
+
+	response := sch.ForContext(Response)
+	_, ok = response.Properties.Get("password")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"id", "name"}, response.Required)
+	assert.Equal(t, 2, response.Properties.GetOrZero("owner").Schema().Properties.Len())
+
+	// the original is untouched.
+	assert.Equal(t, 4, sch.Properties.Len())
+	assert.Equal(t, []string{"id", "name", "password"}, sch.Required)
+}
+
+func TestSchema_DedupeOneOf(t *testing.T) {
+	yml := `openapi: 3.1.0
+components:
+  schemas:
+    Cat:
+      type: object
+    Dog:
+      type: object
+    Owner:
+      oneOf:
+        - type: string
+          minLength: 1
+        - type: integer
+        - minLength: 1
+          type: string
+        - type: integer
+          description: a number
+      properties:
+        pet:
+          anyOf:
+            - $ref: '#/components/schemas/Cat'
+            - $ref: '#/components/schemas/Dog'
+            - $ref: '#/components/schemas/Cat'`
+
+	sch := buildComponentSchema(t, yml, "Owner")
+	deduped := sch.DedupeOneOf()
+	assert.Len(t, deduped.OneOf, 2)
+	assert.Equal(t, []string{"string"}, deduped.OneOf[0].Schema().Type)
+	assert.Equal(t, []string{"integer"}, deduped.OneOf[1].Schema().Type)
+
+	pet := deduped.Properties.GetOrZero("pet").Schema()
+	assert.Len(t, pet.AnyOf, 2)
+	assert.Equal(t, "#/components/schemas/Cat", pet.AnyOf[0].GetReference())
+	assert.Equal(t, "#/components/schemas/Dog", pet.AnyOf[1].GetReference())
+
+	// the original is untouched.
+	assert.Len(t, sch.OneOf, 4)
+	assert.Len(t, sch.Properties.GetOrZero("pet").Schema().AnyOf, 3)
+}
+
+func TestSchema_CollapseSingletons(t *testing.T) {
+	sch := getHighSchema(t, `type: object
+properties:
+  size:
+    description: how big the pizza is
+    allOf:
+      - type: string
+        description: a size
+        enum: [small, large]
+  crust:
+    oneOf:
+      - anyOf:
+          - type: string
+            maxLength: 10
+  toppings:
+    type: array
+    allOf:
+      - maxItems: 3
+  sauce:
+    oneOf:
+      - type: string
+      - type: integer`)
+
+	collapsed := sch.CollapseSingletons()
+	rend, err := collapsed.Render()
+	assert.NoError(t, err)
+	assert.Equal(t, `type: object
+properties:
+    size:
+        type: string
+        description: how big the pizza is
+        enum:
+            - small
+            - large
+    crust:
+        type: string
+        maxLength: 10
+    toppings:
+        type: array
+        allOf:
+            - maxItems: 3
+    sauce:
+        oneOf:
+            - type: string
+            - type: integer
+`, string(rend))
+
+	// the original is untouched.
+	assert.Len(t, sch.Properties.GetOrZero("size").Schema().AllOf, 1)
+	assert.Equal(t, "how big the pizza is", sch.Properties.GetOrZero("size").Schema().Description)
+}
+
+func TestSchema_CollapseSingletons_Reference(t *testing.T) {
+	sch := buildComponentSchema(t, `components:
+  schemas:
+    Order:
+      type: object
+      properties:
+        pizza:
+          description: the pizza ordered
+          allOf:
+            - $ref: '#/components/schemas/Pizza'
+    Pizza:
+      type: object`, "Order")
+
+	pizza := sch.CollapseSingletons().Properties.GetOrZero("pizza").Schema()
+	assert.Len(t, pizza.AllOf, 1)
+	assert.True(t, pizza.AllOf[0].IsReference())
+}
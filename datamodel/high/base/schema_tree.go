@@ -0,0 +1,97 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// Tree returns an indented tree of the schema, useful to give a quick overview of its structure in a CLI. Each line
+// is a child schema (a property, items, an allOf member etc.) with its type (and format), required properties are
+// marked with a '*' and references show the reference followed. A schema that refers back to one of its
+// ancestors is marked with '↻ ref' and not expanded again, so circular schemas have a finite tree.
+//
+//	object
+//	├── id*: string (uuid)
+//	└── friends: array
+//	    └── items → #/components/schemas/Person ↻ ref
+func (s *Schema) Tree() string {
+	if s == nil {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(treeType(s))
+	b.WriteString("\n")
+	writeTree(&b, s, "", []any{schemaKey(s)})
+	return b.String()
+}
+
+// writeTree writes the children of a schema, each line starts with the prefix. Active holds the ancestors.
+func writeTree(b *strings.Builder, s *Schema, prefix string, active []any) {
+	children := s.namedChildProxies()
+	for i, child := range children {
+		branch, indent := "├── ", "│   "
+		if i == len(children)-1 {
+			branch, indent = "└── ", "    "
+		}
+		b.WriteString(prefix + branch + treeLabel(s, child.path))
+		if child.proxy.IsReference() {
+			b.WriteString(" → " + child.proxy.GetReference())
+		}
+
+		if v, isBool := child.proxy.IsBooleanSchema(); isBool {
+			b.WriteString(": " + strconv.FormatBool(v) + "\n")
+			continue
+		}
+		sch := child.proxy.Schema()
+		switch {
+		case sch == nil:
+			b.WriteString(": (cannot be built)\n")
+		case slices.Contains(active, schemaKey(sch)):
+			b.WriteString(" ↻ ref\n")
+		default:
+			b.WriteString(": " + treeType(sch) + "\n")
+			writeTree(b, sch, prefix+indent, append(active, schemaKey(sch)))
+		}
+	}
+}
+
+// treeLabel turns the path to a child into a label, a property name (with a '*' if it's required), or the keyword
+// holding the child, with the index of list members, like 'allOf[1]'.
+func treeLabel(parent *Schema, path string) string {
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	if len(segments) == 1 {
+		return segments[0]
+	}
+	name := strings.ReplaceAll(strings.ReplaceAll(segments[1], "~1", "/"), "~0", "~")
+	switch segments[0] {
+	case "properties":
+		if slices.Contains(parent.Required, name) {
+			return name + "*"
+		}
+		return name
+	case "allOf", "oneOf", "anyOf", "prefixItems":
+		return segments[0] + "[" + name + "]"
+	}
+	return segments[0] + "[" + strconv.Quote(name) + "]"
+}
+
+// treeType describes the type of a schema, with the format if there is one. A schema with no type, that cannot
+// be inferred, is 'any'.
+func treeType(s *Schema) string {
+	t := strings.Join(s.Type, "|")
+	if t == "" {
+		t = s.JSONType()
+	}
+	if t == "" {
+		t = "any"
+	}
+	if s.Format != "" {
+		t += " (" + s.Format + ")"
+	}
+	return t
+}
@@ -0,0 +1,55 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema_Tree(t *testing.T) {
+	yml := `components:
+  schemas:
+    Person:
+      type: object
+      required: [id]
+      properties:
+        id:
+          type: string
+          format: uuid
+        address:
+          $ref: '#/components/schemas/Address'
+        friends:
+          type: array
+          items:
+            $ref: '#/components/schemas/Person'
+        extra:
+          additionalProperties: false
+    Address:
+      type: object
+      properties:
+        street:
+          type: string
+        kind:
+          oneOf:
+            - type: string
+            - type: integer`
+
+	expected := `object
+├── id*: string (uuid)
+├── address → #/components/schemas/Address: object
+│   ├── street: string
+│   └── kind: any
+│       ├── oneOf[0]: string
+│       └── oneOf[1]: integer
+├── friends: array
+│   └── items → #/components/schemas/Person ↻ ref
+└── extra: object
+`
+	assert.Equal(t, expected, buildComponentSchema(t, yml, "Person").Tree())
+
+	var nilSchema *Schema
+	assert.Empty(t, nilSchema.Tree())
+}
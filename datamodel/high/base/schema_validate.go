@@ -0,0 +1,693 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pb33f/libopenapi/orderedmap"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationError represents a single failure found when validating an instance against a Schema.
+type ValidationError struct {
+	// Path is a JSON Pointer to the location in the instance that failed validation, the root is an empty string.
+	Path string
+
+	// Keyword is the schema keyword that failed, for example 'type' or 'required'.
+	Keyword string
+
+	// Message is a human-readable description of the failure.
+	Message string
+}
+
+// Error returns a string representation of the ValidationError, so it can be used as an error.
+func (v *ValidationError) Error() string {
+	path := v.Path
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Sprintf("%s: %s", path, v.Message)
+}
+
+// ValidateOptions controls how Schema.ValidateWithOptions checks an instance.
+type ValidateOptions struct {
+	// FailFast will stop validation as soon as the first ValidationError is found, the rest of the schema tree is
+	// skipped. Useful when only a valid / invalid answer is needed.
+	FailFast bool
+
+	// AssertFormat turns 'format' into a constraint, values that do not match the format fail validation. By default
+	// (as defined by JSON Schema 2020-12) formats are annotations only, and are not checked. The formats that can
+	// be asserted are email, date, date-time, time, uuid, ipv4, ipv6, hostname, uri, uri-reference and byte, any
+	// other format is always treated as an annotation.
+	AssertFormat bool
+
+	// DisabledKeywords are schema keywords that are not checked anywhere in the schema tree, for example 'pattern'
+	// (regular expressions can be expensive) or 'format'. Useful to tune the cost of validating trusted input.
+	// A disabled keyword is skipped entirely; with 'type' disabled every other keyword is still checked, and
+	// disabling a keyword holding schemas (like 'properties', 'items' or 'allOf') skips the values it applies to.
+	DisabledKeywords []string
+
+	// MaxErrors stops validation once that many ValidationError have been found, the rest of the instance is not
+	// checked. Bounds the memory and time spent on a huge, badly wrong (or hostile) payload. Zero means no limit.
+	MaxErrors int
+
+	// Coerce converts strings into the type the schema declares before checking them, so "5" is an integer and
+	// "true" is a boolean, see Schema.ValidateCoerced. Useful for lenient sources like config files, environment
+	// variables or query strings, where every value is a string.
+	Coerce bool
+}
+
+// ValidateOption is used to change the ValidateOptions used by Schema.Validate.
+type ValidateOption func(opts *ValidateOptions)
+
+// AssertFormat will return a ValidateOption that turns format assertion on or off, see ValidateOptions.AssertFormat.
+func AssertFormat(assert bool) ValidateOption {
+	return func(opts *ValidateOptions) {
+		opts.AssertFormat = assert
+	}
+}
+
+// DisableKeywords will return a ValidateOption that stops the keywords given from being checked, see
+// ValidateOptions.DisabledKeywords.
+func DisableKeywords(keywords ...string) ValidateOption {
+	return func(opts *ValidateOptions) {
+		opts.DisabledKeywords = append(opts.DisabledKeywords, keywords...)
+	}
+}
+
+// Coerce will return a ValidateOption that turns type coercion of strings on or off, see ValidateOptions.Coerce.
+func Coerce(coerce bool) ValidateOption {
+	return func(opts *ValidateOptions) {
+		opts.Coerce = coerce
+	}
+}
+
+// MaxErrors will return a ValidateOption that stops validation after n errors, see ValidateOptions.MaxErrors.
+// Use ValidateBounded to also learn if there were more errors than the ones returned.
+func MaxErrors(n int) ValidateOption {
+	return func(opts *ValidateOptions) {
+		opts.MaxErrors = n
+	}
+}
+
+// Validate will check an instance against the schema and return every ValidationError found. If the instance is
+// valid, nothing is returned.
+//
+// The instance is expected to be a plain Go value, the kind produced by decoding JSON or YAML into an 'any';
+// maps with string keys, slices, strings, numbers, booleans and nil.
+//
+// Boolean schemas are understood, a true schema accepts everything, a false schema rejects everything.
+//
+// The annotation based keywords unevaluatedItems and unevaluatedProperties are not supported, they are ignored.
+//
+// A 'pattern' is not anchored, as JSON Schema requires, it matches if it is found anywhere in the string. So '[0-9]'
+// accepts 'abc1', a pattern that must match the whole string needs to say so: '^[0-9]+$'.
+//
+// Options can be supplied to change how the instance is checked, for example AssertFormat(true).
+func (s *Schema) Validate(instance any, opts ...ValidateOption) []*ValidationError {
+	options := new(ValidateOptions)
+	for _, opt := range opts {
+		opt(options)
+	}
+	return s.ValidateWithOptions(instance, options)
+}
+
+// ValidateBounded will check an instance in the same way as Validate, and also returns true if validation was stopped
+// by MaxErrors with more errors still to find. So with MaxErrors(10) a payload with 10 errors returns those 10 and
+// false, a payload with 11 or more returns the first 10 and true.
+func (s *Schema) ValidateBounded(instance any, opts ...ValidateOption) ([]*ValidationError, bool) {
+	options := new(ValidateOptions)
+	for _, opt := range opts {
+		opt(options)
+	}
+	return s.validate(instance, options)
+}
+
+// ValidateWithOptions will check an instance against the schema in the same way as Validate, using the options
+// supplied. A nil ValidateOptions has the same behavior as Validate.
+func (s *Schema) ValidateWithOptions(instance any, opts *ValidateOptions) []*ValidationError {
+	errs, _ := s.validate(instance, opts)
+	return errs
+}
+
+// validate runs the validator, and returns the errors found and if there were more than MaxErrors.
+func (s *Schema) validate(instance any, opts *ValidateOptions) ([]*ValidationError, bool) {
+	if opts == nil {
+		opts = new(ValidateOptions)
+	}
+	v := &schemaValidator{opts: opts, patterns: make(map[string]*regexp.Regexp)}
+	if len(opts.DisabledKeywords) > 0 {
+		v.disabled = make(map[string]bool, len(opts.DisabledKeywords))
+		for _, k := range opts.DisabledKeywords {
+			v.disabled[k] = true
+		}
+	}
+	instance = normalizeInstance(instance)
+	if opts.Coerce {
+		instance = s.coerce(instance)
+	}
+	v.validateSchema(s, instance, "")
+	// one error past the limit is collected, to know there are more.
+	if opts.MaxErrors > 0 && len(v.errors) > opts.MaxErrors {
+		return v.errors[:opts.MaxErrors], true
+	}
+	return v.errors, false
+}
+
+// schemaValidator holds the state of a single Validate run.
+type schemaValidator struct {
+	opts     *ValidateOptions
+	errors   []*ValidationError
+	disabled map[string]bool
+	patterns map[string]*regexp.Regexp
+
+	// compiled is set when running a CompiledValidator, patterns are then shared and read only, and enum / const
+	// values are looked up rather than decoded.
+	compiled *CompiledValidator
+}
+
+// fail records a ValidationError. Disabled keywords are never checked, so they never fail.
+func (v *schemaValidator) fail(path, keyword, message string, args ...any) {
+	if v.stopped() {
+		return
+	}
+	v.errors = append(v.errors, &ValidationError{Path: path, Keyword: keyword, Message: fmt.Sprintf(message, args...)})
+}
+
+// stopped returns true if validation should not continue, because fail-fast is on and an error has been found, or
+// more errors than MaxErrors have been found.
+func (v *schemaValidator) stopped() bool {
+	if v.opts.FailFast {
+		return len(v.errors) > 0
+	}
+	return v.opts.MaxErrors > 0 && len(v.errors) > v.opts.MaxErrors
+}
+
+// validateProxy checks an instance against a schema proxy, boolean schemas are handled without building anything.
+func (v *schemaValidator) validateProxy(sp *SchemaProxy, instance any, path string) {
+	if sp == nil {
+		return
+	}
+	if b, ok := sp.IsBooleanSchema(); ok {
+		if !b {
+			v.fail(path, "false", "schema is false, no value is allowed")
+		}
+		return
+	}
+	sch, err := sp.BuildSchema()
+	if sch == nil {
+		if err != nil {
+			v.fail(path, "$ref", "schema cannot be built: %s", err.Error())
+		}
+		return
+	}
+	v.validateSchema(sch, instance, path)
+}
+
+// validateBranch runs an instance against a proxy in isolation and returns the errors, without recording them.
+// Only the presence of errors matters to a branch, so branches always fail fast.
+func (v *schemaValidator) validateBranch(sp *SchemaProxy, instance any, path string) []*ValidationError {
+	opts := *v.opts
+	opts.FailFast = true
+	branch := &schemaValidator{opts: &opts, disabled: v.disabled, patterns: v.patterns, compiled: v.compiled}
+	branch.validateProxy(sp, instance, path)
+	return branch.errors
+}
+
+func (v *schemaValidator) validateSchema(s *Schema, instance any, path string) {
+	if s == nil || v.stopped() {
+		return
+	}
+	if !v.validateType(s, instance, path) {
+		// there is no point checking anything else if the value is the wrong type.
+		return
+	}
+	v.validateValues(s, instance, path)
+
+	switch value := instance.(type) {
+	case string:
+		v.validateString(s, value, path)
+		if v.opts.AssertFormat && !v.disabled["format"] {
+			v.validateFormat(s, value, path)
+		}
+	case float64, int64:
+		v.validateNumber(s, value, path)
+	case []any:
+		v.validateArray(s, value, path)
+	case map[string]any:
+		v.validateObject(s, value, path)
+	}
+	if v.stopped() {
+		return
+	}
+	v.validateComposition(s, instance, path)
+}
+
+// validateType checks the instance type against the schema type(s), returns false if the type does not match.
+func (v *schemaValidator) validateType(s *Schema, instance any, path string) bool {
+	if len(s.Type) == 0 || v.disabled["type"] {
+		return true
+	}
+	if instance == nil && s.Nullable != nil && *s.Nullable {
+		return true
+	}
+	for _, t := range s.Type {
+		if instanceIsType(instance, t) {
+			return true
+		}
+	}
+	v.fail(path, "type", "expected type '%s', got '%s'", strings.Join(s.Type, "|"), instanceType(instance))
+	return false
+}
+
+// validateValues checks enum and const.
+func (v *schemaValidator) validateValues(s *Schema, instance any, path string) {
+	if len(s.Enum) > 0 && !v.disabled["enum"] {
+		found := false
+		for _, e := range s.Enum {
+			if valuesEqual(instance, v.nodeValue(s, e)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail(path, "enum", "value is not one of the allowed enum values")
+		}
+	}
+	// arrays and objects are compared deeply, so the expected and actual values are both shown.
+	if s.Const != nil && !v.disabled["const"] {
+		if expected := v.nodeValue(s, s.Const); !valuesEqual(instance, expected) {
+			v.fail(path, "const", "value %s does not match the const value %s", describeInstance(instance),
+				describeInstance(expected))
+		}
+	}
+}
+
+// validateString checks minLength, maxLength and pattern. Lengths are counted in Unicode code points (runes), not
+// bytes, as JSON Schema requires. Patterns are searched for (MatchString), they are never anchored to the whole string.
+func (v *schemaValidator) validateString(s *Schema, instance string, path string) {
+	length := int64(utf8.RuneCountInString(instance))
+	if s.MinLength != nil && !v.disabled["minLength"] && length < *s.MinLength {
+		v.fail(path, "minLength", "string has %d characters, at least %d required", length, *s.MinLength)
+	}
+	if s.MaxLength != nil && !v.disabled["maxLength"] && length > *s.MaxLength {
+		v.fail(path, "maxLength", "string has %d characters, no more than %d allowed", length, *s.MaxLength)
+	}
+	if s.Pattern != "" && !v.disabled["pattern"] {
+		re, err := v.pattern(s.Pattern)
+		if err != nil {
+			v.fail(path, "pattern", "pattern '%s' is not a valid regular expression", s.Pattern)
+		} else if !re.MatchString(instance) {
+			v.fail(path, "pattern", "value '%s' does not match pattern '%s'", instance, s.Pattern)
+		}
+	}
+}
+
+// pattern compiles a regular expression once per validation run. A CompiledValidator compiled every pattern up front,
+// so its map is never written to and can be shared between runs.
+func (v *schemaValidator) pattern(expr string) (*regexp.Regexp, error) {
+	if re, ok := v.patterns[expr]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil || v.compiled != nil {
+		return re, err
+	}
+	if v.patterns == nil {
+		v.patterns = make(map[string]*regexp.Regexp)
+	}
+	v.patterns[expr] = re
+	return re, nil
+}
+
+// nodeValue decodes an enum or const value of a schema, compiled validators decode each value once.
+func (v *schemaValidator) nodeValue(s *Schema, n *yaml.Node) any {
+	if v.compiled != nil {
+		if value, ok := v.compiled.values[n]; ok {
+			return value
+		}
+	}
+	return s.nodeValue(n)
+}
+
+func (v *schemaValidator) validateNumber(s *Schema, instance any, path string) {
+	n, _ := toFloat(instance)
+	if max, exclusive, ok := s.upperBound(); ok {
+		if exclusive && !v.disabled["exclusiveMaximum"] && n >= max {
+			v.fail(path, "exclusiveMaximum", "value %v must be less than %v", n, max)
+		} else if !exclusive && !v.disabled["maximum"] && n > max {
+			v.fail(path, "maximum", "value %v must be less than or equal to %v", n, max)
+		}
+	}
+	if min, exclusive, ok := s.lowerBound(); ok {
+		if exclusive && !v.disabled["exclusiveMinimum"] && n <= min {
+			v.fail(path, "exclusiveMinimum", "value %v must be greater than %v", n, min)
+		} else if !exclusive && !v.disabled["minimum"] && n < min {
+			v.fail(path, "minimum", "value %v must be greater than or equal to %v", n, min)
+		}
+	}
+	// multiples are checked with a small tolerance, see multipleOfTolerance.
+	if s.MultipleOf != nil && !v.disabled["multipleOf"] && !isMultipleOf(n, *s.MultipleOf) {
+		v.fail(path, "multipleOf", "value %v is not a multiple of %v", n, *s.MultipleOf)
+	}
+}
+
+func (v *schemaValidator) validateArray(s *Schema, instance []any, path string) {
+	if s.MinItems != nil && !v.disabled["minItems"] && int64(len(instance)) < *s.MinItems {
+		v.fail(path, "minItems", "array has %d items, at least %d required", len(instance), *s.MinItems)
+	}
+	if s.MaxItems != nil && !v.disabled["maxItems"] && int64(len(instance)) > *s.MaxItems {
+		v.fail(path, "maxItems", "array has %d items, no more than %d allowed", len(instance), *s.MaxItems)
+	}
+	// items are compared using JSON semantics, so objects with the same keys and values are duplicates.
+	if s.UniqueItems != nil && *s.UniqueItems && !v.disabled["uniqueItems"] {
+	unique:
+		for i := 1; i < len(instance); i++ {
+			for j := 0; j < i; j++ {
+				if valuesEqual(instance[i], instance[j]) {
+					v.fail(path, "uniqueItems", "array items must be unique, item %d is a duplicate of item %d", i, j)
+					break unique
+				}
+			}
+		}
+	}
+	// contains needs at least one matching item (or minContains of them), and no more than maxContains.
+	if s.Contains != nil && !v.disabled["contains"] {
+		matches := 0
+		for i, item := range instance {
+			if len(v.validateBranch(s.Contains, item, path+"/"+strconv.Itoa(i))) == 0 {
+				matches++
+			}
+		}
+		keyword, min := "contains", int64(1)
+		if s.MinContains != nil && !v.disabled["minContains"] {
+			keyword, min = "minContains", *s.MinContains
+		}
+		if int64(matches) < min {
+			v.fail(path, keyword, "array has %d items matching contains, at least %d required", matches, min)
+		}
+		if s.MaxContains != nil && !v.disabled["maxContains"] && int64(matches) > *s.MaxContains {
+			v.fail(path, "maxContains", "array has %d items matching contains, no more than %d allowed", matches,
+				*s.MaxContains)
+		}
+	}
+	// prefixItems validate the items at the same position, items validates everything after them.
+	for i, item := range instance {
+		if v.stopped() || i >= len(s.PrefixItems) || v.disabled["prefixItems"] {
+			break
+		}
+		v.validateProxy(s.PrefixItems[i], item, path+"/"+strconv.Itoa(i))
+	}
+	if s.Items != nil && !v.disabled["items"] {
+		for i, item := range instance {
+			if v.stopped() {
+				return
+			}
+			if i < len(s.PrefixItems) {
+				continue
+			}
+			itemPath := path + "/" + strconv.Itoa(i)
+			if s.Items.IsB() {
+				if !s.Items.B {
+					v.fail(itemPath, "items", "items is false, no items are allowed")
+				}
+				continue
+			}
+			v.validateProxy(s.Items.A, item, itemPath)
+		}
+	}
+}
+
+func (v *schemaValidator) validateObject(s *Schema, instance map[string]any, path string) {
+	for _, r := range s.Required {
+		if _, ok := instance[r]; !ok && !v.disabled["required"] {
+			v.fail(path, "required", "missing required property '%s'", r)
+		}
+	}
+	// a property in dependentRequired that is present, requires every property it lists.
+	for pair := orderedmap.First(s.DependentRequired); pair != nil; pair = pair.Next() {
+		if _, ok := instance[pair.Key()]; !ok || v.disabled["dependentRequired"] {
+			continue
+		}
+		var missing []string
+		for _, d := range pair.Value() {
+			if _, ok := instance[d]; !ok {
+				missing = append(missing, d)
+			}
+		}
+		if len(missing) > 0 {
+			v.fail(path, "dependentRequired", "property '%s' is present, so '%s' must be present too", pair.Key(),
+				strings.Join(missing, "', '"))
+		}
+	}
+	if s.MinProperties != nil && !v.disabled["minProperties"] && int64(len(instance)) < *s.MinProperties {
+		v.fail(path, "minProperties", "object has %d properties, at least %d required", len(instance), *s.MinProperties)
+	}
+	if s.MaxProperties != nil && !v.disabled["maxProperties"] && int64(len(instance)) > *s.MaxProperties {
+		v.fail(path, "maxProperties", "object has %d properties, no more than %d allowed", len(instance), *s.MaxProperties)
+	}
+
+	for pair := orderedmap.First(s.Properties); pair != nil && !v.stopped(); pair = pair.Next() {
+		if value, ok := instance[pair.Key()]; ok && !v.disabled["properties"] {
+			v.validateProxy(pair.Value(), value, path+"/"+escapePointer(pair.Key()))
+		}
+	}
+
+	// a property matching a pattern is validated against its schema, it may match (and be checked by) more than one.
+	// patterns that are not valid regular expressions match nothing, as they do for additionalProperties.
+	for pair := orderedmap.First(s.PatternProperties); pair != nil; pair = pair.Next() {
+		re, err := v.pattern(pair.Key())
+		if err != nil || v.disabled["patternProperties"] {
+			continue
+		}
+		for _, key := range sortedKeys(instance) {
+			if v.stopped() {
+				return
+			}
+			if re.MatchString(key) {
+				v.validateProxy(pair.Value(), instance[key], path+"/"+escapePointer(key))
+			}
+		}
+	}
+
+	// every property name is validated as a string against propertyNames.
+	if s.PropertyNames != nil && !v.disabled["propertyNames"] {
+		for _, key := range sortedKeys(instance) {
+			if errs := v.validateBranch(s.PropertyNames, key, path); len(errs) > 0 {
+				v.fail(path+"/"+escapePointer(key), "propertyNames", "property name '%s' is not valid: %s", key,
+					errs[0].Message)
+			}
+		}
+	}
+
+	// a property in dependentSchemas that is present, requires the whole object to match the schema it maps to.
+	for pair := orderedmap.First(s.DependentSchemas); pair != nil && !v.stopped(); pair = pair.Next() {
+		if _, ok := instance[pair.Key()]; ok && !v.disabled["dependentSchemas"] {
+			v.validateProxy(pair.Value(), instance, path)
+		}
+	}
+
+	// additional properties are anything not declared in properties, or matched by patternProperties.
+	ap := s.AdditionalProperties
+	if ap == nil || (ap.IsB() && ap.B) || (ap.IsA() && ap.A == nil) || v.disabled["additionalProperties"] {
+		return
+	}
+	for _, key := range sortedKeys(instance) {
+		if v.stopped() {
+			return
+		}
+		if !v.isAdditional(s, key) {
+			continue
+		}
+		keyPath := path + "/" + escapePointer(key)
+		if ap.IsB() {
+			v.fail(keyPath, "additionalProperties", "property '%s' is not allowed, additionalProperties is false", key)
+			continue
+		}
+		v.validateProxy(ap.A, instance[key], keyPath)
+	}
+}
+
+// isAdditional returns true if a property is not declared in properties, and is not matched by any of the
+// patternProperties. Patterns that are not valid regular expressions match nothing.
+func (v *schemaValidator) isAdditional(s *Schema, key string) bool {
+	if s.Properties.GetOrZero(key) != nil {
+		return false
+	}
+	for pair := orderedmap.First(s.PatternProperties); pair != nil; pair = pair.Next() {
+		if re, err := v.pattern(pair.Key()); err == nil && re.MatchString(key) {
+			return false
+		}
+	}
+	return true
+}
+
+func (v *schemaValidator) validateComposition(s *Schema, instance any, path string) {
+	for _, sp := range s.AllOf {
+		if v.stopped() || v.disabled["allOf"] {
+			return
+		}
+		v.validateProxy(sp, instance, path)
+	}
+	if len(s.AnyOf) > 0 && !v.disabled["anyOf"] {
+		matched := false
+		for _, sp := range s.AnyOf {
+			if len(v.validateBranch(sp, instance, path)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.fail(path, "anyOf", "value does not match any of the anyOf schemas")
+		}
+	}
+	if len(s.OneOf) > 0 && !v.disabled["oneOf"] {
+		matches := 0
+		for _, sp := range s.OneOf {
+			if len(v.validateBranch(sp, instance, path)) == 0 {
+				matches++
+			}
+		}
+		if matches != 1 {
+			v.fail(path, "oneOf", "value must match exactly one oneOf schema, matched %d", matches)
+		}
+	}
+	if s.Not != nil && !v.disabled["not"] && len(v.validateBranch(s.Not, instance, path)) == 0 {
+		v.fail(path, "not", "value must not match the 'not' schema")
+	}
+	// a value matching 'if' must match 'then', any other value must match 'else'. Either may be missing.
+	if s.If != nil && !v.disabled["if"] {
+		if len(v.validateBranch(s.If, instance, path)) == 0 {
+			if !v.disabled["then"] {
+				v.validateProxy(s.Then, instance, path)
+			}
+		} else if !v.disabled["else"] {
+			v.validateProxy(s.Else, instance, path)
+		}
+	}
+}
+
+// normalizeInstance converts an instance into the plain types understood by the validator. Maps become
+// map[string]any, slices become []any, integers become int64 and other numbers become float64. A json.Number (from a
+// decoder using UseNumber) is a number, not a string.
+func normalizeInstance(instance any) any {
+	switch i := instance.(type) {
+	case nil, string, bool, float64, int64:
+		return instance
+	case json.Number:
+		if n, err := i.Int64(); err == nil {
+			return n
+		}
+		if f, err := i.Float64(); err == nil {
+			return f
+		}
+		return i.String()
+	case map[string]any:
+		m := make(map[string]any, len(i))
+		for k, val := range i {
+			m[k] = normalizeInstance(val)
+		}
+		return m
+	case []any:
+		s := make([]any, len(i))
+		for k, val := range i {
+			s[k] = normalizeInstance(val)
+		}
+		return s
+	}
+	rv := reflect.ValueOf(instance)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.String:
+		return rv.String()
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.Map:
+		m := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			m[fmt.Sprint(iter.Key().Interface())] = normalizeInstance(iter.Value().Interface())
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		s := make([]any, rv.Len())
+		for k := 0; k < rv.Len(); k++ {
+			s[k] = normalizeInstance(rv.Index(k).Interface())
+		}
+		return s
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return nil
+		}
+		return normalizeInstance(rv.Elem().Interface())
+	}
+	return instance
+}
+
+// instanceType returns the JSON type name of a normalized instance.
+func instanceType(instance any) string {
+	switch i := instance.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case int64:
+		return "integer"
+	case float64:
+		if isIntegral(i) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return "unknown"
+}
+
+// instanceIsType returns true if the normalized instance is of the JSON type supplied.
+func instanceIsType(instance any, t string) bool {
+	it := instanceType(instance)
+	if t == "number" {
+		return it == "number" || it == "integer"
+	}
+	return it == t
+}
+
+// isIntegral returns true if the float has no fractional part.
+func isIntegral(f float64) bool {
+	return !math.IsInf(f, 0) && !math.IsNaN(f) && f == math.Trunc(f)
+}
+
+// escapePointer escapes a single JSON Pointer segment.
+func escapePointer(segment string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(segment, "~", "~0"), "/", "~1")
+}
+
+// sortedKeys returns the keys of a map in a stable order, so errors are always reported consistently.
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
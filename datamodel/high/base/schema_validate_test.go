@@ -0,0 +1,656 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema_Validate(t *testing.T) {
+	yml := `type: object
+required: [name, age]
+properties:
+  name:
+    type: string
+  age:
+    type: integer
+    minimum: 0
+  tags:
+    type: array
+    maxItems: 2
+    items:
+      type: string`
+
+	sch := getHighSchema(t, yml)
+
+	assert.Empty(t, sch.Validate(map[string]any{"name": "pizza", "age": 3, "tags": []string{"hot"}}))
+
+	errs := sch.Validate(map[string]any{"name": 12, "age": -1, "tags": []any{"a", 2, "c"}})
+	assert.Len(t, errs, 4)
+	assert.Equal(t, "/name", errs[0].Path)
+	assert.Equal(t, "type", errs[0].Keyword)
+	assert.Equal(t, "/age", errs[1].Path)
+	assert.Equal(t, "minimum", errs[1].Keyword)
+	assert.Equal(t, "/tags", errs[2].Path)
+	assert.Equal(t, "maxItems", errs[2].Keyword)
+	assert.Equal(t, "/tags/1", errs[3].Path)
+	assert.Equal(t, "/tags/1: expected type 'string', got 'integer'", errs[3].Error())
+
+	errs = sch.Validate(map[string]any{"name": "pizza"})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "required", errs[0].Keyword)
+	assert.Equal(t, "/: missing required property 'age'", errs[0].Error())
+}
+
+func TestSchema_Validate_AdditionalPropertiesBooleans(t *testing.T) {
+	open := getHighSchema(t, `type: object
+properties:
+  name:
+    type: string
+additionalProperties: true`)
+	closed := getHighSchema(t, `type: object
+properties:
+  name:
+    type: string
+additionalProperties: false`)
+
+	instance := map[string]any{"name": "pizza", "topping": "cheese"}
+	assert.Empty(t, open.Validate(instance))
+
+	errs := closed.Validate(instance)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "/topping", errs[0].Path)
+	assert.Equal(t, "additionalProperties", errs[0].Keyword)
+	assert.Empty(t, closed.Validate(map[string]any{"name": "pizza"}))
+}
+
+func TestSchema_Validate_BooleanSchemas(t *testing.T) {
+	sch := getHighSchema(t, `type: object
+properties:
+  anything: true
+  nothing: false
+not: false`)
+
+	yes, isBool := sch.Properties.GetOrZero("anything").IsBooleanSchema()
+	assert.True(t, isBool)
+	assert.True(t, yes)
+	no, isBool := sch.Properties.GetOrZero("nothing").IsBooleanSchema()
+	assert.True(t, isBool)
+	assert.False(t, no)
+	_, isBool = CreateSchemaProxy(&Schema{}).IsBooleanSchema()
+	assert.False(t, isBool)
+
+	assert.Empty(t, sch.Validate(map[string]any{"anything": []any{1, "two"}}))
+
+	errs := sch.Validate(map[string]any{"nothing": 1})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "/nothing", errs[0].Path)
+	assert.Equal(t, "false", errs[0].Keyword)
+
+	rend, _ := sch.Render()
+	assert.Contains(t, string(rend), "anything: true")
+	assert.Contains(t, string(rend), "nothing: false")
+}
+
+func TestSchema_Validate_Composition(t *testing.T) {
+	sch := getHighSchema(t, `oneOf:
+  - type: string
+  - type: integer
+  - type: number`)
+
+	assert.Empty(t, sch.Validate("pizza"))
+	errs := sch.Validate(5)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "oneOf", errs[0].Keyword)
+	assert.Empty(t, sch.Validate(5.5))
+
+	sch = getHighSchema(t, `anyOf:
+  - type: string
+  - type: boolean
+enum: [pizza, true]`)
+	assert.Empty(t, sch.Validate(true))
+	assert.Len(t, sch.Validate(12), 2)
+}
+
+func TestSchema_Validate_Nullable(t *testing.T) {
+	sch := getHighSchema(t, `type: string
+nullable: true`)
+	assert.Empty(t, sch.Validate(nil))
+	assert.Len(t, getHighSchema(t, `type: string`).Validate(nil), 1)
+}
+
+func TestSchema_ValidateWithOptions_FailFast(t *testing.T) {
+	sch := getHighSchema(t, `type: object
+required: [name, age, email]
+properties:
+  name:
+    type: string
+  age:
+    type: integer
+    minimum: 0
+  tags:
+    type: array
+    items:
+      type: string`)
+
+	instance := map[string]any{"name": 12, "age": -1, "tags": []any{1, 2, 3}}
+
+	assert.Len(t, sch.Validate(instance), 6)
+	assert.Len(t, sch.ValidateWithOptions(instance, nil), 6)
+
+	errs := sch.ValidateWithOptions(instance, &ValidateOptions{FailFast: true})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "required", errs[0].Keyword)
+
+	assert.Empty(t, sch.ValidateWithOptions(map[string]any{"name": "a", "age": 1, "email": "b"},
+		&ValidateOptions{FailFast: true}))
+}
+
+func TestSchema_Validate_MaxErrors(t *testing.T) {
+	sch := getHighSchema(t, `type: array
+items:
+  type: integer`)
+
+	items := make([]any, 1000)
+	for i := range items {
+		items[i] = "nope"
+	}
+
+	assert.Len(t, sch.Validate(items), 1000)
+	errs := sch.Validate(items, MaxErrors(10))
+	assert.Len(t, errs, 10)
+	assert.Equal(t, "/9", errs[9].Path)
+
+	errs, more := sch.ValidateBounded(items, MaxErrors(10))
+	assert.Len(t, errs, 10)
+	assert.True(t, more)
+
+	errs, more = sch.ValidateBounded(items[:10], MaxErrors(10))
+	assert.Len(t, errs, 10)
+	assert.False(t, more)
+
+	errs, more = sch.ValidateBounded(items)
+	assert.Len(t, errs, 1000)
+	assert.False(t, more)
+}
+
+func TestSchema_Validate_AdditionalPropertiesNoProperties(t *testing.T) {
+	sch := getHighSchema(t, `type: object
+additionalProperties: false`)
+	errs := sch.Validate(map[string]any{"pizza": true})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "/pizza", errs[0].Path)
+}
+
+func TestSchema_Validate_AssertFormat(t *testing.T) {
+	sch := getHighSchema(t, `type: string
+format: email`)
+
+	assert.Empty(t, sch.Validate("not-an-email"))
+	assert.Empty(t, sch.Validate("not-an-email", AssertFormat(false)))
+
+	errs := sch.Validate("not-an-email", AssertFormat(true))
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "format", errs[0].Keyword)
+	assert.Equal(t, "/: value 'not-an-email' is not a valid 'email'", errs[0].Error())
+	assert.Empty(t, sch.Validate("pizza@pb33f.io", AssertFormat(true)))
+
+	formats := []struct {
+		format, valid, invalid string
+	}{
+		{"date", "2023-10-01", "2023-13-01"},
+		{"date-time", "2023-10-01T12:00:00Z", "2023-10-01 12:00"},
+		{"time", "12:00:00Z", "25:00:00Z"},
+		{"uuid", "123e4567-e89b-12d3-a456-426614174000", "123e4567"},
+		{"ipv4", "192.168.0.1", "::1"},
+		{"ipv6", "::1", "192.168.0.1"},
+		{"hostname", "pb33f.io", "-pb33f.io"},
+		{"uri", "https://pb33f.io", "pb33f"},
+		{"byte", "cGl6emE=", "pizza!"},
+	}
+	for _, f := range formats {
+		sch = getHighSchema(t, "type: string\nformat: "+f.format)
+		assert.Empty(t, sch.Validate(f.valid, AssertFormat(true)), f.format)
+		assert.Len(t, sch.Validate(f.invalid, AssertFormat(true)), 1, f.format)
+	}
+
+	// unknown formats are annotations only.
+	sch = getHighSchema(t, `type: string
+format: pizza`)
+	assert.Empty(t, sch.Validate("anything", AssertFormat(true)))
+}
+
+func TestSchema_Validate_NestedPaths(t *testing.T) {
+	sch := getHighSchema(t, `type: object
+properties:
+  orders:
+    type: array
+    items:
+      type: object
+      properties:
+        items:
+          type: array
+          items:
+            type: object
+            required: [sku]
+            properties:
+              sku:
+                type: string
+        location:
+          type: array
+          prefixItems:
+            - type: number
+            - type: number
+          items: false
+      additionalProperties: false`)
+
+	order := func(sku any) map[string]any {
+		return map[string]any{"items": []any{map[string]any{"sku": sku}}}
+	}
+	errs := sch.Validate(map[string]any{"orders": []any{order("a"), order("b"), order(12)}})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "/orders/2/items/0/sku", errs[0].Path)
+	assert.Equal(t, "/orders/2/items/0/sku: expected type 'string', got 'integer'", errs[0].Error())
+
+	errs = sch.Validate(map[string]any{"orders": []any{
+		map[string]any{"location": []any{1.5, "north", 3}, "pizza/slice": true},
+	}})
+	assert.Len(t, errs, 3)
+	assert.Equal(t, "/orders/0/location/1", errs[0].Path)
+	assert.Equal(t, "type", errs[0].Keyword)
+	assert.Equal(t, "/orders/0/location/2", errs[1].Path)
+	assert.Equal(t, "items", errs[1].Keyword)
+	assert.Equal(t, "/orders/0/pizza~1slice", errs[2].Path)
+
+	composed := getHighSchema(t, `type: array
+items:
+  allOf:
+    - type: object
+      properties:
+        sku:
+          type: string`)
+	errs = composed.Validate([]any{map[string]any{"sku": "a"}, map[string]any{"sku": true}})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "/1/sku", errs[0].Path)
+}
+
+func TestSchema_Validate_StringLength(t *testing.T) {
+	sch := getHighSchema(t, `type: string
+minLength: 2
+maxLength: 3`)
+
+	// three emoji are three code points, but twelve bytes.
+	assert.Empty(t, sch.Validate("🍕🍕🍕"))
+	assert.Empty(t, sch.Validate("ab"))
+
+	errs := sch.Validate("🍕🍕🍕🍕")
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "maxLength", errs[0].Keyword)
+	assert.Equal(t, "/: string has 4 characters, no more than 3 allowed", errs[0].Error())
+
+	errs = sch.Validate("🍕")
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "minLength", errs[0].Keyword)
+}
+
+func TestSchema_Validate_MultipleOf(t *testing.T) {
+	sch := getHighSchema(t, `type: number
+multipleOf: 0.1`)
+
+	// all of these fail a naive float modulo.
+	for _, n := range []float64{0.3, 0.7, 1.1, 2.3, 100.7} {
+		assert.Empty(t, sch.Validate(n), n)
+	}
+	errs := sch.Validate(0.35)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "multipleOf", errs[0].Keyword)
+	assert.Equal(t, "/: value 0.35 is not a multiple of 0.1", errs[0].Error())
+
+	sch = getHighSchema(t, `type: integer
+multipleOf: 3`)
+	assert.Empty(t, sch.Validate(9))
+	assert.Len(t, sch.Validate(10), 1)
+}
+
+func TestSchema_Validate_DisableKeywords(t *testing.T) {
+	sch := getHighSchema(t, `type: object
+properties:
+  code:
+    type: string
+    pattern: '^[A-Z]{3}$'
+    maxLength: 3
+  email:
+    type: string
+    format: email`)
+
+	instance := map[string]any{"code": "pizza", "email": "nope"}
+	errs := sch.Validate(instance, AssertFormat(true))
+	assert.Len(t, errs, 3)
+	assert.Equal(t, "/code: value 'pizza' does not match pattern '^[A-Z]{3}$'", errs[1].Error())
+
+	// pattern and format are skipped, maxLength still applies.
+	errs = sch.Validate(instance, AssertFormat(true), DisableKeywords("pattern", "format"))
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "maxLength", errs[0].Keyword)
+	assert.Empty(t, sch.Validate(map[string]any{"code": "pb"}, DisableKeywords("pattern")))
+	assert.Len(t, sch.Validate(map[string]any{"code": "pb"}), 1)
+
+	// any keyword can be turned off.
+	assert.Empty(t, sch.Validate(map[string]any{"code": 12}, DisableKeywords("type")))
+
+	// a disabled type is not checked at all, so the rest of the schema still is.
+	enum := getHighSchema(t, `type: string
+enum: [a, b]
+not:
+  const: 5`)
+	errs = enum.Validate(int64(5), DisableKeywords("type"))
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "enum", errs[0].Keyword)
+	assert.Equal(t, "not", errs[1].Keyword)
+	assert.Len(t, enum.Validate(int64(5), DisableKeywords("type", "not")), 1)
+
+	// keywords holding schemas skip the values they apply to.
+	errs = sch.Validate(instance, AssertFormat(true), DisableKeywords("properties"))
+	assert.Empty(t, errs)
+	items := getHighSchema(t, `type: array
+items:
+  type: string
+allOf:
+  - minItems: 2`)
+	assert.Len(t, items.Validate([]any{int64(1)}), 2)
+	assert.Empty(t, items.Validate([]any{int64(1)}, DisableKeywords("items", "allOf")))
+
+	bad := getHighSchema(t, `type: string
+pattern: '[unclosed'`)
+	errs = bad.Validate("pizza")
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "/: pattern '[unclosed' is not a valid regular expression", errs[0].Error())
+}
+
+func TestSchema_Validate_Integer(t *testing.T) {
+	sch := getHighSchema(t, `type: integer`)
+
+	// an integer is any number without a fractional part, whatever Go type holds it.
+	for _, n := range []any{3, int8(3), uint64(3), 3.0, float32(3), json.Number("3"), json.Number("3.0"), -0.0} {
+		assert.Empty(t, sch.Validate(n), "%T %v", n, n)
+	}
+	for _, n := range []any{3.5, float32(0.25), json.Number("3.5"), math.Inf(1), math.NaN(), "3"} {
+		errs := sch.Validate(n)
+		assert.Len(t, errs, 1, "%T %v", n, n)
+		assert.Equal(t, "type", errs[0].Keyword)
+	}
+	assert.Equal(t, "/: expected type 'integer', got 'number'", sch.Validate(3.5)[0].Error())
+
+	// a number accepts both.
+	num := getHighSchema(t, `type: number`)
+	assert.Empty(t, num.Validate(3))
+	assert.Empty(t, num.Validate(json.Number("3.5")))
+}
+
+func TestSchema_Validate_ConstAndEnum(t *testing.T) {
+	sch := getHighSchema(t, `type: string
+enum: [small, medium, large]
+const: medium`)
+	assert.Empty(t, sch.Contradictions())
+	assert.Empty(t, sch.Validate("medium"))
+
+	// in the enum, but not the const.
+	errs := sch.Validate("small")
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "const", errs[0].Keyword)
+
+	// neither.
+	assert.Len(t, sch.Validate("huge"), 2)
+
+	// a const that is not in the enum can never be satisfied.
+	impossible := getHighSchema(t, `enum: [small, large]
+const: medium`)
+	assert.Equal(t, []string{"const value 'medium' is not one of the enum values, no value can match"},
+		impossible.Contradictions())
+	for _, value := range []string{"small", "medium", "large"} {
+		assert.Len(t, impossible.Validate(value), 1, value)
+	}
+}
+
+func TestSchema_Validate_ConstDeep(t *testing.T) {
+	sch := getHighSchema(t, `const: {a: 1, b: 2}`)
+	assert.Empty(t, sch.Validate(map[string]any{"b": 2, "a": 1.0}))
+
+	errs := sch.Validate(map[string]any{"a": 1, "b": 3})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "const", errs[0].Keyword)
+	assert.Equal(t, `value {"a":1,"b":3} does not match the const value {"a":1,"b":2}`, errs[0].Message)
+	assert.Len(t, sch.Validate(map[string]any{"a": 1, "b": 2, "c": 3}), 1)
+	assert.Len(t, sch.Validate(map[string]any{"a": 1}), 1)
+
+	// arrays are order sensitive.
+	arr := getHighSchema(t, `const: [1, [2, 3], {x: y}]`)
+	assert.Empty(t, arr.Validate([]any{1, []any{2, 3}, map[string]any{"x": "y"}}))
+	errs = arr.Validate([]any{1, []any{3, 2}, map[string]any{"x": "y"}})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, `value [1,[3,2],{"x":"y"}] does not match the const value [1,[2,3],{"x":"y"}]`, errs[0].Message)
+}
+
+func TestSchema_Validate_DependentRequired(t *testing.T) {
+	sch := getHighSchema(t, `type: object
+properties:
+  name:
+    type: string
+  credit_card:
+    type: string
+  billing_address:
+    type: string
+  billing_zip:
+    type: string
+dependentRequired:
+  credit_card: [billing_address, billing_zip]`)
+
+	assert.Equal(t, []string{"billing_address", "billing_zip"}, sch.DependentRequired.GetOrZero("credit_card"))
+	assert.Empty(t, sch.Validate(map[string]any{"name": "pizza"}))
+	assert.Empty(t, sch.Validate(map[string]any{"credit_card": "4111", "billing_address": "1 Main St",
+		"billing_zip": "12345"}))
+
+	errs := sch.Validate(map[string]any{"credit_card": "4111", "billing_zip": "12345"})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "dependentRequired", errs[0].Keyword)
+	assert.Equal(t, "property 'credit_card' is present, so 'billing_address' must be present too", errs[0].Message)
+
+	errs = sch.Validate(map[string]any{"credit_card": "4111"})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "property 'credit_card' is present, so 'billing_address', 'billing_zip' must be present too",
+		errs[0].Message)
+
+	// the keyword is rendered as it was written.
+	rend, err := sch.Render()
+	assert.NoError(t, err)
+	assert.Contains(t, string(rend), `dependentRequired:
+    credit_card:
+        - billing_address
+        - billing_zip`)
+}
+
+func TestSchema_Validate_UniqueItems(t *testing.T) {
+	sch := getHighSchema(t, `type: array
+uniqueItems: true`)
+
+	assert.Empty(t, sch.Validate([]any{1, "1", map[string]any{"a": 1}, map[string]any{"a": 2}, []any{1, 2}}))
+	assert.Empty(t, sch.Validate([]any{}))
+
+	errs := sch.Validate([]any{map[string]any{"a": 1, "b": []any{1}}, "pizza", map[string]any{"b": []any{1.0}, "a": 1}})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "uniqueItems", errs[0].Keyword)
+	assert.Equal(t, "/: array items must be unique, item 2 is a duplicate of item 0", errs[0].Error())
+
+	// only the first duplicate is reported.
+	assert.Len(t, sch.Validate([]any{1, 1, 2, 2}), 1)
+
+	assert.Empty(t, getHighSchema(t, `type: array
+uniqueItems: false`).Validate([]any{1, 1}))
+}
+
+func TestSchema_Validate_PatternUnanchored(t *testing.T) {
+	// a pattern can match anywhere in the string.
+	sch := getHighSchema(t, `type: string
+pattern: '[0-9]'`)
+	assert.Empty(t, sch.Validate("abc1"))
+	assert.Empty(t, sch.Validate("1abc"))
+	assert.Len(t, sch.Validate("abc"), 1)
+
+	// unless the pattern anchors itself.
+	anchored := getHighSchema(t, `type: string
+pattern: '^[0-9]+$'`)
+	assert.Empty(t, anchored.Validate("123"))
+	errs := anchored.Validate("abc1")
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "/: value 'abc1' does not match pattern '^[0-9]+$'", errs[0].Error())
+}
+
+func TestSchema_Validate_AdditionalPropertiesSchema(t *testing.T) {
+	sch := getHighSchema(t, `type: object
+properties:
+  id:
+    type: integer
+patternProperties:
+  '^x-':
+    type: boolean
+additionalProperties:
+  type: string`)
+
+	assert.Empty(t, sch.Validate(map[string]any{"id": 1, "name": "pizza", "x-hot": true}))
+
+	errs := sch.Validate(map[string]any{"id": 1, "name": "pizza", "size": 12})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "type", errs[0].Keyword)
+	assert.Equal(t, "/size: expected type 'string', got 'integer'", errs[0].Error())
+
+	// declared properties are checked against their own schema, not additionalProperties.
+	errs = sch.Validate(map[string]any{"id": "one"})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "/id", errs[0].Path)
+
+	closed := getHighSchema(t, `type: object
+properties:
+  id:
+    type: integer
+patternProperties:
+  '^x-': {}
+additionalProperties: false`)
+	assert.Empty(t, closed.Validate(map[string]any{"id": 1, "x-trace": "abc"}))
+	errs = closed.Validate(map[string]any{"id": 1, "extra": "abc"})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "/extra: property 'extra' is not allowed, additionalProperties is false", errs[0].Error())
+}
+
+func TestSchema_Validate_PatternProperties(t *testing.T) {
+	sch := getHighSchema(t, `type: object
+patternProperties:
+  '^x-':
+    type: string
+  'id$':
+    type: integer
+  '(':
+    type: boolean`)
+
+	assert.Empty(t, sch.Validate(map[string]any{"x-trace": "abc", "userid": 1, "other": true}))
+
+	// a property is checked by every pattern it matches.
+	errs := sch.Validate(map[string]any{"x-id": "abc", "x-trace": 12})
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "/x-trace: expected type 'string', got 'integer'", errs[0].Error())
+	assert.Equal(t, "/x-id: expected type 'integer', got 'string'", errs[1].Error())
+}
+
+func TestSchema_Validate_PropertyNames(t *testing.T) {
+	sch := getHighSchema(t, `type: object
+propertyNames:
+  maxLength: 3`)
+
+	assert.Empty(t, sch.Validate(map[string]any{"id": 1, "abc": 2}))
+	errs := sch.Validate(map[string]any{"id": 1, "pizza": 2})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "propertyNames", errs[0].Keyword)
+	assert.Equal(t, "/pizza: property name 'pizza' is not valid: string has 5 characters, no more than 3 allowed",
+		errs[0].Error())
+}
+
+func TestSchema_Validate_DependentSchemas(t *testing.T) {
+	sch := getHighSchema(t, `type: object
+dependentSchemas:
+  credit_card:
+    required: [billing_address]`)
+
+	assert.Empty(t, sch.Validate(map[string]any{"name": "pizza"}))
+	assert.Empty(t, sch.Validate(map[string]any{"credit_card": 1234, "billing_address": "here"}))
+	errs := sch.Validate(map[string]any{"credit_card": 1234})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "/: missing required property 'billing_address'", errs[0].Error())
+}
+
+func TestSchema_Validate_IfThenElse(t *testing.T) {
+	sch := getHighSchema(t, `type: object
+if:
+  properties:
+    country:
+      const: NL
+then:
+  required: [postcode]
+else:
+  required: [zip]`)
+
+	assert.Empty(t, sch.Validate(map[string]any{"country": "NL", "postcode": "1234AB"}))
+	assert.Empty(t, sch.Validate(map[string]any{"country": "US", "zip": "90210"}))
+
+	errs := sch.Validate(map[string]any{"country": "NL", "zip": "90210"})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "/: missing required property 'postcode'", errs[0].Error())
+
+	errs = sch.Validate(map[string]any{"country": "US"})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "/: missing required property 'zip'", errs[0].Error())
+
+	// without 'then', a matching value is valid.
+	sch = getHighSchema(t, `if:
+  type: string
+else:
+  type: integer`)
+	assert.Empty(t, sch.Validate("pizza"))
+	assert.Empty(t, sch.Validate(5))
+	assert.Len(t, sch.Validate(true), 1)
+}
+
+func TestSchema_Validate_Contains(t *testing.T) {
+	sch := getHighSchema(t, `type: array
+contains:
+  type: integer`)
+
+	assert.Empty(t, sch.Validate([]any{"a", 1}))
+	errs := sch.Validate([]any{"a", "b"})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "contains", errs[0].Keyword)
+	assert.Equal(t, "/: array has 0 items matching contains, at least 1 required", errs[0].Error())
+
+	sch = getHighSchema(t, `type: array
+contains:
+  type: integer
+minContains: 2
+maxContains: 3`)
+	assert.Empty(t, sch.Validate([]any{1, "a", 2}))
+	errs = sch.Validate([]any{1, "a"})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "minContains", errs[0].Keyword)
+	errs = sch.Validate([]any{1, 2, 3, 4})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "/: array has 4 items matching contains, no more than 3 allowed", errs[0].Error())
+
+	// minContains of zero allows no matches at all.
+	sch = getHighSchema(t, `type: array
+contains:
+  type: integer
+minContains: 0`)
+	assert.Empty(t, sch.Validate([]any{"a"}))
+}
@@ -0,0 +1,162 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// toFloat will convert any Go numeric type into a float64, the second value is false if the value is not a number.
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// multipleOfTolerance is how far the quotient of a value and a multipleOf can be from a whole number, and still
+// count as a multiple. Floats cannot hold most decimals exactly, so 0.3 / 0.1 is 2.9999999999999996 rather than 3,
+// a naive modulo would reject perfectly good values.
+const multipleOfTolerance = 1e-9
+
+// isMultipleOf returns true if n is a multiple of m, within multipleOfTolerance. A multipleOf of zero (which is not
+// allowed by JSON Schema) accepts any number.
+func isMultipleOf(n, m float64) bool {
+	if m == 0 {
+		return true
+	}
+	q := n / m
+	if math.IsInf(q, 0) || math.IsNaN(q) {
+		return false
+	}
+	return math.Abs(q-math.Round(q)) <= multipleOfTolerance
+}
+
+// valuesEqual compares two decoded values using JSON semantics. Numbers are equal if they have the same
+// value regardless of Go type (1 == 1.0), object key order does not matter and array order does.
+func valuesEqual(a, b any) bool {
+	// two integers are compared exactly, large int64 values would lose precision as floats.
+	if ia, ok := a.(int64); ok {
+		if ib, okb := b.(int64); okb {
+			return ia == ib
+		}
+	}
+	if fa, ok := toFloat(a); ok {
+		fb, okb := toFloat(b)
+		return okb && (fa == fb || (math.IsNaN(fa) && math.IsNaN(fb)))
+	}
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for k, v := range av {
+			if w, found := bv[k]; !found || !valuesEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		bv, ok := b.([]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !valuesEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// describeInstance returns a short description of a decoded value for a message, the value rendered as compact JSON
+// (object keys are sorted), or as Go formats it if it cannot be rendered.
+func describeInstance(v any) string {
+	if b, err := json.Marshal(v); err == nil {
+		return string(b)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// nodesEqual compares two nodes structurally. Aliases are followed, mapping key order, comments, style and position
+// are ignored. The seen map guards against recursive aliases.
+func nodesEqual(a, b *yaml.Node, seen map[[2]*yaml.Node]bool) bool {
+	a, b = utils.NodeAlias(a), utils.NodeAlias(b)
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a == b || seen[[2]*yaml.Node{a, b}] {
+		return true
+	}
+	seen[[2]*yaml.Node{a, b}] = true
+	if a.Kind == yaml.DocumentNode && len(a.Content) > 0 {
+		return nodesEqual(a.Content[0], b, seen)
+	}
+	if b.Kind == yaml.DocumentNode && len(b.Content) > 0 {
+		return nodesEqual(a, b.Content[0], seen)
+	}
+	if a.Kind != b.Kind || len(a.Content) != len(b.Content) {
+		return false
+	}
+	switch a.Kind {
+	case yaml.ScalarNode:
+		return a.ShortTag() == b.ShortTag() && a.Value == b.Value
+	case yaml.MappingNode:
+		for i := 0; i < len(a.Content); i += 2 {
+			found := false
+			for j := 0; j < len(b.Content); j += 2 {
+				if b.Content[j].Value == a.Content[i].Value {
+					if !nodesEqual(a.Content[i+1], b.Content[j+1], seen) {
+						return false
+					}
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	default:
+		for i := range a.Content {
+			if !nodesEqual(a.Content[i], b.Content[i], seen) {
+				return false
+			}
+		}
+		return true
+	}
+}
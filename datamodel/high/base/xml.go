@@ -43,6 +43,23 @@ func NewXML(xml *low.XML) *XML {
 	return x
 }
 
+// QualifiedName returns the name of the XML element or attribute, including the prefix if there is one, for example
+// 'pb:pizza'. If no name is defined, the defaultName is used instead (usually the name of the property). A nil XML
+// will return the defaultName.
+func (x *XML) QualifiedName(defaultName string) string {
+	if x == nil {
+		return defaultName
+	}
+	name := defaultName
+	if x.Name != "" {
+		name = x.Name
+	}
+	if x.Prefix != "" && name != "" {
+		return x.Prefix + ":" + name
+	}
+	return name
+}
+
 // GoLow returns the low level XML reference used to create the high level one.
 func (x *XML) GoLow() *low.XML {
 	return x.low
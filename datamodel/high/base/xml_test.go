@@ -72,3 +72,40 @@ wrapped: true`
 	assert.NotEqual(t, yml, strings.TrimSpace(string(highXMLBytes)))
 
 }
+
+func TestXML_QualifiedName(t *testing.T) {
+	assert.Equal(t, "pb:pizza", (&XML{Name: "pizza", Prefix: "pb"}).QualifiedName("food"))
+	assert.Equal(t, "pb:food", (&XML{Prefix: "pb"}).QualifiedName("food"))
+	assert.Equal(t, "pizza", (&XML{Name: "pizza"}).QualifiedName("food"))
+	assert.Equal(t, "food", (&XML{}).QualifiedName("food"))
+	assert.Equal(t, "food", (*XML)(nil).QualifiedName("food"))
+}
+
+func TestSchema_XMLName(t *testing.T) {
+	sch := getHighSchema(t, `type: object
+properties:
+  id:
+    type: integer
+    xml:
+      attribute: true
+  name:
+    type: string
+    xml:
+      name: title
+      prefix: pb
+      namespace: https://pb33f.io/schema
+  size:
+    type: integer`)
+
+	name, attr := sch.Properties.GetOrZero("id").Schema().XMLName("id")
+	assert.Equal(t, "id", name)
+	assert.True(t, attr)
+
+	name, attr = sch.Properties.GetOrZero("name").Schema().XMLName("name")
+	assert.Equal(t, "pb:title", name)
+	assert.False(t, attr)
+
+	name, attr = sch.Properties.GetOrZero("size").Schema().XMLName("size")
+	assert.Equal(t, "size", name)
+	assert.False(t, attr)
+}
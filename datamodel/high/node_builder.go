@@ -90,6 +90,15 @@ func (n *NodeBuilder) add(key string, i int) {
 			if lowExtensions != nil {
 				lowItem := low.FindItemInOrderedMap(pair.Key(), lowExtensions)
 				nodeEntry.LowValue = lowItem
+
+				// keep the extension in its original position, if it was in the original document.
+				for lp := orderedmap.First(lowExtensions); lp != nil; lp = lp.Next() {
+					if lp.Key().Value == pair.Key() && lp.Key().KeyNode != nil && lp.Key().KeyNode.Line >= j {
+						nodeEntry.Line = lp.Key().KeyNode.Line
+						j = nodeEntry.Line
+						break
+					}
+				}
 			}
 			n.Nodes = append(n.Nodes, nodeEntry)
 			j++
@@ -244,11 +253,10 @@ func (n *NodeBuilder) Render() *yaml.Node {
 		}
 	}
 
-	sort.Slice(n.Nodes, func(i, j int) bool {
-		if n.Nodes[i].Line != n.Nodes[j].Line {
-			return n.Nodes[i].Line < n.Nodes[j].Line
-		}
-		return false
+	// a stable sort is used, so entries on the same line (new entries with no low-level model for example) are always
+	// rendered in the order they were added, making the output deterministic.
+	sort.SliceStable(n.Nodes, func(i, j int) bool {
+		return n.Nodes[i].Line < n.Nodes[j].Line
 	})
 
 	for i := range n.Nodes {
@@ -388,7 +396,7 @@ func (n *NodeBuilder) AddYAMLNode(parent *yaml.Node, entry *nodes.NodeEntry) *ya
 			if entry.LowValue != nil {
 				if vnut, ok := entry.LowValue.(low.HasValueNodeUntyped); ok {
 					vn := vnut.GetValueNode()
-					if vn.Kind == yaml.SequenceNode {
+					if vn != nil && vn.Kind == yaml.SequenceNode && len(vn.Content) == len(rawNode.Content) {
 						for i := range vn.Content {
 							rawNode.Content[i].Style = vn.Content[i].Style
 						}
@@ -491,12 +499,22 @@ func (n *NodeBuilder) AddYAMLNode(parent *yaml.Node, entry *nodes.NodeEntry) *ya
 			}
 			if b, bok := value.(*float64); bok {
 				encodeSkip = true
-				if *b > 0 || (entry.RenderZero && entry.Line > 0) {
+				raw, changed := rawNumberNode(entry.LowValue, *b)
+				if raw != nil {
+					// prefer the original form of the number, so '1e3' or '1.0' render as they were written.
+					valueNode = raw
+					valueNode.Line = line
+				} else if *b > 0 || (entry.RenderZero && entry.Line > 0) {
 					if *b > 0 {
 						valueNode = utils.CreateFloatNode(strconv.FormatFloat(*b, 'f', -1, 64))
 					} else {
 						valueNode = utils.CreateIntNode(strconv.FormatFloat(*b, 'f', -1, 64))
 					}
+					if changed {
+						// a new value in place of one from the document is tagged as it's written, so it renders
+						// as '500' rather than '!!float 500'.
+						valueNode.Tag = numberTag(valueNode.Value)
+					}
 					valueNode.Line = line
 				}
 			}
@@ -533,3 +551,29 @@ type Renderable interface {
 type RenderableInline interface {
 	MarshalYAMLInline() (interface{}, error)
 }
+
+// rawNumberNode returns a copy of the original number node from a low-level value, as long as it still holds the
+// same value as the high-level number. Returns nil if there is no original node, or if the value has changed, the
+// second value is true if there is an original number node that has changed.
+func rawNumberNode(lowValue any, value float64) (*yaml.Node, bool) {
+	vn, ok := lowValue.(low.HasValueNodeUntyped)
+	if !ok {
+		return nil, false
+	}
+	n := vn.GetValueNode()
+	if n == nil || n.Kind != yaml.ScalarNode || (n.ShortTag() != "!!int" && n.ShortTag() != "!!float") {
+		return nil, false
+	}
+	if utils.ParseNodeFloat(n) != value {
+		return nil, true
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: n.ShortTag(), Value: n.Value, Style: n.Style}, false
+}
+
+// numberTag returns the tag YAML resolves a plain number to, '!!int' for whole numbers and '!!float' for the rest.
+func numberTag(value string) string {
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return "!!int"
+	}
+	return "!!float"
+}
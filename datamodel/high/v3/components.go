@@ -143,6 +143,24 @@ func buildSchema(inMap *orderedmap.Map[lowmodel.KeyReference[string], lowmodel.V
 	_ = datamodel.TranslateMapParallel(inMap, translateFunc, resultFunc)
 }
 
+// Schema will return the built schema for the component named, the one found at '#/components/schemas/{name}'.
+// If the component is a reference, it is followed. The second value is false if there is no schema with that name,
+// or the schema cannot be built.
+func (c *Components) Schema(name string) (*highbase.Schema, bool) {
+	if c == nil || c.Schemas == nil {
+		return nil, false
+	}
+	sp := c.Schemas.GetOrZero(name)
+	if sp == nil {
+		return nil, false
+	}
+	sch, err := sp.BuildSchema()
+	if err != nil || sch == nil {
+		return nil, false
+	}
+	return sch, true
+}
+
 // GoLow returns the low-level Components instance used to create the high-level one.
 func (c *Components) GoLow() *low.Components {
 	return c.low
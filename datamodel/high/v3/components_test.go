@@ -69,3 +69,42 @@ requestBodies:
 	dat, _ = r.Render()
 	assert.Equal(t, desired, strings.TrimSpace(string(dat)))
 }
+
+func TestComponents_Schema(t *testing.T) {
+	yml := `components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name:
+          type: string
+    Dog:
+      $ref: '#/components/schemas/Pet'`
+
+	var idxNode yaml.Node
+	_ = yaml.Unmarshal([]byte(yml), &idxNode)
+	idx := index.NewSpecIndexWithConfig(&idxNode, index.CreateOpenAPIIndexConfig())
+
+	var n v3.Components
+	compNode := idxNode.Content[0].Content[1]
+	_ = low.BuildModel(compNode, &n)
+	_ = n.Build(context.Background(), compNode, idx)
+
+	r := NewComponents(&n)
+
+	pet, ok := r.Schema("Pet")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"object"}, pet.Type)
+	assert.Equal(t, []string{"string"}, pet.Properties.GetOrZero("name").Schema().Type)
+
+	dog, ok := r.Schema("Dog")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"object"}, dog.Type)
+
+	missing, ok := r.Schema("Cat")
+	assert.False(t, ok)
+	assert.Nil(t, missing)
+
+	_, ok = (&Components{}).Schema("Pet")
+	assert.False(t, ok)
+}
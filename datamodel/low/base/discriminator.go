@@ -8,7 +8,10 @@ import (
 	"strings"
 
 	"github.com/pb33f/libopenapi/datamodel/low"
+	"github.com/pb33f/libopenapi/index"
 	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
 )
 
 // Discriminator is only used by OpenAPI 3+ documents, it represents a polymorphic discriminator used for schemas
@@ -23,9 +26,23 @@ import (
 type Discriminator struct {
 	PropertyName low.NodeReference[string]
 	Mapping      low.NodeReference[*orderedmap.Map[low.KeyReference[string], low.ValueReference[string]]]
+	Extensions   *orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]]
 	low.Reference
 }
 
+// Build will extract extensions from the Discriminator instance.
+func (d *Discriminator) Build(root *yaml.Node, _ *index.SpecIndex) error {
+	root = utils.NodeAlias(root)
+	utils.CheckForMergeNodes(root)
+	d.Extensions = low.ExtractExtensions(root)
+	return nil
+}
+
+// GetExtensions returns all Discriminator extensions and satisfies the low.HasExtensions interface.
+func (d *Discriminator) GetExtensions() *orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]] {
+	return d.Extensions
+}
+
 // FindMappingValue will return a ValueReference containing the string mapping value
 func (d *Discriminator) FindMappingValue(key string) *low.ValueReference[string] {
 	for pair := orderedmap.First(d.Mapping.Value); pair != nil; pair = pair.Next() {
@@ -48,6 +65,7 @@ func (d *Discriminator) Hash() [32]byte {
 	for pair := orderedmap.First(orderedmap.SortAlpha(d.Mapping.Value)); pair != nil; pair = pair.Next() {
 		f = append(f, pair.Value().Value)
 	}
+	f = append(f, low.HashExtensions(d.Extensions)...)
 
 	return sha256.Sum256([]byte(strings.Join(f, "|")))
 }
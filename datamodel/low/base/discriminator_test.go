@@ -49,3 +49,20 @@ propertyName: freshCakes`
 
 	assert.Equal(t, lDoc.Hash(), rDoc.Hash())
 }
+
+func TestDiscriminator_Build_Extensions(t *testing.T) {
+	yml := `propertyName: freshCakes
+x-case-insensitive: true`
+
+	var idxNode yaml.Node
+	_ = yaml.Unmarshal([]byte(yml), &idxNode)
+
+	var n Discriminator
+	_ = low.BuildModel(idxNode.Content[0], &n)
+	assert.NoError(t, n.Build(idxNode.Content[0], nil))
+	assert.Equal(t, 1, n.GetExtensions().Len())
+
+	var plain Discriminator
+	_ = low.BuildModel(idxNode.Content[0], &plain)
+	assert.NotEqual(t, n.Hash(), plain.Hash())
+}
@@ -98,6 +98,7 @@ type Schema struct {
 	Else                  low.NodeReference[*SchemaProxy]
 	Then                  low.NodeReference[*SchemaProxy]
 	DependentSchemas      low.NodeReference[*orderedmap.Map[low.KeyReference[string], low.ValueReference[*SchemaProxy]]]
+	DependentRequired     low.NodeReference[*orderedmap.Map[low.KeyReference[string], low.ValueReference[[]string]]]
 	PatternProperties     low.NodeReference[*orderedmap.Map[low.KeyReference[string], low.ValueReference[*SchemaProxy]]]
 	PropertyNames         low.NodeReference[*SchemaProxy]
 	UnevaluatedItems      low.NodeReference[*SchemaProxy]
@@ -143,6 +144,34 @@ type Schema struct {
 	// Index is a reference to the SpecIndex that was used to build this schema.
 	Index *index.SpecIndex
 	*low.Reference
+
+	warnings   []SchemaWarning
+	sourcePath string
+	rootNode   *yaml.Node
+}
+
+// SchemaWarning represents a non-fatal problem found when building a Schema, like an unknown keyword or a
+// malformed value that could not be used. Path is a JSON Pointer relative to the schema the warning belongs to.
+type SchemaWarning struct {
+	Path    string
+	Message string
+	Node    *yaml.Node
+}
+
+// schemaKeywords contains every keyword the Schema model understands, anything else found in a schema
+// (that is not an extension) will generate a warning when building.
+var schemaKeywords = map[string]bool{
+	"$ref": true, "$schema": true, "$id": true, "$anchor": true, "$defs": true, "$comment": true, "$dynamicRef": true,
+	"$dynamicAnchor": true, "contentSchema": true, "type": true, "allOf": true, "oneOf": true, "anyOf": true,
+	"not": true, "discriminator": true, "examples": true, "example": true, "prefixItems": true, "contains": true,
+	"minContains": true, "maxContains": true, "items": true, "if": true, "else": true, "then": true,
+	"dependentSchemas": true, "dependentRequired": true, "patternProperties": true, "propertyNames": true,
+	"unevaluatedItems": true, "unevaluatedProperties": true, "title": true, "multipleOf": true, "maximum": true, "minimum": true,
+	"exclusiveMaximum": true, "exclusiveMinimum": true, "maxLength": true, "minLength": true, "pattern": true,
+	"format": true, "maxItems": true, "minItems": true, "uniqueItems": true, "maxProperties": true,
+	"minProperties": true, "required": true, "enum": true, "properties": true, "additionalProperties": true,
+	"description": true, "contentEncoding": true, "contentMediaType": true, "default": true, "const": true,
+	"nullable": true, "readOnly": true, "writeOnly": true, "xml": true, "externalDocs": true, "deprecated": true,
 }
 
 // Hash will calculate a SHA256 hash from the values of the schema, This allows equality checking against
@@ -370,6 +399,10 @@ func (s *Schema) Hash() [32]byte {
 		d = append(d, fmt.Sprintf("%s-%s", pair.Key().Value, low.GenerateHashString(pair.Value().Value)))
 	}
 
+	for pair := orderedmap.First(orderedmap.SortAlpha(s.DependentRequired.Value)); pair != nil; pair = pair.Next() {
+		d = append(d, fmt.Sprintf("%s-%s", pair.Key().Value, strings.Join(pair.Value().Value, "|")))
+	}
+
 	if len(s.PrefixItems.Value) > 0 {
 		itemsKeys := make([]string, len(s.PrefixItems.Value))
 		itemsEntities := make(map[string]*SchemaProxy)
@@ -428,6 +461,54 @@ func (s *Schema) FindPatternProperty(name string) *low.ValueReference[*SchemaPro
 	return low.FindItemInOrderedMap[*SchemaProxy](name, s.PatternProperties.Value)
 }
 
+// SourcePath returns the path (or URL) of the document the Schema was built from, in a specification spread across
+// files, this is the file the schema lives in. Empty if the schema was built from memory, with no path known.
+func (s *Schema) SourcePath() string {
+	return s.sourcePath
+}
+
+// GetWarnings returns any warnings that were collected when the Schema was built.
+func (s *Schema) GetWarnings() []SchemaWarning {
+	return s.warnings
+}
+
+// addWarning records a new SchemaWarning against the schema, the key is converted into a JSON Pointer.
+func (s *Schema) addWarning(key, message string, node *yaml.Node) {
+	key = strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+	s.warnings = append(s.warnings, SchemaWarning{Path: "/" + key, Message: message, Node: node})
+}
+
+// checkKeywords runs through the top level keys of a schema node and records warnings for anything that is
+// unknown, or any values that are malformed and cannot be used. Extensions (keys with one of the prefixes) are skipped.
+func (s *Schema) checkKeywords(root *yaml.Node, prefixes []string) {
+	for i := 0; i < len(root.Content)-1; i += 2 {
+		k, v := root.Content[i], utils.NodeAlias(root.Content[i+1])
+		if utils.HasAnyPrefix(k.Value, prefixes...) {
+			continue
+		}
+		if !schemaKeywords[k.Value] {
+			s.addWarning(k.Value, fmt.Sprintf("unknown schema keyword '%s'", k.Value), k)
+			continue
+		}
+		switch k.Value {
+		case TypeLabel:
+			if isBlankTypeNode(v) {
+				s.addWarning(k.Value, "type is blank, it is ignored", v)
+			} else if !utils.IsNodeStringValue(v) && !utils.IsNodeArray(v) && !isNullTypeNode(v) {
+				s.addWarning(k.Value, "type must be a string or an array of strings", v)
+			}
+		case "required", "enum", AllOfLabel, AnyOfLabel, OneOfLabel, PrefixItemsLabel:
+			if !utils.IsNodeArray(v) {
+				s.addWarning(k.Value, fmt.Sprintf("%s must be an array", k.Value), v)
+			}
+		case PropertiesLabel, DependentSchemasLabel, DependentRequiredLabel, PatternPropertiesLabel:
+			if !utils.IsNodeMap(v) {
+				s.addWarning(k.Value, fmt.Sprintf("%s must be an object", k.Value), v)
+			}
+		}
+	}
+}
+
 // GetExtensions returns all extensions for Schema
 func (s *Schema) GetExtensions() *orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]] {
 	return s.Extensions
@@ -452,6 +533,7 @@ func (s *Schema) GetExtensions() *orderedmap.Map[low.KeyReference[string], low.V
 //   - Else
 //   - Then
 //   - DependentSchemas
+//   - DependentRequired
 //   - PatternProperties
 //   - PropertyNames
 //   - UnevaluatedItems
@@ -462,6 +544,7 @@ func (s *Schema) Build(ctx context.Context, root *yaml.Node, idx *index.SpecInde
 	utils.CheckForMergeNodes(root)
 	s.Reference = new(low.Reference)
 	s.Index = idx
+	s.warnings = nil
 	if h, _, _ := utils.IsNodeRefValue(root); h {
 		ref, _, err, fctx := low.LocateRefNodeWithContext(ctx, root, idx)
 		if ref != nil {
@@ -480,29 +563,41 @@ func (s *Schema) Build(ctx context.Context, root *yaml.Node, idx *index.SpecInde
 		}
 	}
 
+	s.sourcePath = ""
+	if p, ok := ctx.Value(index.CurrentPathKey).(string); ok && p != "" {
+		s.sourcePath = p
+	} else if idx != nil {
+		s.sourcePath = idx.GetSpecAbsolutePath()
+	}
+
 	// Build model using possibly dereferenced root
 	if err := low.BuildModel(root, s); err != nil {
 		return err
 	}
 
+	s.rootNode = root
 	s.extractExtensions(root)
+	s.checkKeywords(root, []string{"x-"})
 
 	// determine schema type, singular (3.0) or multiple (3.1), use a variable value
 	_, typeLabel, typeValue := utils.FindKeyNodeFullTop(TypeLabel, root.Content)
 	if typeValue != nil {
-		if utils.IsNodeStringValue(typeValue) {
+		if utils.IsNodeStringValue(typeValue) || isNullTypeNode(typeValue) {
 			s.Type = low.NodeReference[SchemaDynamicValue[string, []low.ValueReference[string]]]{
 				KeyNode:   typeLabel,
 				ValueNode: typeValue,
-				Value:     SchemaDynamicValue[string, []low.ValueReference[string]]{N: 0, A: typeValue.Value},
+				Value:     SchemaDynamicValue[string, []low.ValueReference[string]]{N: 0, A: schemaTypeValue(typeValue)},
 			}
 		}
 		if utils.IsNodeArray(typeValue) {
 
 			var refs []low.ValueReference[string]
 			for r := range typeValue.Content {
+				if isBlankTypeNode(typeValue.Content[r]) {
+					continue
+				}
 				refs = append(refs, low.ValueReference[string]{
-					Value:     typeValue.Content[r].Value,
+					Value:     schemaTypeValue(typeValue.Content[r]),
 					ValueNode: typeValue.Content[r],
 				})
 			}
@@ -520,7 +615,7 @@ func (s *Schema) Build(ctx context.Context, root *yaml.Node, idx *index.SpecInde
 		// if there is an index, determine if this a 3.0 or 3.1 schema
 		if idx != nil {
 			if idx.GetConfig().SpecInfo.VersionNumeric == 3.1 {
-				val, _ := strconv.ParseFloat(exMinValue.Value, 64)
+				val := utils.ParseNodeFloat(exMinValue)
 				s.ExclusiveMinimum = low.NodeReference[*SchemaDynamicValue[bool, float64]]{
 					KeyNode:   exMinLabel,
 					ValueNode: exMinValue,
@@ -547,7 +642,7 @@ func (s *Schema) Build(ctx context.Context, root *yaml.Node, idx *index.SpecInde
 				}
 			}
 			if utils.IsNodeIntValue(exMinValue) {
-				val, _ := strconv.ParseFloat(exMinValue.Value, 64)
+				val := utils.ParseNodeFloat(exMinValue)
 				s.ExclusiveMinimum = low.NodeReference[*SchemaDynamicValue[bool, float64]]{
 					KeyNode:   exMinLabel,
 					ValueNode: exMinValue,
@@ -563,7 +658,7 @@ func (s *Schema) Build(ctx context.Context, root *yaml.Node, idx *index.SpecInde
 		// if there is an index, determine if this a 3.0 or 3.1 schema
 		if idx != nil {
 			if idx.GetConfig().SpecInfo.VersionNumeric == 3.1 {
-				val, _ := strconv.ParseFloat(exMaxValue.Value, 64)
+				val := utils.ParseNodeFloat(exMaxValue)
 				s.ExclusiveMaximum = low.NodeReference[*SchemaDynamicValue[bool, float64]]{
 					KeyNode:   exMaxLabel,
 					ValueNode: exMaxValue,
@@ -590,7 +685,7 @@ func (s *Schema) Build(ctx context.Context, root *yaml.Node, idx *index.SpecInde
 				}
 			}
 			if utils.IsNodeIntValue(exMaxValue) {
-				val, _ := strconv.ParseFloat(exMaxValue.Value, 64)
+				val := utils.ParseNodeFloat(exMaxValue)
 				s.ExclusiveMaximum = low.NodeReference[*SchemaDynamicValue[bool, float64]]{
 					KeyNode:   exMaxLabel,
 					ValueNode: exMaxValue,
@@ -664,6 +759,7 @@ func (s *Schema) Build(ctx context.Context, root *yaml.Node, idx *index.SpecInde
 	if discNode != nil {
 		var discriminator Discriminator
 		_ = low.BuildModel(discNode, &discriminator)
+		_ = discriminator.Build(discNode, idx) // returns no errors, can't check for one.
 		s.Discriminator = low.NodeReference[*Discriminator]{Value: &discriminator, KeyNode: discLabel, ValueNode: discNode}
 	}
 
@@ -704,6 +800,11 @@ func (s *Schema) Build(ctx context.Context, root *yaml.Node, idx *index.SpecInde
 		s.DependentSchemas = *props
 	}
 
+	// handle dependent required
+	if dr := buildDependentRequired(root); dr != nil {
+		s.DependentRequired = *dr
+	}
+
 	// handle pattern properties
 	props, err = buildPropertyMap(ctx, root, idx, PatternPropertiesLabel)
 	if err != nil {
@@ -1052,6 +1153,34 @@ func buildPropertyMap(ctx context.Context, root *yaml.Node, idx *index.SpecIndex
 	return nil, nil
 }
 
+// buildDependentRequired extracts the dependentRequired map, each property name maps to the names of the properties
+// it requires. Values that are not arrays are skipped.
+func buildDependentRequired(root *yaml.Node) *low.NodeReference[*orderedmap.Map[low.KeyReference[string], low.ValueReference[[]string]]] {
+	_, label, node := utils.FindKeyNodeFullTop(DependentRequiredLabel, root.Content)
+	node = utils.NodeAlias(node)
+	if node == nil || !utils.IsNodeMap(node) {
+		return nil
+	}
+	dependents := orderedmap.New[low.KeyReference[string], low.ValueReference[[]string]]()
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		key, value := node.Content[i], utils.NodeAlias(node.Content[i+1])
+		if !utils.IsNodeArray(value) {
+			continue
+		}
+		names := make([]string, 0, len(value.Content))
+		for _, n := range value.Content {
+			names = append(names, n.Value)
+		}
+		dependents.Set(low.KeyReference[string]{KeyNode: key, Value: key.Value},
+			low.ValueReference[[]string]{Value: names, ValueNode: value})
+	}
+	return &low.NodeReference[*orderedmap.Map[low.KeyReference[string], low.ValueReference[[]string]]]{
+		Value:     dependents,
+		KeyNode:   label,
+		ValueNode: node,
+	}
+}
+
 // count the number of sub-schemas in a node.
 func countSubSchemaItems(node *yaml.Node) int {
 	if utils.IsNodeMap(node) {
@@ -1074,6 +1203,21 @@ func (s *Schema) extractExtensions(root *yaml.Node) {
 	s.Extensions = low.ExtractExtensions(root)
 }
 
+// ExtensionsWithPrefixes returns the extensions of the schema collected again, where a key is an extension if it
+// starts with any of the prefixes given, rather than 'x-'. The prefixes replace the default, so include 'x-' to keep
+// it. The warnings of the schema are returned checked against the same prefixes, so a key like 'acme-owner' is
+// collected rather than reported as an unknown keyword. The schema itself is not changed.
+func (s *Schema) ExtensionsWithPrefixes(prefixes ...string) (
+	*orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]], []SchemaWarning,
+) {
+	if s.rootNode == nil || len(prefixes) == 0 {
+		return s.Extensions, s.warnings
+	}
+	checked := new(Schema)
+	checked.checkKeywords(s.rootNode, prefixes)
+	return low.ExtractExtensionsWithPrefixes(s.rootNode, prefixes...), checked.warnings
+}
+
 // build out a child schema for parent schema.
 func buildSchema(ctx context.Context, schemas chan schemaProxyBuildResult, labelNode, valueNode *yaml.Node, errors chan error, idx *index.SpecIndex) {
 	if valueNode != nil {
@@ -1144,6 +1288,17 @@ func buildSchema(ctx context.Context, schemas chan schemaProxyBuildResult, label
 					v: *r.res,
 				}
 			}
+		} else if utils.IsNodeBoolValue(valueNode) {
+			// a boolean schema (true / false) is valid JSON Schema, true accepts everything, false rejects everything.
+			go build(foundCtx, labelNode, valueNode, nil, -1, syncChan, false, "")
+			r := <-syncChan
+			schemas <- schemaProxyBuildResult{
+				k: low.KeyReference[string]{
+					KeyNode: labelNode,
+					Value:   labelNode.Value,
+				},
+				v: *r.res,
+			}
 		} else if utils.IsNodeArray(valueNode) {
 			refBuilds := 0
 			results := make([]*low.ValueReference[*SchemaProxy], len(valueNode.Content))
@@ -1261,3 +1416,24 @@ func ExtractSchema(ctx context.Context, root *yaml.Node, idx *index.SpecIndex) (
 	}
 	return nil, nil
 }
+
+// isNullTypeNode returns true if a type is an unquoted YAML null, as in 'type: null', rather than the string "null".
+// Both mean the null type. A type left blank is not the null type, it's no type at all.
+func isNullTypeNode(n *yaml.Node) bool {
+	n = utils.NodeAlias(n)
+	return n != nil && n.Kind == yaml.ScalarNode && n.Tag == "!!null" && n.Value != ""
+}
+
+// isBlankTypeNode returns true if a type is left blank, as in 'type:', it's treated as if there is no type.
+func isBlankTypeNode(n *yaml.Node) bool {
+	n = utils.NodeAlias(n)
+	return n != nil && n.Kind == yaml.ScalarNode && n.Tag == "!!null" && n.Value == ""
+}
+
+// schemaTypeValue returns the name of a type, a written YAML null (null or ~) is the type 'null'.
+func schemaTypeValue(n *yaml.Node) string {
+	if isNullTypeNode(n) {
+		return "null"
+	}
+	return n.Value
+}
@@ -6,10 +6,13 @@ package base
 import (
 	"context"
 	"crypto/sha256"
+	"strconv"
+	"sync"
 
 	"github.com/pb33f/libopenapi/datamodel/low"
 	"github.com/pb33f/libopenapi/index"
 	"github.com/pb33f/libopenapi/utils"
+	"golang.org/x/sync/syncmap"
 	"gopkg.in/yaml.v3"
 )
 
@@ -53,6 +56,7 @@ type SchemaProxy struct {
 	rendered   *Schema
 	buildError error
 	ctx        context.Context
+	lock       sync.Mutex
 }
 
 // Build will prepare the SchemaProxy for rendering, it does not build the Schema, only sets up internal state.
@@ -79,7 +83,11 @@ func (sp *SchemaProxy) Build(ctx context.Context, key, value *yaml.Node, idx *in
 //
 // If anything goes wrong during the build, then nothing is returned and the error that occurred can
 // be retrieved by using GetBuildError()
+//
+// Schema is safe to call from many goroutines, the schema is only built once.
 func (sp *SchemaProxy) Schema() *Schema {
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
 	if sp.rendered != nil {
 		return sp.rendered
 	}
@@ -98,6 +106,8 @@ func (sp *SchemaProxy) Schema() *Schema {
 // GetBuildError returns the build error that was set when Schema() was called. If Schema() has not been run, or
 // there were no errors during build, then nil will be returned.
 func (sp *SchemaProxy) GetBuildError() error {
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
 	return sp.buildError
 }
 
@@ -125,20 +135,90 @@ func (sp *SchemaProxy) GetValueNode() *yaml.Node {
 	return sp.vn
 }
 
+// GetIndex will return the index used by the proxy to look up references, nil if it was built without one.
+func (sp *SchemaProxy) GetIndex() *index.SpecIndex {
+	return sp.idx
+}
+
+// IsBooleanSchema will return true as the second value if the proxy is for a boolean schema (a literal true or
+// false) rather than an object. The first value is the boolean value of the schema. A true schema accepts
+// everything, a false schema rejects everything.
+func (sp *SchemaProxy) IsBooleanSchema() (value bool, isBool bool) {
+	if sp == nil || !utils.IsNodeBoolValue(sp.vn) {
+		return false, false
+	}
+	value, _ = strconv.ParseBool(utils.NodeAlias(sp.vn).Value)
+	return value, true
+}
+
+// IsRecursiveAlias returns true if the proxy is for a YAML alias (*name) that sits inside the anchor (&name) it
+// refers to, so the schema contains itself. A recursive alias can be built one level at a time, but never fully
+// resolved.
+func (sp *SchemaProxy) IsRecursiveAlias() bool {
+	return sp != nil && isRecursiveAlias(sp.vn)
+}
+
 // Hash will return a consistent SHA256 Hash of the SchemaProxy object (it will resolve it)
+//
+// When there is an index, the hash of an inline schema is cached in the index cache, keyed by the node the schema
+// was built from. Nodes are never changed once a document has been parsed, so the cache never needs invalidating,
+// and it's shared with every other proxy of the same node, so hashing a shared subtree over and over (like a diff
+// does) is cheap. The cache is safe to use from many goroutines.
 func (sp *SchemaProxy) Hash() [32]byte {
-	if sp.rendered != nil {
-		if !sp.IsReference() {
-			return sp.rendered.Hash()
+	if b, ok := sp.IsBooleanSchema(); ok {
+		return sha256.Sum256([]byte(strconv.FormatBool(b)))
+	}
+	if sp.IsRecursiveAlias() {
+		// a YAML alias that points back to its own anchor is recursive, resolving it would never end.
+		// so hash the anchor name only, the same way a reference is hashed.
+		return sha256.Sum256([]byte("*" + sp.vn.Alias.Anchor))
+	}
+	if sp.IsReference() {
+		// hash reference value only, do not resolve!
+		return sha256.Sum256([]byte(sp.GetReference()))
+	}
+	cache := sp.hashCache()
+	if cache != nil {
+		if h, ok := cache.Load(schemaHashKey{node: sp.vn}); ok {
+			return h.([32]byte)
 		}
-	} else {
-		if !sp.IsReference() {
-			// only resolve this proxy if it's not a ref.
-			sch := sp.Schema()
-			sp.rendered = sch
-			return sch.Hash()
+	}
+	// only resolve this proxy if it's not a ref.
+	h := sp.Schema().Hash()
+	if cache != nil {
+		cache.Store(schemaHashKey{node: sp.vn}, h)
+	}
+	return h
+}
+
+// schemaHashKey is the key of a cached schema hash in the index cache, the value node of the schema.
+type schemaHashKey struct {
+	node *yaml.Node
+}
+
+// hashCache returns the index cache that holds schema hashes, or nil if there is nothing to key the cache on (the
+// proxy was built by hand) or nowhere to keep it.
+func (sp *SchemaProxy) hashCache() *syncmap.Map {
+	if sp.vn == nil || sp.idx == nil {
+		return nil
+	}
+	return sp.idx.GetCache()
+}
+
+// isRecursiveAlias returns true if the node is a YAML alias (*name) that is contained by the node it is an alias of,
+// the anchor (&name). Following a recursive alias never ends.
+func isRecursiveAlias(node *yaml.Node) bool {
+	if node == nil || node.Kind != yaml.AliasNode || node.Alias == nil {
+		return false
+	}
+	var contains func(n *yaml.Node) bool
+	contains = func(n *yaml.Node) bool {
+		for _, c := range n.Content {
+			if c == node || contains(c) {
+				return true
+			}
 		}
+		return false
 	}
-	// hash reference value only, do not resolve!
-	return sha256.Sum256([]byte(sp.GetReference()))
+	return contains(node.Alias)
 }
@@ -5,6 +5,9 @@ package base
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/pb33f/libopenapi/datamodel/low"
@@ -153,3 +156,158 @@ properties:
 	origin = schC.GetSchemaReferenceLocation()
 	assert.Nil(t, origin)
 }
+
+func TestSchemaProxy_IsBooleanSchema(t *testing.T) {
+	var trueNode, falseNode yaml.Node
+	_ = yaml.Unmarshal([]byte(`true`), &trueNode)
+	_ = yaml.Unmarshal([]byte(`false`), &falseNode)
+
+	yes := new(SchemaProxy)
+	_ = yes.Build(context.Background(), nil, trueNode.Content[0], nil)
+	no := new(SchemaProxy)
+	_ = no.Build(context.Background(), nil, falseNode.Content[0], nil)
+
+	v, ok := yes.IsBooleanSchema()
+	assert.True(t, ok)
+	assert.True(t, v)
+	v, ok = no.IsBooleanSchema()
+	assert.True(t, ok)
+	assert.False(t, v)
+	assert.NotEqual(t, yes.Hash(), no.Hash())
+
+	_, ok = new(SchemaProxy).IsBooleanSchema()
+	assert.False(t, ok)
+}
+
+func TestSchemaProxy_IsRecursiveAlias(t *testing.T) {
+	yml := `tree: &node
+  type: object
+  properties:
+    child: *node
+other: *node`
+
+	var idxNode yaml.Node
+	_ = yaml.Unmarshal([]byte(yml), &idxNode)
+	root := idxNode.Content[0]
+	tree := root.Content[1]
+	child := tree.Content[3].Content[1]
+
+	sp := new(SchemaProxy)
+	_ = sp.Build(context.Background(), nil, child, nil)
+	assert.True(t, sp.IsRecursiveAlias())
+
+	// an alias outside the anchor is not recursive.
+	other := new(SchemaProxy)
+	_ = other.Build(context.Background(), nil, root.Content[3], nil)
+	assert.False(t, other.IsRecursiveAlias())
+
+	plain := new(SchemaProxy)
+	_ = plain.Build(context.Background(), nil, tree, nil)
+	assert.False(t, plain.IsRecursiveAlias())
+	assert.False(t, (*SchemaProxy)(nil).IsRecursiveAlias())
+}
+
+func TestSchemaProxy_Hash_Cached(t *testing.T) {
+	yml := `type: object
+properties:
+  name:
+    type: string
+  tags:
+    type: array
+    items:
+      type: string`
+
+	var node yaml.Node
+	_ = yaml.Unmarshal([]byte(yml), &node)
+	idx := index.NewSpecIndexWithConfig(&node, index.CreateOpenAPIIndexConfig())
+
+	var first SchemaProxy
+	assert.NoError(t, first.Build(context.Background(), nil, node.Content[0], idx))
+	hash := first.Hash()
+	assert.Equal(t, hash, first.Hash())
+
+	// the hash is the same as an uncached schema.
+	var uncached SchemaProxy
+	assert.NoError(t, uncached.Build(context.Background(), nil, node.Content[0], nil))
+	assert.Equal(t, hash, uncached.Hash())
+
+	// a new proxy of the same node, sharing the index, picks up the cached hash without building anything.
+	var second SchemaProxy
+	assert.NoError(t, second.Build(context.Background(), nil, node.Content[0], idx))
+	assert.Equal(t, hash, second.Hash())
+	assert.Nil(t, second.rendered)
+}
+
+func TestSchemaProxy_Hash_Concurrent(t *testing.T) {
+	yml := `type: object
+properties:
+  name:
+    type: string`
+
+	var node yaml.Node
+	_ = yaml.Unmarshal([]byte(yml), &node)
+	idx := index.NewSpecIndexWithConfig(&node, index.CreateOpenAPIIndexConfig())
+
+	var shared SchemaProxy
+	assert.NoError(t, shared.Build(context.Background(), nil, node.Content[0], idx))
+	expected := shared.Hash()
+
+	// the same proxy, and new proxies of the same node, hashed at the same time.
+	var wg sync.WaitGroup
+	hashes := make([][32]byte, 20)
+	for i := range hashes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sp := &shared
+			if i%2 == 0 {
+				sp = new(SchemaProxy)
+				_ = sp.Build(context.Background(), nil, node.Content[0], idx)
+			}
+			hashes[i] = sp.Hash()
+		}(i)
+	}
+	wg.Wait()
+	for _, h := range hashes {
+		assert.Equal(t, expected, h)
+	}
+}
+
+// sharedSubtree returns the root node of a large schema, and an index of it.
+func sharedSubtree() (*yaml.Node, *index.SpecIndex) {
+	var sb strings.Builder
+	sb.WriteString("type: object\nproperties:\n")
+	for i := 0; i < 200; i++ {
+		sb.WriteString(fmt.Sprintf("  prop%d:\n    type: object\n    properties:\n      name:\n        type: string\n"+
+			"        maxLength: %d\n      tags:\n        type: array\n        items:\n          type: string\n", i, i))
+	}
+	var node yaml.Node
+	_ = yaml.Unmarshal([]byte(sb.String()), &node)
+	return node.Content[0], index.NewSpecIndexWithConfig(&node, index.CreateOpenAPIIndexConfig())
+}
+
+func BenchmarkSchemaProxy_Hash_SharedSubtree(b *testing.B) {
+	root, idx := sharedSubtree()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// every iteration is a new proxy of the same subtree, like every side of a diff run.
+		var sp SchemaProxy
+		_ = sp.Build(context.Background(), nil, root, idx)
+		sp.Hash()
+	}
+}
+
+func BenchmarkSchemaProxy_Hash_SharedSubtree_Uncached(b *testing.B) {
+	// without an index there is no cache, so every hash builds and hashes the whole subtree.
+	root, _ := sharedSubtree()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var sp SchemaProxy
+		_ = sp.Build(context.Background(), nil, root, nil)
+		sp.Hash()
+	}
+}
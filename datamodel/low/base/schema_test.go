@@ -1888,3 +1888,176 @@ func TestBuildSchema_BadNodeTypes(t *testing.T) {
 	<-doneChan
 	assert.Equal(t, "build schema failed: unexpected data type: 'unknown', line 1, col 2", err.Error())
 }
+
+func TestSchema_Build_Warnings(t *testing.T) {
+	yml := `type: object
+x-cake: yummy
+burgers: tasty
+required: email
+properties:
+  a/b:
+    type: string`
+
+	var idxNode yaml.Node
+	_ = yaml.Unmarshal([]byte(yml), &idxNode)
+
+	sch := Schema{}
+	err := sch.Build(context.Background(), idxNode.Content[0], nil)
+	assert.NoError(t, err)
+
+	warnings := sch.GetWarnings()
+	assert.Len(t, warnings, 2)
+	assert.Equal(t, "/burgers", warnings[0].Path)
+	assert.Equal(t, "unknown schema keyword 'burgers'", warnings[0].Message)
+	assert.Equal(t, 3, warnings[0].Node.Line)
+	assert.Equal(t, "/required", warnings[1].Path)
+	assert.Equal(t, "required must be an array", warnings[1].Message)
+}
+
+func TestSchema_Build_Warnings_JSONSchema(t *testing.T) {
+	yml := `$schema: https://json-schema.org/draft/2020-12/schema
+$id: https://example.com/pet.json
+$comment: pets are great
+$dynamicAnchor: node
+type: object
+properties:
+  tag:
+    $ref: '#/$defs/tag'
+  payload:
+    type: string
+    contentMediaType: application/json
+    contentSchema:
+      type: object
+  children:
+    type: array
+    items:
+      $dynamicRef: '#node'
+$defs:
+  tag:
+    type: string`
+
+	var idxNode yaml.Node
+	_ = yaml.Unmarshal([]byte(yml), &idxNode)
+	idx := index.NewSpecIndexWithConfig(&idxNode, index.CreateClosedAPIIndexConfig())
+
+	sch := Schema{}
+	err := sch.Build(context.Background(), idxNode.Content[0], idx)
+	assert.NoError(t, err)
+	assert.Empty(t, sch.GetWarnings())
+}
+
+func TestSchema_Build_DependentRequired(t *testing.T) {
+	yml := `type: object
+dependentRequired:
+  credit_card: [billing_address]
+  name: nope`
+
+	var idxNode yaml.Node
+	_ = yaml.Unmarshal([]byte(yml), &idxNode)
+
+	sch := Schema{}
+	assert.NoError(t, sch.Build(context.Background(), idxNode.Content[0], nil))
+	assert.Empty(t, sch.GetWarnings())
+	assert.Equal(t, 1, orderedmap.Len(sch.DependentRequired.Value))
+	dr := low.FindItemInOrderedMap("credit_card", sch.DependentRequired.Value)
+	assert.Equal(t, []string{"billing_address"}, dr.Value)
+	assert.Equal(t, 3, dr.ValueNode.Line)
+
+	// dependentRequired is part of the hash.
+	other := Schema{}
+	_ = yaml.Unmarshal([]byte(`type: object
+dependentRequired:
+  credit_card: [billing_zip]`), &idxNode)
+	assert.NoError(t, other.Build(context.Background(), idxNode.Content[0], nil))
+	assert.NotEqual(t, sch.Hash(), other.Hash())
+}
+
+func TestSchema_Build_ExtensionPrefixes(t *testing.T) {
+	yml := `type: object
+acme-foo: bar
+x-cake: yummy
+properties:
+  name:
+    type: string
+    acme-owner: team`
+
+	var idxNode yaml.Node
+	_ = yaml.Unmarshal([]byte(yml), &idxNode)
+
+	sch := Schema{}
+	assert.NoError(t, sch.Build(context.Background(), idxNode.Content[0], nil))
+
+	// by default, only 'x-' is an extension.
+	assert.Nil(t, low.FindItemInOrderedMap("acme-foo", sch.Extensions))
+	assert.Equal(t, "yummy", low.FindItemInOrderedMap("x-cake", sch.Extensions).Value.Value)
+	assert.Len(t, sch.GetWarnings(), 1)
+	assert.Equal(t, "unknown schema keyword 'acme-foo'", sch.GetWarnings()[0].Message)
+
+	ext, warnings := sch.ExtensionsWithPrefixes("acme-")
+	assert.Equal(t, 1, orderedmap.Len(ext))
+	assert.Equal(t, "bar", low.FindItemInOrderedMap("acme-foo", ext).Value.Value)
+	assert.Nil(t, low.FindItemInOrderedMap("x-cake", ext))
+	assert.Len(t, warnings, 1)
+	assert.Equal(t, "unknown schema keyword 'x-cake'", warnings[0].Message)
+
+	// the schema is not changed.
+	assert.Equal(t, "yummy", low.FindItemInOrderedMap("x-cake", sch.Extensions).Value.Value)
+	assert.Equal(t, "unknown schema keyword 'acme-foo'", sch.GetWarnings()[0].Message)
+
+	// without prefixes, the schema extensions and warnings are returned.
+	ext, warnings = sch.ExtensionsWithPrefixes()
+	assert.Equal(t, sch.Extensions, ext)
+	assert.Equal(t, sch.GetWarnings(), warnings)
+}
+
+func TestSchema_Build_NullType(t *testing.T) {
+	for _, yml := range []string{`type: null`, `type: ~`, `type: "null"`} {
+		var idxNode yaml.Node
+		_ = yaml.Unmarshal([]byte(yml), &idxNode)
+
+		sch := Schema{}
+		assert.NoError(t, sch.Build(context.Background(), idxNode.Content[0], nil))
+		assert.Equal(t, "null", sch.Type.Value.A, yml)
+		assert.Empty(t, sch.GetWarnings(), yml)
+	}
+
+	var idxNode yaml.Node
+	_ = yaml.Unmarshal([]byte(`type: [string, null]`), &idxNode)
+	sch := Schema{}
+	assert.NoError(t, sch.Build(context.Background(), idxNode.Content[0], nil))
+	assert.Equal(t, "null", sch.Type.Value.B[1].Value)
+
+	// a blank type is no type at all.
+	_ = yaml.Unmarshal([]byte("type:"), &idxNode)
+	sch = Schema{}
+	assert.NoError(t, sch.Build(context.Background(), idxNode.Content[0], nil))
+	assert.True(t, sch.Type.IsEmpty())
+	assert.Len(t, sch.GetWarnings(), 1)
+	assert.Equal(t, "type is blank, it is ignored", sch.GetWarnings()[0].Message)
+	_ = yaml.Unmarshal([]byte("type:\n  - string\n  -"), &idxNode)
+	sch = Schema{}
+	assert.NoError(t, sch.Build(context.Background(), idxNode.Content[0], nil))
+	assert.Len(t, sch.Type.Value.B, 1)
+}
+
+func TestSchema_Build_SourcePath(t *testing.T) {
+	var node yaml.Node
+	_ = yaml.Unmarshal([]byte(`type: string`), &node)
+
+	cfg := index.CreateClosedAPIIndexConfig()
+	cfg.SpecAbsolutePath = "/specs/root.yaml"
+	idx := index.NewSpecIndexWithConfig(&node, cfg)
+
+	var sch Schema
+	_ = low.BuildModel(node.Content[0], &sch)
+	assert.NoError(t, sch.Build(context.Background(), node.Content[0], idx))
+	assert.Equal(t, "/specs/root.yaml", sch.SourcePath())
+
+	// the current path in the context wins, it's the file a reference was found in.
+	ctx := context.WithValue(context.Background(), index.CurrentPathKey, "/specs/pets.yaml")
+	assert.NoError(t, sch.Build(ctx, node.Content[0], idx))
+	assert.Equal(t, "/specs/pets.yaml", sch.SourcePath())
+
+	assert.NoError(t, sch.Build(context.Background(), node.Content[0], nil))
+	assert.Empty(t, sch.SourcePath())
+}
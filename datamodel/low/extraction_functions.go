@@ -807,8 +807,14 @@ func ExtractMap[PT Buildable[N], N any](
 //
 //	int64, float64, bool, string
 func ExtractExtensions(root *yaml.Node) *orderedmap.Map[KeyReference[string], ValueReference[*yaml.Node]] {
+	return ExtractExtensionsWithPrefixes(root, "x-")
+}
+
+// ExtractExtensionsWithPrefixes works the same as ExtractExtensions, except any key starting with one of the
+// prefixes given is extracted, for organizations that use their own prefix (like 'acme-') rather than 'x-'.
+func ExtractExtensionsWithPrefixes(root *yaml.Node, prefixes ...string) *orderedmap.Map[KeyReference[string], ValueReference[*yaml.Node]] {
 	root = utils.NodeAlias(root)
-	extensions := utils.FindExtensionNodes(root.Content)
+	extensions := utils.FindExtensionNodesWithPrefixes(root.Content, prefixes...)
 	extensionMap := orderedmap.New[KeyReference[string], ValueReference[*yaml.Node]]()
 	for _, ext := range extensions {
 		extensionMap.Set(KeyReference[string]{
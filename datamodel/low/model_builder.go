@@ -221,7 +221,7 @@ func SetField(field *reflect.Value, valueNode *yaml.Node, keyNode *yaml.Node) {
 
 		if utils.IsNodeNumberValue(valueNode) {
 			if field.CanSet() {
-				fv, _ := strconv.ParseFloat(valueNode.Value, 64)
+				fv := utils.ParseNodeFloat(valueNode)
 				nr := NodeReference[float64]{
 					Value:     fv,
 					ValueNode: valueNode,
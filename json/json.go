@@ -1,18 +1,25 @@
 package json
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
+	"strings"
 
 	"github.com/pb33f/libopenapi/orderedmap"
+	"golang.org/x/exp/slices"
 	"gopkg.in/yaml.v3"
 )
 
 // YAMLNodeToJSON converts yaml/json stored in a yaml.Node to json ordered matching the original yaml/json
 //
-// NOTE: The limitation is this won't work with YAML that is not compatible with JSON, ie yaml with anchors or complex map keys
+// Aliases (*name) are replaced by a copy of the node they refer to. An alias inside its own anchor can never be
+// expanded, so it's returned as an error.
+//
+// NOTE: The limitation is this won't work with YAML that is not compatible with JSON, ie yaml with complex map keys
 func YAMLNodeToJSON(node *yaml.Node, indentation string) ([]byte, error) {
-	v, err := handleYAMLNode(node)
+	v, err := handleYAMLNode(node, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -20,24 +27,137 @@ func YAMLNodeToJSON(node *yaml.Node, indentation string) ([]byte, error) {
 	return json.MarshalIndent(v, "", indentation)
 }
 
-func handleYAMLNode(node *yaml.Node) (any, error) {
+// YAMLNodeToCompactJSON converts yaml/json stored in a yaml.Node to json in the same way as YAMLNodeToJSON, with
+// no whitespace at all between tokens.
+func YAMLNodeToCompactJSON(node *yaml.Node) ([]byte, error) {
+	v, err := handleYAMLNode(node, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(v)
+}
+
+// YAMLNodeToJSONWriter converts yaml/json stored in a yaml.Node to json in the same way as YAMLNodeToJSON, but
+// writes the result to the writer as the node is walked, instead of building an intermediate value and then
+// marshalling it. The output is identical to YAMLNodeToJSON.
+func YAMLNodeToJSONWriter(w io.Writer, node *yaml.Node, indentation string) error {
+	bw := bufio.NewWriter(w)
+	if err := writeYAMLNode(bw, node, indentation, 0, nil); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// aliasTarget returns the node an alias refers to, aliases holds the targets of the aliases already being expanded
+// above this one. An alias to one of those is recursive, and is returned as an error.
+func aliasTarget(node *yaml.Node, aliases []*yaml.Node) ([]*yaml.Node, error) {
+	if node.Alias == nil {
+		return nil, fmt.Errorf("alias '*%s' has no anchor", node.Value)
+	}
+	if slices.Contains(aliases, node.Alias) {
+		return nil, fmt.Errorf("alias '*%s' is recursive and cannot be converted to json", node.Alias.Anchor)
+	}
+	return append(slices.Clip(aliases), node.Alias), nil
+}
+
+func writeYAMLNode(w *bufio.Writer, node *yaml.Node, indentation string, depth int, aliases []*yaml.Node) error {
+	newline := func(d int) {
+		w.WriteByte('\n')
+		w.WriteString(strings.Repeat(indentation, d))
+	}
 	switch node.Kind {
 	case yaml.DocumentNode:
-		return handleYAMLNode(node.Content[0])
+		return writeYAMLNode(w, node.Content[0], indentation, depth, aliases)
 	case yaml.SequenceNode:
-		return handleSequenceNode(node)
+		if len(node.Content) == 0 {
+			_, err := w.WriteString("[]")
+			return err
+		}
+		w.WriteByte('[')
+		for i, n := range node.Content {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			newline(depth + 1)
+			if err := writeYAMLNode(w, n, indentation, depth+1, aliases); err != nil {
+				return err
+			}
+		}
+		newline(depth)
+		return w.WriteByte(']')
 	case yaml.MappingNode:
-		return handleMappingNode(node)
+		// decode the keys in the same way as handleMappingNode, so duplicate keys are handled the same way.
+		m := orderedmap.New[string, yaml.Node]()
+		if err := node.Decode(m); err != nil {
+			return err
+		}
+		if m.Len() == 0 {
+			_, err := w.WriteString("{}")
+			return err
+		}
+		w.WriteByte('{')
+		i := 0
+		for pair := orderedmap.First(m); pair != nil; pair = pair.Next() {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			i++
+			newline(depth + 1)
+			key, _ := json.Marshal(pair.Key())
+			w.Write(key)
+			w.WriteString(": ")
+			n := pair.Value()
+			if err := writeYAMLNode(w, &n, indentation, depth+1, aliases); err != nil {
+				return err
+			}
+		}
+		newline(depth)
+		return w.WriteByte('}')
+	case yaml.ScalarNode:
+		v, err := handleScalarNode(node)
+		if err != nil {
+			return err
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	case yaml.AliasNode:
+		expanding, err := aliasTarget(node, aliases)
+		if err != nil {
+			return err
+		}
+		return writeYAMLNode(w, node.Alias, indentation, depth, expanding)
+	default:
+		return fmt.Errorf("unknown node kind: %v", node.Kind)
+	}
+}
+
+func handleYAMLNode(node *yaml.Node, aliases []*yaml.Node) (any, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		return handleYAMLNode(node.Content[0], aliases)
+	case yaml.SequenceNode:
+		return handleSequenceNode(node, aliases)
+	case yaml.MappingNode:
+		return handleMappingNode(node, aliases)
 	case yaml.ScalarNode:
 		return handleScalarNode(node)
 	case yaml.AliasNode:
-		panic("currently unsupported")
+		expanding, err := aliasTarget(node, aliases)
+		if err != nil {
+			return nil, err
+		}
+		return handleYAMLNode(node.Alias, expanding)
 	default:
 		return nil, fmt.Errorf("unknown node kind: %v", node.Kind)
 	}
 }
 
-func handleMappingNode(node *yaml.Node) (any, error) {
+func handleMappingNode(node *yaml.Node, aliases []*yaml.Node) (any, error) {
 	m := orderedmap.New[string, yaml.Node]()
 
 	if err := node.Decode(m); err != nil {
@@ -47,7 +167,7 @@ func handleMappingNode(node *yaml.Node) (any, error) {
 	v := orderedmap.New[string, any]()
 	for pair := orderedmap.First(m); pair != nil; pair = pair.Next() {
 		n := pair.Value()
-		vv, err := handleYAMLNode(&n)
+		vv, err := handleYAMLNode(&n, aliases)
 		if err != nil {
 			return nil, err
 		}
@@ -58,7 +178,7 @@ func handleMappingNode(node *yaml.Node) (any, error) {
 	return v, nil
 }
 
-func handleSequenceNode(node *yaml.Node) (any, error) {
+func handleSequenceNode(node *yaml.Node, aliases []*yaml.Node) (any, error) {
 	var s []yaml.Node
 
 	if err := node.Decode(&s); err != nil {
@@ -67,7 +187,7 @@ func handleSequenceNode(node *yaml.Node) (any, error) {
 
 	v := make([]any, len(s))
 	for i, n := range s {
-		vv, err := handleYAMLNode(&n)
+		vv, err := handleYAMLNode(&n, aliases)
 		if err != nil {
 			return nil, err
 		}
@@ -1,6 +1,7 @@
 package json_test
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/pb33f/libopenapi/json"
@@ -85,3 +86,86 @@ func TestYAMLNodeToJSON_FromJSON(t *testing.T) {
 
 	assert.Equal(t, j, string(o))
 }
+
+func TestYAMLNodeToJSONWriter(t *testing.T) {
+	y := `root:
+  key1: scalar1
+  key2:
+    - scalar2
+    - subkey1: "<scalar3>"
+      subkey2:
+        - 1
+        - 2.5
+    -
+      - scalar4
+      - ~
+  key3: true
+  empty: {}
+  none: []`
+
+	var v yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(y), &v))
+
+	for _, indentation := range []string{"  ", "\t", ""} {
+		expected, err := json.YAMLNodeToJSON(&v, indentation)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, json.YAMLNodeToJSONWriter(&buf, &v, indentation))
+		assert.Equal(t, string(expected), buf.String())
+	}
+}
+
+func TestYAMLNodeToJSON_Aliases(t *testing.T) {
+	y := `base: &base
+  type: string
+copy: *base`
+
+	var v yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(y), &v))
+
+	o, err := json.YAMLNodeToCompactJSON(&v)
+	require.NoError(t, err)
+	assert.Equal(t, `{"base":{"type":"string"},"copy":{"type":"string"}}`, string(o))
+
+	var buf bytes.Buffer
+	require.NoError(t, json.YAMLNodeToJSONWriter(&buf, &v, ""))
+	expected, err := json.YAMLNodeToJSON(&v, "")
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), buf.String())
+}
+
+func TestYAMLNodeToJSON_RecursiveAlias(t *testing.T) {
+	y := `tree: &node
+  type: object
+  properties:
+    child: *node`
+
+	var v yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(y), &v))
+
+	_, err := json.YAMLNodeToJSON(&v, "  ")
+	assert.EqualError(t, err, "alias '*node' is recursive and cannot be converted to json")
+
+	_, err = json.YAMLNodeToCompactJSON(&v)
+	assert.Error(t, err)
+
+	var buf bytes.Buffer
+	assert.Error(t, json.YAMLNodeToJSONWriter(&buf, &v, "  "))
+}
+
+func TestYAMLNodeToCompactJSON(t *testing.T) {
+	y := `root:
+  key1: scalar1 with spaces
+  key2:
+    - 1
+    - subkey: [true, null]
+  key3: {}`
+
+	var v yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(y), &v))
+
+	j, err := json.YAMLNodeToCompactJSON(&v)
+	require.NoError(t, err)
+	assert.Equal(t, `{"root":{"key1":"scalar1 with spaces","key2":[1,{"subkey":[true,null]}],"key3":{}}}`, string(j))
+}
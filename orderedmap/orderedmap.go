@@ -50,7 +50,12 @@ func (o *Map[K, V]) GetValueType() reflect.Type {
 }
 
 // GetOrZero will return the value for the key if it exists, otherwise it will return the zero value for the value type.
+// A nil map always returns the zero value.
 func (o *Map[K, V]) GetOrZero(k K) V {
+	if o == nil {
+		var zero V
+		return zero
+	}
 	v, ok := o.OrderedMap.Get(k)
 	if !ok {
 		var zero V
@@ -76,6 +76,9 @@ func TestOrderedMap(t *testing.T) {
 		}
 
 		assert.Equal(t, 0, m.GetOrZero("bogus"))
+
+		var nilMap *orderedmap.Map[string, int]
+		assert.Equal(t, 0, nilMap.GetOrZero("bogus"))
 	})
 }
 
@@ -60,8 +60,9 @@ func init() {
 // SchemaRenderer is a renderer that will generate random words, numbers and values based on a dictionary file.
 // The dictionary is just a slice of strings that is used to generate random words.
 type SchemaRenderer struct {
-	words           []string
-	disableRequired bool
+	words             []string
+	disableRequired   bool
+	includeDeprecated bool
 }
 
 // CreateRendererUsingDictionary will create a new SchemaRenderer using a custom dictionary file.
@@ -96,6 +97,12 @@ func (wr *SchemaRenderer) DisableRequiredCheck() {
 	wr.disableRequired = true
 }
 
+// IncludeDeprecated will render properties marked as deprecated. By default, deprecated properties are skipped
+// (unless they are required), so rendered examples reflect what a payload should look like going forward.
+func (wr *SchemaRenderer) IncludeDeprecated(include bool) {
+	wr.includeDeprecated = include
+}
+
 // DiveIntoSchema will dive into a schema and inject values from examples into a map. If there are no examples in
 // the schema, then the renderer will attempt to generate a value based on the schema type, format and pattern.
 func (wr *SchemaRenderer) DiveIntoSchema(schema *base.Schema, key string, structure map[string]any, depth int) {
@@ -249,10 +256,15 @@ func (wr *SchemaRenderer) DiveIntoSchema(schema *base.Schema, key string, struct
 			} else {
 				checkProps = properties
 			}
+			required := requiredNames(schema)
 			for pair := orderedmap.First(checkProps); pair != nil; pair = pair.Next() {
 				// render property
 				propName, propValue := pair.Key(), pair.Value()
 				propertySchema := propValue.Schema()
+				if !wr.includeDeprecated && propertySchema != nil && propertySchema.Deprecated != nil &&
+					*propertySchema.Deprecated && !slices.Contains(required, propName) {
+					continue
+				}
 				wr.DiveIntoSchema(propertySchema, propName, propertyMap, depth+1)
 			}
 		}
@@ -320,13 +332,17 @@ func (wr *SchemaRenderer) DiveIntoSchema(schema *base.Schema, key string, struct
 			// otherwise the items value is a schema, so we need to dive into it
 			if itemsSchema.IsA() {
 
-				// check if the schema contains a minItems value and render up to that number.
+				// check if the schema contains a minItems value and render up to that number, never more than
+				// maxItems allows.
 				var minItems int64 = 1
 				if schema.MinItems != nil {
 					minItems = *schema.MinItems
 				}
+				if schema.MaxItems != nil && *schema.MaxItems < minItems {
+					minItems = *schema.MaxItems
+				}
 
-				var renderedItems []any
+				renderedItems := make([]any, 0, minItems)
 				// build up the array
 				for i := int64(0); i < minItems; i++ {
 					itemMap := make(map[string]any)
@@ -341,6 +357,18 @@ func (wr *SchemaRenderer) DiveIntoSchema(schema *base.Schema, key string, struct
 	}
 }
 
+// requiredNames returns the names of the required properties of a schema, including those required by the members
+// of allOf (at any depth), as every one of them applies to the object.
+func requiredNames(schema *base.Schema) []string {
+	required := slices.Clone(schema.Required)
+	for _, member := range schema.AllOf {
+		if s := member.Schema(); s != nil {
+			required = append(required, requiredNames(s)...)
+		}
+	}
+	return required
+}
+
 func readFile(file io.Reader) []string {
 	bytes, err := io.ReadAll(file)
 	if err != nil {
@@ -558,6 +558,44 @@ items:
 	assert.Len(t, journeyMap["pb33f"], 3)
 }
 
+func TestRenderExample_Array_MinItemsObjects(t *testing.T) {
+	testObject := `type: array
+minItems: 2
+maxItems: 4
+items:
+  type: object
+  properties:
+    name:
+      type: string`
+
+	compiled := getSchema([]byte(testObject))
+
+	journeyMap := make(map[string]any)
+	wr := createSchemaRenderer()
+	wr.DiveIntoSchema(compiled, "pb33f", journeyMap, 0)
+
+	assert.Len(t, journeyMap["pb33f"], 2)
+	for _, item := range journeyMap["pb33f"].([]any) {
+		assert.NotEmpty(t, item.(map[string]any)["name"])
+	}
+}
+
+func TestRenderExample_Array_MaxItems(t *testing.T) {
+	testObject := `type: array
+maxItems: 0
+items:
+  type: string`
+
+	compiled := getSchema([]byte(testObject))
+
+	journeyMap := make(map[string]any)
+	wr := createSchemaRenderer()
+	wr.DiveIntoSchema(compiled, "pb33f", journeyMap, 0)
+
+	assert.NotNil(t, journeyMap["pb33f"])
+	assert.Len(t, journeyMap["pb33f"], 0)
+}
+
 // TODO: object array!
 
 func TestRenderExample_Object_StringProps(t *testing.T) {
@@ -974,6 +1012,72 @@ properties:
 	assert.NotNil(t, journeyMap["pb33f"].(map[string]interface{})["fries"])
 }
 
+func TestRenderSchema_Deprecated(t *testing.T) {
+	testObject := `type: [object]
+required: [name, code]
+properties:
+  name:
+    type: string
+    example: pb33f
+  code:
+    type: string
+    deprecated: true
+    example: old-code
+  nickname:
+    type: string
+    deprecated: true
+    example: pizza
+  age:
+    type: integer
+    example: 3`
+
+	compiled := getSchema([]byte(testObject))
+
+	// a deprecated optional property is skipped, a deprecated required one is not.
+	schema := make(map[string]any)
+	wr := createSchemaRenderer()
+	wr.DisableRequiredCheck()
+	wr.DiveIntoSchema(compiled, "pb33f", schema, 0)
+	rendered, _ := json.Marshal(schema["pb33f"])
+	assert.Equal(t, `{"age":3,"code":"old-code","name":"pb33f"}`, string(rendered))
+
+	schema = make(map[string]any)
+	wr.IncludeDeprecated(true)
+	wr.DiveIntoSchema(compiled, "pb33f", schema, 0)
+	rendered, _ = json.Marshal(schema["pb33f"])
+	assert.Equal(t, `{"age":3,"code":"old-code","name":"pb33f","nickname":"pizza"}`, string(rendered))
+}
+
+func TestRenderSchema_Deprecated_RequiredByAllOf(t *testing.T) {
+	testObject := `type: [object]
+properties:
+  name:
+    type: string
+    example: pb33f
+  code:
+    type: string
+    deprecated: true
+    example: old-code
+  nickname:
+    type: string
+    deprecated: true
+    example: pizza
+allOf:
+  - type: [object]
+    required: [code]
+  - allOf:
+      - required: [nickname]`
+
+	compiled := getSchema([]byte(testObject))
+
+	// deprecated properties required by allOf members are kept.
+	schema := make(map[string]any)
+	wr := createSchemaRenderer()
+	wr.DiveIntoSchema(compiled, "pb33f", schema, 0)
+	rendered, _ := json.Marshal(schema["pb33f"])
+	assert.Equal(t, `{"code":"old-code","name":"pb33f","nickname":"pizza"}`, string(rendered))
+}
+
 func TestRenderSchema_WithExample(t *testing.T) {
 	testObject := `type: [object]
 properties:
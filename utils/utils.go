@@ -331,15 +331,31 @@ func FindKeyNodeFullTop(key string, nodes []*yaml.Node) (keyNode *yaml.Node, lab
 	return nil, nil, nil
 }
 
+// HasAnyPrefix returns true if the value starts with any of the prefixes.
+func HasAnyPrefix(value string, prefixes ...string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(value, p) {
+			return true
+		}
+	}
+	return false
+}
+
 type ExtensionNode struct {
 	Key   *yaml.Node
 	Value *yaml.Node
 }
 
 func FindExtensionNodes(nodes []*yaml.Node) []*ExtensionNode {
+	return FindExtensionNodesWithPrefixes(nodes, "x-")
+}
+
+// FindExtensionNodesWithPrefixes works the same as FindExtensionNodes, except any key that starts with one of the
+// prefixes given is an extension, rather than only keys starting with 'x-'.
+func FindExtensionNodesWithPrefixes(nodes []*yaml.Node, prefixes ...string) []*ExtensionNode {
 	var extensions []*ExtensionNode
 	for i, v := range nodes {
-		if i%2 == 0 && strings.HasPrefix(v.Value, "x-") {
+		if i%2 == 0 && HasAnyPrefix(v.Value, prefixes...) {
 			if i+1 < len(nodes) {
 				extensions = append(extensions, &ExtensionNode{
 					Key:   v,
@@ -471,6 +487,20 @@ func IsNodeNumberValue(node *yaml.Node) bool {
 	return IsNodeIntValue(node) || IsNodeFloatValue(node)
 }
 
+// ParseNodeFloat will parse the value of a node as a float64. As well as regular numbers, the YAML special values
+// '.inf', '-.inf' and '.nan' are understood. Values that cannot be parsed return 0.
+func ParseNodeFloat(node *yaml.Node) float64 {
+	if node == nil {
+		return 0
+	}
+	if fv, err := strconv.ParseFloat(node.Value, 64); err == nil {
+		return fv
+	}
+	var fv float64
+	_ = NodeAlias(node).Decode(&fv)
+	return fv
+}
+
 // IsNodeBoolValue will check is a node is a bool
 func IsNodeBoolValue(node *yaml.Node) bool {
 	if node == nil {
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"math"
 	"os"
 	"sync"
 	"testing"
@@ -930,3 +931,16 @@ func TestTimeoutFind(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, nodes)
 }
+
+func TestParseNodeFloat(t *testing.T) {
+	var n yaml.Node
+	_ = yaml.Unmarshal([]byte(`[1.5, 3, .inf, -.Inf, .nan, pizza]`), &n)
+	items := n.Content[0].Content
+	assert.Equal(t, 1.5, ParseNodeFloat(items[0]))
+	assert.Equal(t, 3.0, ParseNodeFloat(items[1]))
+	assert.True(t, math.IsInf(ParseNodeFloat(items[2]), 1))
+	assert.True(t, math.IsInf(ParseNodeFloat(items[3]), -1))
+	assert.True(t, math.IsNaN(ParseNodeFloat(items[4])))
+	assert.Equal(t, 0.0, ParseNodeFloat(items[5]))
+	assert.Equal(t, 0.0, ParseNodeFloat(nil))
+}